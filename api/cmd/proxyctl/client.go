@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"api/internal/shared"
+)
+
+// settings locate the proxy and hold the admin key used against it
+type settings struct {
+	URL      string `json:"url"`
+	AdminKey string `json:"admin_key"`
+}
+
+// loadSettings reads the config file, when there is one, and lets
+// PROXYCTL_URL and PROXYCTL_ADMIN_KEY override it
+func loadSettings(path string) (settings, error) {
+	s := settings{URL: "http://localhost"}
+
+	explicit := path != ""
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "proxyctl", "config.json")
+		}
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &s); err != nil {
+				return s, fmt.Errorf("invalid config file %s: %w", path, err)
+			}
+		case explicit || !os.IsNotExist(err):
+			return s, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+
+	if url := os.Getenv("PROXYCTL_URL"); url != "" {
+		s.URL = url
+	}
+	if key := os.Getenv("PROXYCTL_ADMIN_KEY"); key != "" {
+		s.AdminKey = key
+	}
+	s.URL = strings.TrimRight(s.URL, "/")
+
+	if s.AdminKey == "" {
+		return s, fmt.Errorf("no admin key: set PROXYCTL_ADMIN_KEY or admin_key in the config file")
+	}
+	return s, nil
+}
+
+// client calls the proxy's admin API
+type client struct {
+	settings settings
+	http     *http.Client
+}
+
+func newClient(s settings) *client {
+	return &client{settings: s, http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// do sends a request with an optional JSON body and decodes a successful
+// response into out. Failures carry the proxy's error message.
+func (c *client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.settings.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.settings.AdminKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp shared.ErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("%s: %s", errResp.Code, errResp.Message)
+		}
+		return fmt.Errorf("proxy returned %s", resp.Status)
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,244 @@
+// Command proxyctl manages API keys, model routes and the result cache through
+// the proxy's admin API.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"api/internal/shared"
+)
+
+const usage = `Usage: proxyctl [-config file] <command> [flags]
+
+Commands:
+  keys add <hotkey> [-tier t] [-scopes a,b] [-ttl d]
+  keys list [-limit n] [-offset n]
+  keys remove <hotkey>
+  keys rotate <hotkey> [-grace d]
+  routes set <model> -backend url[,url] [-path p] [-server name]
+  cache flush <request_id>... [-yes]
+
+The proxy URL and admin key are read from PROXYCTL_URL and PROXYCTL_ADMIN_KEY,
+or from "url" and "admin_key" in the config file
+(default: $XDG_CONFIG_HOME/proxyctl/config.json).
+`
+
+func main() {
+	configPath := flag.String("config", "", "path to the config file")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s, err := loadSettings(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "proxyctl: %v\n", err)
+		os.Exit(1)
+	}
+	c := newClient(s)
+
+	commands := map[string]func(*client, []string) error{
+		"keys add":    keysAdd,
+		"keys list":   keysList,
+		"keys remove": keysRemove,
+		"keys rotate": keysRotate,
+		"routes set":  routesSet,
+		"cache flush": cacheFlush,
+	}
+	run, ok := commands[args[0]+" "+args[1]]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(c, args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "proxyctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs parses flags that may come before or after the positional
+// arguments and checks the number of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, minPositional int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) < minPositional {
+		return nil, fmt.Errorf("%s: missing arguments", fs.Name())
+	}
+	return positional, nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func keysAdd(c *client, args []string) error {
+	fs := flag.NewFlagSet("keys add", flag.ContinueOnError)
+	tier := fs.String("tier", "", "rate limit tier")
+	scopes := fs.String("scopes", "", "comma-separated scopes")
+	ttl := fs.String("ttl", "", "lifetime of the key, such as 720h")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	req := shared.AddKeyRequest{Hotkey: positional[0], Tier: *tier, TTL: *ttl}
+	if *scopes != "" {
+		req.Scopes = strings.Split(*scopes, ",")
+	}
+	var key map[string]any
+	if err := c.do(http.MethodPost, "/admin/keys", req, &key); err != nil {
+		return err
+	}
+	return printJSON(key)
+}
+
+func keysList(c *client, args []string) error {
+	fs := flag.NewFlagSet("keys list", flag.ContinueOnError)
+	limit := fs.Int("limit", 100, "keys per page")
+	offset := fs.Int("offset", 0, "keys to skip")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	var list shared.KeyListResponse
+	if err := c.do(http.MethodGet, fmt.Sprintf("/admin/keys?limit=%d&offset=%d", *limit, *offset), nil, &list); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "HOTKEY\tKEY\tTIER\tSTATE\tLAST USED")
+	for _, key := range list.Keys {
+		state := "active"
+		switch {
+		case key.Disabled:
+			state = "disabled"
+		case !key.Active:
+			state = "pending"
+		}
+		if key.IsAdmin {
+			state += ",admin"
+		}
+		lastUsed := "never"
+		if key.LastUsed != nil {
+			lastUsed = key.LastUsed.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.Hotkey, key.KeyMasked, key.Tier, state, lastUsed)
+	}
+	w.Flush()
+	fmt.Printf("%d of %d keys\n", len(list.Keys), list.Total)
+	return nil
+}
+
+func keysRemove(c *client, args []string) error {
+	fs := flag.NewFlagSet("keys remove", flag.ContinueOnError)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(http.MethodDelete, "/admin/keys/"+url.PathEscape(positional[0]), nil, nil); err != nil {
+		return err
+	}
+	fmt.Printf("Removed key for %s\n", positional[0])
+	return nil
+}
+
+func keysRotate(c *client, args []string) error {
+	fs := flag.NewFlagSet("keys rotate", flag.ContinueOnError)
+	grace := fs.String("grace", "", "how long the previous key keeps working, such as 1h")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	var rotated shared.RotateKeyResponse
+	req := shared.RotateKeyRequest{Hotkey: positional[0], GracePeriod: *grace}
+	if err := c.do(http.MethodPost, "/admin/keys/"+url.PathEscape(positional[0])+"/rotate", req, &rotated); err != nil {
+		return err
+	}
+	return printJSON(rotated)
+}
+
+func routesSet(c *client, args []string) error {
+	fs := flag.NewFlagSet("routes set", flag.ContinueOnError)
+	backend := fs.String("backend", "", "backend base URL, or a comma-separated list to balance between")
+	path := fs.String("path", "", "verification path on the backend")
+	server := fs.String("server", "", "x-backend-server header sent to the backend")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *backend == "" {
+		return fmt.Errorf("routes set: -backend is required")
+	}
+
+	var route map[string]any
+	req := shared.SetRouteRequest{Model: positional[0], BackendURL: *backend, Path: *path, BackendServer: *server}
+	if err := c.do(http.MethodPost, "/admin/routes", req, &route); err != nil {
+		return err
+	}
+	return printJSON(route)
+}
+
+// cacheFlush drops cached results through the bulk API, confirming the dry
+// run's count before applying it unless -yes is given
+func cacheFlush(c *client, args []string) error {
+	fs := flag.NewFlagSet("cache flush", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "apply without asking for confirmation")
+	requestIDs, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	req := shared.BulkInvalidateCacheRequest{BulkRequest: shared.BulkRequest{DryRun: true}, RequestIDs: requestIDs}
+	var plan shared.BulkResult
+	if err := c.do(http.MethodPost, "/admin/bulk/cache/invalidate", req, &plan); err != nil {
+		return err
+	}
+	fmt.Println(plan.Message)
+	if plan.Count == 0 {
+		return nil
+	}
+
+	if !*yes {
+		fmt.Print("Apply? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	req.DryRun = false
+	req.ConfirmationToken = plan.ConfirmationToken
+	var result shared.BulkResult
+	if err := c.do(http.MethodPost, "/admin/bulk/cache/invalidate", req, &result); err != nil {
+		return err
+	}
+	fmt.Println(result.Message)
+	return nil
+}
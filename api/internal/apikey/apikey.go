@@ -0,0 +1,86 @@
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	"github.com/aidarkhanov/nanoid"
+)
+
+const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Scheme describes how random identifiers are generated
+type Scheme struct {
+	Alphabet string
+	Length   int
+}
+
+var (
+	// DefaultScheme is the API key scheme used unless configured otherwise
+	DefaultScheme = Scheme{Alphabet: alphabet, Length: 32}
+	// DefaultIDScheme is the request and job ID scheme used unless configured otherwise
+	DefaultIDScheme = Scheme{Alphabet: "0123456789abcdefghijklmnopqrstuvwxyz", Length: 28}
+)
+
+// current is the scheme new keys are generated with, set once at startup
+var current = DefaultScheme
+
+// Entropy returns the bits of entropy of an identifier drawn from the scheme
+func (s Scheme) Entropy() float64 {
+	symbols := make(map[rune]struct{})
+	for _, r := range s.Alphabet {
+		symbols[r] = struct{}{}
+	}
+	return float64(s.Length) * math.Log2(float64(len(symbols)))
+}
+
+// Validate checks the scheme is usable and has at least minBits of entropy
+func (s Scheme) Validate(minBits float64) error {
+	if s.Length <= 0 || s.Length > 255 {
+		return fmt.Errorf("length must be between 1 and 255")
+	}
+	if len([]rune(s.Alphabet)) < 2 || len([]rune(s.Alphabet)) > 255 {
+		return fmt.Errorf("alphabet must have between 2 and 255 characters")
+	}
+	if bits := s.Entropy(); bits < minBits {
+		return fmt.Errorf("%.0f bits of entropy is below the required %.0f", bits, minBits)
+	}
+	return nil
+}
+
+// Generate returns a new random identifier from the scheme
+func (s Scheme) Generate() (string, error) {
+	return nanoid.Generate(s.Alphabet, s.Length)
+}
+
+// Configure sets the scheme used by Generate. It must be called before keys are issued.
+func Configure(s Scheme) {
+	current = s
+}
+
+// Length returns the length of newly generated keys
+func Length() int {
+	return current.Length
+}
+
+// Generate returns a new random API key value
+func Generate() (string, error) {
+	return current.Generate()
+}
+
+// Hash returns the hex SHA-256 digest stored in place of a key value. Keys are
+// long and random, so an unsalted digest is safe and stays indexable.
+func Hash(keyValue string) string {
+	sum := sha256.Sum256([]byte(keyValue))
+	return hex.EncodeToString(sum[:])
+}
+
+// Hint returns the last four characters of a key, kept so operators can tell keys apart
+func Hint(keyValue string) string {
+	if len(keyValue) <= 4 {
+		return keyValue
+	}
+	return keyValue[len(keyValue)-4:]
+}
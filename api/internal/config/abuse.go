@@ -0,0 +1,184 @@
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+// AbuseSettings holds thresholds for the suspicious-pattern heuristics
+type AbuseSettings struct {
+	Window            time.Duration
+	MinSamples        int
+	FailureRate       float64
+	ResubmitThreshold int
+	AutoThrottle      bool
+	ThrottleInterval  time.Duration
+}
+
+type outcome struct {
+	at       time.Time
+	verified bool
+}
+
+type payloadSeen struct {
+	requestIDs map[string]struct{}
+	lastSeen   time.Time
+}
+
+type keyActivity struct {
+	outcomes     []outcome
+	payloads     map[string]*payloadSeen
+	spikeFlagged time.Time
+}
+
+// AbuseDetector tracks per-hotkey activity and flagged keys
+type AbuseDetector struct {
+	Settings AbuseSettings
+	keys     map[string]*keyActivity
+	flagged  map[string]time.Time
+	mutex    sync.Mutex
+}
+
+func NewAbuseDetector(settings AbuseSettings) *AbuseDetector {
+	return &AbuseDetector{
+		Settings: settings,
+		keys:     make(map[string]*keyActivity),
+		flagged:  make(map[string]time.Time),
+	}
+}
+
+func (d *AbuseDetector) activity(hotkey string) *keyActivity {
+	a, ok := d.keys[hotkey]
+	if !ok {
+		a = &keyActivity{payloads: make(map[string]*payloadSeen)}
+		d.keys[hotkey] = a
+	}
+	return a
+}
+
+// RecordPayload notes a payload hash and returns how many distinct request_ids submitted it within the window
+func (d *AbuseDetector) RecordPayload(hotkey, hash, requestID string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	a := d.activity(hotkey)
+	seen, ok := a.payloads[hash]
+	if !ok || now.Sub(seen.lastSeen) > d.Settings.Window {
+		seen = &payloadSeen{requestIDs: make(map[string]struct{})}
+		a.payloads[hash] = seen
+	}
+	seen.requestIDs[requestID] = struct{}{}
+	seen.lastSeen = now
+
+	return len(seen.requestIDs)
+}
+
+// RecordOutcome notes a verdict and returns the failure rate and sample count within the window
+func (d *AbuseDetector) RecordOutcome(hotkey string, verified bool) (float64, int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	a := d.activity(hotkey)
+	a.outcomes = append(a.outcomes, outcome{at: now, verified: verified})
+
+	cutoff := now.Add(-d.Settings.Window)
+	kept := a.outcomes[:0]
+	failures := 0
+	for _, o := range a.outcomes {
+		if o.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, o)
+		if !o.verified {
+			failures++
+		}
+	}
+	a.outcomes = kept
+
+	return float64(failures) / float64(len(kept)), len(kept)
+}
+
+// ClaimSpikeFlag reports whether a failure spike may be flagged for hotkey,
+// allowing one flag per detection window
+func (d *AbuseDetector) ClaimSpikeFlag(hotkey string) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	a := d.activity(hotkey)
+	if !a.spikeFlagged.IsZero() && now.Sub(a.spikeFlagged) < d.Settings.Window {
+		return false
+	}
+	a.spikeFlagged = now
+	return true
+}
+
+// Flag marks a hotkey as flagged for review
+func (d *AbuseDetector) Flag(hotkey string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, ok := d.flagged[hotkey]; !ok {
+		d.flagged[hotkey] = time.Time{}
+	}
+}
+
+// Unflag clears a hotkey's flagged state
+func (d *AbuseDetector) Unflag(hotkey string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.flagged, hotkey)
+}
+
+// Throttle reports whether a flagged hotkey may make a request now, and if not, how long to wait
+func (d *AbuseDetector) Throttle(hotkey string) (bool, time.Duration) {
+	if !d.Settings.AutoThrottle {
+		return true, 0
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	last, ok := d.flagged[hotkey]
+	if !ok {
+		return true, 0
+	}
+
+	now := time.Now()
+	if wait := d.Settings.ThrottleInterval - now.Sub(last); wait > 0 {
+		return false, wait
+	}
+
+	d.flagged[hotkey] = now
+	return true, 0
+}
+
+// Cleanup drops activity older than the detection window
+func (d *AbuseDetector) Cleanup() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cutoff := time.Now().Add(-d.Settings.Window)
+	for hotkey, a := range d.keys {
+		for hash, seen := range a.payloads {
+			if seen.lastSeen.Before(cutoff) {
+				delete(a.payloads, hash)
+			}
+		}
+		if len(a.payloads) == 0 && (len(a.outcomes) == 0 || a.outcomes[len(a.outcomes)-1].at.Before(cutoff)) {
+			delete(d.keys, hotkey)
+		}
+	}
+}
+
+func (d *AbuseDetector) StartCleanupRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			d.Cleanup()
+		}
+	}()
+}
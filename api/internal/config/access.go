@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+)
+
+// AccessRule restricts what matching callers may verify. A rule applies to a
+// request when every non-empty selector matches it, and an applicable rule
+// denies the request unless all of its limits hold.
+type AccessRule struct {
+	Name string `json:"name"`
+
+	// Selectors
+	Hotkeys []string `json:"hotkeys,omitempty"`
+	Tiers   []string `json:"tiers,omitempty"`
+	CIDRs   []string `json:"cidrs,omitempty"`
+
+	// Limits
+	Models    []string `json:"models,omitempty"`
+	MaxTokens int64    `json:"max_tokens,omitempty"`
+	MaxChunks int      `json:"max_chunks,omitempty"`
+
+	// Message is returned to denied callers instead of the generated reason
+	Message string `json:"message,omitempty"`
+
+	nets []*net.IPNet
+}
+
+// AccessInput holds the request attributes access rules are evaluated against
+type AccessInput struct {
+	Hotkey    string
+	Tier      string
+	IP        net.IP
+	Model     string
+	MaxTokens int64
+	Chunks    int
+}
+
+// Applies reports whether the rule's selectors match the input
+func (r AccessRule) Applies(in AccessInput) bool {
+	if len(r.Hotkeys) > 0 && !slices.Contains(r.Hotkeys, in.Hotkey) {
+		return false
+	}
+	if len(r.Tiers) > 0 && !slices.Contains(r.Tiers, in.Tier) {
+		return false
+	}
+	if len(r.nets) > 0 {
+		if in.IP == nil || !slices.ContainsFunc(r.nets, func(n *net.IPNet) bool { return n.Contains(in.IP) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Violation returns why the input breaks the rule's limits, or "" if it does not
+func (r AccessRule) Violation(in AccessInput) string {
+	if len(r.Models) > 0 && !slices.Contains(r.Models, in.Model) {
+		return fmt.Sprintf("model %s is not permitted", in.Model)
+	}
+	if r.MaxTokens > 0 && in.MaxTokens > r.MaxTokens {
+		return fmt.Sprintf("max_tokens %d exceeds the limit of %d", in.MaxTokens, r.MaxTokens)
+	}
+	if r.MaxChunks > 0 && in.Chunks > r.MaxChunks {
+		return fmt.Sprintf("%d raw_chunks exceeds the limit of %d", in.Chunks, r.MaxChunks)
+	}
+	return ""
+}
+
+// CheckAccess evaluates the rules in order and returns the first one the input
+// violates along with the reason
+func CheckAccess(rules []AccessRule, in AccessInput) (*AccessRule, string) {
+	for i := range rules {
+		if !rules[i].Applies(in) {
+			continue
+		}
+		if reason := rules[i].Violation(in); reason != "" {
+			if rules[i].Message != "" {
+				reason = rules[i].Message
+			}
+			return &rules[i], reason
+		}
+	}
+	return nil, ""
+}
+
+// parseAccessRules reads access rules from a JSON array
+func parseAccessRules(raw string) ([]AccessRule, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var rules []AccessRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("invalid ACCESS_RULES: %w", err)
+	}
+
+	for i := range rules {
+		if rules[i].Name == "" {
+			rules[i].Name = fmt.Sprintf("rule-%d", i)
+		}
+		if rules[i].MaxTokens < 0 || rules[i].MaxChunks < 0 {
+			return nil, fmt.Errorf("invalid ACCESS_RULES: limits of %s must be non-negative", rules[i].Name)
+		}
+		for _, cidr := range rules[i].CIDRs {
+			_, n, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ACCESS_RULES: %s has bad cidr %q", rules[i].Name, cidr)
+			}
+			rules[i].nets = append(rules[i].nets, n)
+		}
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Access log formats
+const (
+	AccessLogJSON    = "json"
+	AccessLogConsole = "console"
+	AccessLogOff     = "off"
+)
+
+// AccessLogSettings controls the one-record-per-request access log. Sample is
+// the fraction of successful requests logged; failed requests are always logged.
+type AccessLogSettings struct {
+	Format    string
+	Sample    float64
+	SkipPaths []string
+}
+
+// parseAccessLogSettings reads the access log settings from the environment
+func parseAccessLogSettings() (AccessLogSettings, []error) {
+	var errs []error
+
+	settings := AccessLogSettings{
+		Format:    getEnv("ACCESS_LOG", AccessLogJSON),
+		SkipPaths: splitList(getEnv("ACCESS_LOG_SKIP_PATHS", "/healthz,/readyz,/metrics")),
+	}
+	if settings.Format != AccessLogJSON && settings.Format != AccessLogConsole && settings.Format != AccessLogOff {
+		errs = append(errs, fmt.Errorf("invalid ACCESS_LOG %q: must be json, console or off", settings.Format))
+	}
+
+	sample, err := strconv.ParseFloat(getEnv("ACCESS_LOG_SAMPLE", "1"), 64)
+	if err != nil || sample < 0 || sample > 1 {
+		errs = append(errs, fmt.Errorf("invalid ACCESS_LOG_SAMPLE: must be between 0 and 1"))
+	}
+	settings.Sample = sample
+
+	return settings, errs
+}
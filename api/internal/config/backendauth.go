@@ -0,0 +1,83 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// BackendAuthSettings is how the proxy proves itself to verifier backends, so
+// they can refuse verification traffic that did not come through it: a client
+// certificate for mTLS, a service token sent with every request, or both
+type BackendAuthSettings struct {
+	CertFile    string
+	KeyFile     string
+	Token       string
+	TokenHeader string
+
+	cert *tls.Certificate
+}
+
+// Apply sets the client certificate on an outbound transport
+func (s BackendAuthSettings) Apply(transport *http.Transport) {
+	if s.cert == nil {
+		return
+	}
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.Certificates = []tls.Certificate{*s.cert}
+}
+
+// SetToken adds the service token to a backend request
+func (s BackendAuthSettings) SetToken(header http.Header) {
+	if s.Token == "" {
+		return
+	}
+	if strings.EqualFold(s.TokenHeader, "Authorization") {
+		header.Set(s.TokenHeader, "Bearer "+s.Token)
+		return
+	}
+	header.Set(s.TokenHeader, s.Token)
+}
+
+// parseBackendAuthSettings reads the backend credentials from the environment.
+// BACKEND_TOKEN_FILE takes precedence over BACKEND_TOKEN, for mounted secrets.
+func parseBackendAuthSettings() (BackendAuthSettings, []error) {
+	var errs []error
+
+	settings := BackendAuthSettings{
+		CertFile:    getEnv("BACKEND_CLIENT_CERT", ""),
+		KeyFile:     getEnv("BACKEND_CLIENT_KEY", ""),
+		Token:       getEnv("BACKEND_TOKEN", ""),
+		TokenHeader: getEnv("BACKEND_TOKEN_HEADER", "Authorization"),
+	}
+
+	if path := getEnv("BACKEND_TOKEN_FILE", ""); path != "" {
+		token, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid BACKEND_TOKEN_FILE: %w", err))
+		}
+		settings.Token = strings.TrimSpace(string(token))
+	}
+
+	if settings.TokenHeader == "" {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_TOKEN_HEADER: must not be empty"))
+	}
+
+	switch {
+	case (settings.CertFile == "") != (settings.KeyFile == ""):
+		errs = append(errs, fmt.Errorf("invalid BACKEND_CLIENT_CERT: BACKEND_CLIENT_CERT and BACKEND_CLIENT_KEY must be set together"))
+	case settings.CertFile != "":
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid BACKEND_CLIENT_CERT: %w", err))
+		} else {
+			settings.cert = &cert
+		}
+	}
+
+	return settings, errs
+}
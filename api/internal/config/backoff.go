@@ -0,0 +1,93 @@
+package config
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type backoffEntry struct {
+	streak   int
+	lastSeen time.Time
+}
+
+// Backoff recommends how long a throttled client should wait before retrying.
+// The wait doubles with each consecutive rejection of a hotkey, never drops
+// below the time the limit it ran into needs to recover and is jittered, so
+// clients rejected together do not all come back at once.
+type Backoff struct {
+	base    time.Duration
+	max     time.Duration
+	entries map[string]*backoffEntry
+	mutex   sync.Mutex
+}
+
+func NewBackoff(base, max time.Duration) *Backoff {
+	return &Backoff{
+		base:    base,
+		max:     max,
+		entries: make(map[string]*backoffEntry),
+	}
+}
+
+// Next records a rejection of the hotkey and returns the wait to recommend,
+// which is at least floor
+func (b *Backoff) Next(hotkey string, floor time.Duration) time.Duration {
+	b.mutex.Lock()
+	now := time.Now()
+	entry, ok := b.entries[hotkey]
+	if !ok {
+		entry = &backoffEntry{}
+		b.entries[hotkey] = entry
+	}
+	// A client that stayed away for the longest backoff starts over
+	if now.Sub(entry.lastSeen) > b.max {
+		entry.streak = 0
+	}
+	entry.streak++
+	entry.lastSeen = now
+	streak := entry.streak
+	b.mutex.Unlock()
+
+	wait := b.max
+	if streak <= 30 {
+		wait = min(b.base<<(streak-1), b.max)
+	}
+	wait = max(wait, floor)
+
+	// Spread retries over up to a tenth of the wait, capped at the base delay
+	if spread := min(wait/10, b.base); spread > 0 {
+		wait += rand.N(spread)
+	}
+	return wait
+}
+
+// Reset forgets the rejections of a hotkey once one of its requests got through
+func (b *Backoff) Reset(hotkey string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.entries, hotkey)
+}
+
+// Cleanup drops hotkeys whose last rejection is older than the longest backoff
+func (b *Backoff) Cleanup() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	cutoff := time.Now().Add(-b.max)
+	for hotkey, entry := range b.entries {
+		if entry.lastSeen.Before(cutoff) {
+			delete(b.entries, hotkey)
+		}
+	}
+}
+
+func (b *Backoff) StartCleanupRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			b.Cleanup()
+		}
+	}()
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"api/internal/metrics"
+)
+
+// Backend selection strategies
+const (
+	BalanceRoundRobin       = "round_robin"
+	BalanceLeastOutstanding = "least_outstanding"
+)
+
+// BackendHealth is the balancer's view of a single backend
+type BackendHealth struct {
+	URL         string    `json:"url"`
+	Healthy     bool      `json:"healthy"`
+	Outstanding int64     `json:"outstanding"`
+	Failures    int       `json:"consecutive_failures"`
+	LastError   string    `json:"last_error,omitempty"`
+	CheckedAt   time.Time `json:"checked_at,omitempty"`
+}
+
+type backendState struct {
+	outstanding atomic.Int64
+	failures    int
+	ejected     bool
+	lastError   string
+	checkedAt   time.Time
+}
+
+// Balancer spreads a model's traffic over the backends of its route and ejects
+// backends that fail ejectAfter probes or calls in a row until one succeeds
+type Balancer struct {
+	strategy   string
+	ejectAfter int
+	states     map[string]*backendState
+	next       map[string]*atomic.Uint64
+	mutex      sync.Mutex
+}
+
+func NewBalancer(strategy string, ejectAfter int) *Balancer {
+	return &Balancer{
+		strategy:   strategy,
+		ejectAfter: ejectAfter,
+		states:     make(map[string]*backendState),
+		next:       make(map[string]*atomic.Uint64),
+	}
+}
+
+func (b *Balancer) state(backend string) *backendState {
+	state, ok := b.states[backend]
+	if !ok {
+		state = &backendState{}
+		b.states[backend] = state
+	}
+	return state
+}
+
+// Pick chooses the backend for a call to model. Ejected backends are skipped
+// unless every backend is ejected, in which case all are tried rather than
+// failing outright.
+func (b *Balancer) Pick(model string, backends []string) string {
+	if len(backends) == 1 {
+		return backends[0]
+	}
+
+	b.mutex.Lock()
+	candidates := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		if !b.state(backend).ejected {
+			candidates = append(candidates, backend)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = backends
+	}
+	counter, ok := b.next[model]
+	if !ok {
+		counter = &atomic.Uint64{}
+		b.next[model] = counter
+	}
+	states := make([]*backendState, len(candidates))
+	for i, backend := range candidates {
+		states[i] = b.state(backend)
+	}
+	b.mutex.Unlock()
+
+	start := int(counter.Add(1) % uint64(len(candidates)))
+	if b.strategy == BalanceRoundRobin {
+		return candidates[start]
+	}
+
+	// Least outstanding, breaking ties in round-robin order
+	best := start
+	for i := 1; i < len(candidates); i++ {
+		j := (start + i) % len(candidates)
+		if states[j].outstanding.Load() < states[best].outstanding.Load() {
+			best = j
+		}
+	}
+	return candidates[best]
+}
+
+// Start counts a call to backend as outstanding until the returned function is called
+func (b *Balancer) Start(backend string) func() {
+	b.mutex.Lock()
+	state := b.state(backend)
+	b.mutex.Unlock()
+
+	state.outstanding.Add(1)
+	return func() { state.outstanding.Add(-1) }
+}
+
+// Report records the outcome of a call or health probe
+func (b *Balancer) Report(backend string, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state := b.state(backend)
+	state.checkedAt = time.Now()
+	if err == nil {
+		state.failures = 0
+		state.lastError = ""
+		state.ejected = false
+		metrics.BackendHealthy.WithLabelValues(backend).Set(1)
+		return
+	}
+
+	state.failures++
+	state.lastError = err.Error()
+	if b.ejectAfter > 0 && state.failures >= b.ejectAfter && !state.ejected {
+		state.ejected = true
+		metrics.BackendHealthy.WithLabelValues(backend).Set(0)
+		fmt.Printf("Warning: Ejected backend %s after %d consecutive failures: %v\n", backend, state.failures, err)
+	}
+}
+
+// Healthy reports whether a backend is in rotation. Backends not seen yet are.
+func (b *Balancer) Healthy(backend string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state, ok := b.states[backend]
+	return !ok || !state.ejected
+}
+
+// All returns the state of every backend seen so far, sorted by URL
+func (b *Balancer) All() []BackendHealth {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	health := make([]BackendHealth, 0, len(b.states))
+	for backend, state := range b.states {
+		health = append(health, BackendHealth{
+			URL:         backend,
+			Healthy:     !state.ejected,
+			Outstanding: state.outstanding.Load(),
+			Failures:    state.failures,
+			LastError:   state.lastError,
+			CheckedAt:   state.checkedAt,
+		})
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].URL < health[j].URL })
+	return health
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// CircuitBreaker stops traffic to a backend after repeated failures
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	states    map[string]*breakerState
+	mutex     sync.Mutex
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		states:    make(map[string]*breakerState),
+	}
+}
+
+// Allow reports whether a request may be sent to the backend. Once the cooldown
+// has passed, a single probe request is let through to test the backend.
+func (b *CircuitBreaker) Allow(backend string) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state, ok := b.states[backend]
+	if !ok || state.failures < b.threshold {
+		return true
+	}
+
+	if time.Now().Before(state.openUntil) || state.probing {
+		return false
+	}
+
+	state.probing = true
+	return true
+}
+
+// Success closes the circuit for the backend
+func (b *CircuitBreaker) Success(backend string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.states, backend)
+}
+
+// Failure records a failed request and opens the circuit once the threshold is reached
+func (b *CircuitBreaker) Failure(backend string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state, ok := b.states[backend]
+	if !ok {
+		state = &breakerState{}
+		b.states[backend] = state
+	}
+
+	state.failures++
+	state.probing = false
+	if state.failures >= b.threshold {
+		state.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// Open reports whether the circuit for the backend is currently open
+func (b *CircuitBreaker) Open(backend string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state, ok := b.states[backend]
+	return ok && b.threshold > 0 && state.failures >= b.threshold && time.Now().Before(state.openUntil)
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Result cache key modes
+const (
+	CacheKeyRequestID = "request_id"
+	CacheKeyContent   = "content"
+)
+
+// ResultCacheSettings sets how long results stay in the result cache by outcome.
+// Failed verdicts and backend errors expire sooner than verified results, so a
+// momentary backend glitch is not served for long; with SkipErrors set, results
+// carrying a backend error are not cached at all.
+type ResultCacheSettings struct {
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	ErrorTTL    time.Duration
+	SkipErrors  bool
+}
+
+// For returns the TTL for a result and whether it should be cached at all
+func (s ResultCacheSettings) For(verified bool, backendError string) (time.Duration, bool) {
+	switch {
+	case backendError != "":
+		return s.ErrorTTL, !s.SkipErrors && s.ErrorTTL > 0
+	case !verified:
+		return s.NegativeTTL, s.NegativeTTL > 0
+	default:
+		return s.TTL, s.TTL > 0
+	}
+}
+
+// parseResultCacheSettings reads the result cache TTLs from the environment
+func parseResultCacheSettings() (ResultCacheSettings, []error) {
+	var errs []error
+
+	var settings ResultCacheSettings
+	var err error
+	settings.SkipErrors, err = strconv.ParseBool(getEnv("CACHE_SKIP_ERRORS", "false"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid CACHE_SKIP_ERRORS: %w", err))
+	}
+	settings.TTL, err = time.ParseDuration(getEnv("CACHE_TTL", "72m"))
+	if err != nil || settings.TTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid CACHE_TTL: must be a non-negative duration"))
+	}
+	settings.NegativeTTL, err = time.ParseDuration(getEnv("CACHE_NEGATIVE_TTL", "10m"))
+	if err != nil || settings.NegativeTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid CACHE_NEGATIVE_TTL: must be a non-negative duration"))
+	}
+	settings.ErrorTTL, err = time.ParseDuration(getEnv("CACHE_ERROR_TTL", "1m"))
+	if err != nil || settings.ErrorTTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid CACHE_ERROR_TTL: must be a non-negative duration"))
+	}
+
+	return settings, errs
+}
+
+// Cache stores verification responses keyed by request ID
+type Cache interface {
+	Set(key string, response []byte, ttl time.Duration)
+	Get(key string) ([]byte, bool)
+	Delete(key string)
+	Close() error
+}
+
+// Close is a no-op for the in-memory cache
+func (c *VerificationCache) Close() error {
+	return nil
+}
+
+// RedisCache is a Cache shared across replicas and deploys
+type RedisCache struct {
+	client  *redis.Client
+	prefix  string
+	timeout time.Duration
+}
+
+func NewRedisCache(url, prefix string) (*RedisCache, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed ping to redis: %w", err)
+	}
+
+	return &RedisCache{
+		client:  client,
+		prefix:  prefix,
+		timeout: 2 * time.Second,
+	}, nil
+}
+
+func (c *RedisCache) Set(key string, response []byte, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.prefix+key, response, ttl).Err(); err != nil {
+		fmt.Printf("Warning: Failed to write cache entry %s: %v\n", key, err)
+	}
+}
+
+func (c *RedisCache) Get(key string) ([]byte, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	response, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			fmt.Printf("Warning: Failed to read cache entry %s: %v\n", key, err)
+		}
+		return nil, false
+	}
+
+	return response, true
+}
+
+// SetNX stores a marker under key unless one exists, reporting whether it was stored
+func (c *RedisCache) SetNX(key string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	return c.client.SetNX(ctx, c.prefix+key, 1, ttl).Result()
+}
+
+func (c *RedisCache) Delete(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.prefix+key).Err(); err != nil {
+		fmt.Printf("Warning: Failed to delete cache entry %s: %v\n", key, err)
+	}
+}
+
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// newCache builds the cache selected by CACHE_BACKEND
+func newCache(backend, redisURL, redisPrefix string) (Cache, error) {
+	switch backend {
+	case "memory":
+		cache := NewVerificationCache()
+		cache.StartCleanupRoutine(5 * time.Minute)
+		return cache, nil
+	case "redis":
+		return NewRedisCache(redisURL, redisPrefix)
+	default:
+		return nil, fmt.Errorf("invalid CACHE_BACKEND %q: must be memory or redis", backend)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Capture target kinds
+const (
+	CaptureRoute     = "route"
+	CaptureRequestID = "request_id"
+)
+
+// CaptureTarget is a route or request ID whose bodies are logged until it expires
+type CaptureTarget struct {
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CaptureRegistry holds the active body capture targets of this replica
+type CaptureRegistry struct {
+	targets map[string]CaptureTarget
+	mutex   sync.RWMutex
+}
+
+func NewCaptureRegistry() *CaptureRegistry {
+	return &CaptureRegistry{
+		targets: make(map[string]CaptureTarget),
+	}
+}
+
+// Add registers a capture target for ttl, replacing any existing one
+func (r *CaptureRegistry) Add(kind, value string, ttl time.Duration) CaptureTarget {
+	target := CaptureTarget{Kind: kind, Value: value, ExpiresAt: time.Now().Add(ttl)}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.targets[kind+":"+value] = target
+	return target
+}
+
+// Remove drops a capture target, reporting whether it existed
+func (r *CaptureRegistry) Remove(kind, value string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := kind + ":" + value
+	_, ok := r.targets[key]
+	delete(r.targets, key)
+	return ok
+}
+
+// Matches reports whether an unexpired target covers the given kind and value
+func (r *CaptureRegistry) Matches(kind, value string) bool {
+	if value == "" {
+		return false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	target, ok := r.targets[kind+":"+value]
+	return ok && time.Now().Before(target.ExpiresAt)
+}
+
+// Empty reports whether no targets are registered
+func (r *CaptureRegistry) Empty() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.targets) == 0
+}
+
+// All returns the unexpired targets, dropping expired ones
+func (r *CaptureRegistry) All() []CaptureTarget {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	targets := make([]CaptureTarget, 0, len(r.targets))
+	for key, target := range r.targets {
+		if now.After(target.ExpiresAt) {
+			delete(r.targets, key)
+			continue
+		}
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Kind != targets[j].Kind {
+			return targets[i].Kind < targets[j].Kind
+		}
+		return targets[i].Value < targets[j].Value
+	})
+	return targets
+}
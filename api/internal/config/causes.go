@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Stable cause codes reported alongside the backend's free-form cause
+const (
+	CauseTokenMismatch   = "token_mismatch"
+	CauseLogprobMismatch = "logprob_mismatch"
+	CauseTimeout         = "timeout"
+	CauseInvalidResponse = "invalid_response"
+	CauseConsensusFailed = "consensus_failed"
+	CausePolicyRejected  = "policy_rejected"
+	CauseUnknown         = "unknown"
+	CauseOverridden      = "manual_override"
+)
+
+var causeCodes = map[string]bool{
+	CauseTokenMismatch:   true,
+	CauseLogprobMismatch: true,
+	CauseTimeout:         true,
+	CauseInvalidResponse: true,
+	CauseConsensusFailed: true,
+	CausePolicyRejected:  true,
+	CauseUnknown:         true,
+	CauseOverridden:      true,
+}
+
+// CauseRule maps raw causes matching a regular expression to a cause code
+type CauseRule struct {
+	Match string `json:"match"`
+	Code  string `json:"code"`
+
+	re *regexp.Regexp
+}
+
+// defaultCauseRules apply to every model after any model-specific rules
+var defaultCauseRules = mustCompileCauseRules([]CauseRule{
+	{Match: `(?i)rejected by policy`, Code: CausePolicyRejected},
+	{Match: `(?i)consensus not reached`, Code: CauseConsensusFailed},
+	{Match: `(?i)time(d)? ?out`, Code: CauseTimeout},
+	{Match: `(?i)logprob`, Code: CauseLogprobMismatch},
+	{Match: `(?i)token`, Code: CauseTokenMismatch},
+	{Match: `(?i)invalid|malformed|parse`, Code: CauseInvalidResponse},
+})
+
+// CompileCauseRules validates rules and prepares their expressions
+func CompileCauseRules(rules []CauseRule) ([]CauseRule, error) {
+	compiled := make([]CauseRule, len(rules))
+	for i, rule := range rules {
+		if !causeCodes[rule.Code] {
+			return nil, fmt.Errorf("unknown cause code %q", rule.Code)
+		}
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cause pattern %q: %w", rule.Match, err)
+		}
+		compiled[i] = CauseRule{Match: rule.Match, Code: rule.Code, re: re}
+	}
+	return compiled, nil
+}
+
+func mustCompileCauseRules(rules []CauseRule) []CauseRule {
+	compiled, err := CompileCauseRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// ClassifyCause returns the cause code for a raw cause, trying the given rules
+// before the defaults. An empty cause has no code.
+func ClassifyCause(rules []CauseRule, cause string) string {
+	if cause == "" {
+		return ""
+	}
+
+	for _, set := range [][]CauseRule{rules, defaultCauseRules} {
+		for _, rule := range set {
+			if rule.re != nil && rule.re.MatchString(cause) {
+				return rule.Code
+			}
+		}
+	}
+
+	return CauseUnknown
+}
@@ -1,15 +1,21 @@
 package config
 
 import (
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"api/internal/apikey"
+	"api/internal/metagraph"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type CacheEntry struct {
@@ -23,10 +29,76 @@ type VerificationCache struct {
 }
 
 type Environment struct {
-	Debug         bool
-	HaproxyURL    string
-	AdminHotkey   string
-	AdminKeyValue string
+	Debug             bool
+	HaproxyURL        string
+	AdminHotkey       string
+	AdminKeyValue     string
+	ConsensusBackends []string
+	ConsensusMode     string
+	ConsensusModels   []string
+	DedupWindow       time.Duration
+	AsyncWorkers      int
+	AsyncJobLease     time.Duration
+	RequireChallenge  bool
+	BackendRetries    int
+	BackendRetryBase  time.Duration
+	BackendRetryMax   time.Duration
+	BackendPrewarm    int
+	BackendDNSRefresh time.Duration
+	BackendHealthTick time.Duration
+	MetagraphInterval time.Duration
+	RateLimitRPS      float64
+	RateLimitBurst    int
+	EpochQuota        int
+	KeyRotationGrace  time.Duration
+	AdminSessionTTL   time.Duration
+	LegacySunset      string
+	ShutdownTimeout   time.Duration
+	Server            ServerSettings
+	SchemaCheck       string
+	Migrate           bool
+	EpistulaAuth      bool
+	EpistulaMaxSkew   time.Duration
+	EpistulaReceiver  string
+	WarmupKeys        int
+	WarmupRequest     string
+	CanaryRequest     string
+	CanaryInterval    time.Duration
+	CanaryMaxLatency  time.Duration
+	TracingEndpoint   string
+	TracingSample     float64
+	ErrorSinkDSN      string
+	ErrorSinkURL      string
+	ErrorSinkEnv      string
+	Priority          PrioritySettings
+	ShadowBackendURL  string
+	ShadowPercent     float64
+	Quarantine        QuarantineSettings
+	Sanitize          SanitizeSettings
+	ValidatePayloads  bool
+	ValidateMaxChunks int
+	CaptureRedact     []string
+	CaptureMaxBytes   int
+	DailyQuota        int
+	CacheKeyMode      string
+	IDs               IDSettings
+	Outbound          OutboundSettings
+	AnalyticsMirror   MirrorSettings
+	Mock              MockSettings
+	Soak              SoakSettings
+	Messages          MessageTemplates
+	AccessLog         AccessLogSettings
+	KeyPrune          KeyPruneSettings
+	LogRetention      LogRetentionSettings
+	ExpectTTL         time.Duration
+	BackendAuth       BackendAuthSettings
+	ShutdownReportURL string
+	BackendGzip       bool
+	CompleteAbandoned bool
+	ResultCache       ResultCacheSettings
+
+	BackendDefaultTimeout time.Duration
+	BackendModelTimeouts  map[string]time.Duration
 }
 
 func NewVerificationCache() *VerificationCache {
@@ -66,6 +138,21 @@ func (c *VerificationCache) Get(requestID string) ([]byte, bool) {
 	return entry.Response, true
 }
 
+func (c *VerificationCache) Delete(requestID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.cache, requestID)
+}
+
+// Len returns the number of entries, including expired ones not cleaned up yet
+func (c *VerificationCache) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return len(c.cache)
+}
+
 func (c *VerificationCache) Cleanup() {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -89,14 +176,65 @@ func (c *VerificationCache) StartCleanupRoutine(interval time.Duration) {
 
 type Config struct {
 	Env       Environment
-	SqlClient *sql.DB
-	Cache     *VerificationCache
+	SqlClient *DB
+	Cache     Cache
+	Abuse     *AbuseDetector
+	JobQueue  chan string
+	Mirror    chan []byte
+	Breaker   *CircuitBreaker
+	Routes    *RouteTable
+	Captures  *CaptureRegistry
+	Usage     *UsageTracker
+	Decisions *DecisionLog
+	Payloads  *PayloadLibrary
+	Legacy    *LegacyTracker
+	Metagraph *metagraph.Store
+	Keys      *KeyCache
+	Limiter   *RateLimiter
+	Backoff   *Backoff
+	Weights   *StakeWeight
+	Chain     *metagraph.Chain
+	Epochs    *EpochUsage
+	Versions  *VersionTracker
+	Inflight  *singleflight.Group
+	Database  *DatabaseMonitor
+	Nonces    *NonceCache
+	Transport *BackendTransport
+	Client    *http.Client
+	Workers   *BackendPool
+	Expected  *Expectations
+	Traffic   *Traffic
+	Balancer  *Balancer
+	Timeouts  *AdaptiveTimeouts
+
+	// Warm is set once the startup warm-up has finished
+	Warm atomic.Bool
+
+	runtime atomic.Pointer[Runtime]
 }
 
 func (c *Config) Shutdown() {
+	if c.Keys != nil {
+		if err := c.Keys.Flush(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	if c.Usage != nil {
+		if err := c.Usage.Flush(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+	if c.Decisions != nil {
+		if err := c.Decisions.Flush(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
 	if c.SqlClient != nil {
 		c.SqlClient.Close()
 	}
+	if c.Cache != nil {
+		c.Cache.Close()
+	}
 }
 
 func getEnv(env, fallback string) string {
@@ -106,6 +244,17 @@ func getEnv(env, fallback string) string {
 	return fallback
 }
 
+// splitList splits a comma-separated env value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func InitConfig() (*Config, []error) {
 	var errs []error
 
@@ -114,8 +263,25 @@ func InitConfig() (*Config, []error) {
 	mysqlPassword := getEnv("MYSQL_PASSWORD", "adminpassword")
 	mysqlDatabase := getEnv("MYSQL_DATABASE", "targon_proxy")
 
-	DSN := fmt.Sprintf("%s:%s@tcp(%s:3306)/%s?parseTime=true",
-		mysqlUser, mysqlPassword, mysqlHost, mysqlDatabase)
+	DB_DRIVER := Dialect(getEnv("DB_DRIVER", string(DialectMySQL)))
+	DSN := getEnv("DB_DSN", "")
+	switch DB_DRIVER {
+	case DialectMySQL:
+		if DSN == "" {
+			DSN = fmt.Sprintf("%s:%s@tcp(%s:3306)/%s?parseTime=true",
+				mysqlUser, mysqlPassword, mysqlHost, mysqlDatabase)
+		}
+	case DialectPostgres:
+		if DSN == "" {
+			errs = append(errs, fmt.Errorf("DB_DSN is required when DB_DRIVER is postgres"))
+		}
+	case DialectSQLite:
+		if DSN == "" {
+			DSN = "file:verifier-proxy.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid DB_DRIVER %q: must be mysql, postgres or sqlite", DB_DRIVER))
+	}
 
 	HAPROXY_URL := getEnv("HAPROXY_URL", "http://haproxy")
 
@@ -127,11 +293,422 @@ func InitConfig() (*Config, []error) {
 		errs = append(errs, err)
 	}
 
+	CONSENSUS_BACKENDS := splitList(getEnv("CONSENSUS_BACKENDS", ""))
+	CONSENSUS_MODELS := splitList(getEnv("CONSENSUS_MODELS", ""))
+	CONSENSUS_MODE := getEnv("CONSENSUS_MODE", "majority")
+	if CONSENSUS_MODE != "majority" && CONSENSUS_MODE != "unanimous" {
+		errs = append(errs, fmt.Errorf("invalid CONSENSUS_MODE %q: must be majority or unanimous", CONSENSUS_MODE))
+	}
+
+	ABUSE_WINDOW, err := time.ParseDuration(getEnv("ABUSE_WINDOW", "10m"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid ABUSE_WINDOW: %w", err))
+	}
+	ABUSE_MIN_SAMPLES, err := strconv.Atoi(getEnv("ABUSE_MIN_SAMPLES", "20"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid ABUSE_MIN_SAMPLES: %w", err))
+	}
+	ABUSE_FAILURE_RATE, err := strconv.ParseFloat(getEnv("ABUSE_FAILURE_RATE", "0.5"), 64)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid ABUSE_FAILURE_RATE: %w", err))
+	}
+	ABUSE_RESUBMIT_THRESHOLD, err := strconv.Atoi(getEnv("ABUSE_RESUBMIT_THRESHOLD", "3"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid ABUSE_RESUBMIT_THRESHOLD: %w", err))
+	}
+	ABUSE_AUTO_THROTTLE, err := strconv.ParseBool(getEnv("ABUSE_AUTO_THROTTLE", "false"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid ABUSE_AUTO_THROTTLE: %w", err))
+	}
+	ABUSE_THROTTLE_INTERVAL, err := time.ParseDuration(getEnv("ABUSE_THROTTLE_INTERVAL", "10s"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid ABUSE_THROTTLE_INTERVAL: %w", err))
+	}
+
+	DEDUP_WINDOW, err := time.ParseDuration(getEnv("DEDUP_WINDOW", "10m"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid DEDUP_WINDOW: %w", err))
+	}
+
+	ASYNC_WORKERS, err := strconv.Atoi(getEnv("ASYNC_WORKERS", "8"))
+	if err != nil || ASYNC_WORKERS < 1 {
+		errs = append(errs, fmt.Errorf("invalid ASYNC_WORKERS: must be a positive integer"))
+	}
+	ASYNC_JOB_LEASE, err := time.ParseDuration(getEnv("ASYNC_JOB_LEASE", "10m"))
+	if err != nil || ASYNC_JOB_LEASE <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ASYNC_JOB_LEASE: must be a positive duration"))
+	}
+	ASYNC_QUEUE_SIZE, err := strconv.Atoi(getEnv("ASYNC_QUEUE_SIZE", "1000"))
+	if err != nil || ASYNC_QUEUE_SIZE < 1 {
+		errs = append(errs, fmt.Errorf("invalid ASYNC_QUEUE_SIZE: must be a positive integer"))
+	}
+
+	PAYLOAD_RETENTION := getEnv("PAYLOAD_RETENTION", RetentionFull)
+	if !validRetention(PAYLOAD_RETENTION) {
+		errs = append(errs, fmt.Errorf("invalid PAYLOAD_RETENTION %q: must be full, metadata or none", PAYLOAD_RETENTION))
+	}
+	retentionModels, err := parseRetentionMap("PAYLOAD_RETENTION_MODELS", getEnv("PAYLOAD_RETENTION_MODELS", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	retentionTiers, err := parseRetentionMap("PAYLOAD_RETENTION_TIERS", getEnv("PAYLOAD_RETENTION_TIERS", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	REQUIRE_HOTKEY_CHALLENGE, err := strconv.ParseBool(getEnv("REQUIRE_HOTKEY_CHALLENGE", "false"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid REQUIRE_HOTKEY_CHALLENGE: %w", err))
+	}
+
+	BACKEND_PREWARM_CONNS, err := strconv.Atoi(getEnv("BACKEND_PREWARM_CONNS", "4"))
+	if err != nil || BACKEND_PREWARM_CONNS < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_PREWARM_CONNS: must be a non-negative integer"))
+	}
+	BACKEND_POOL, backendPoolErrs := parseBackendPoolSettings()
+	errs = append(errs, backendPoolErrs...)
+	BACKEND_DNS_REFRESH, err := time.ParseDuration(getEnv("BACKEND_DNS_REFRESH", "30s"))
+	if err != nil || BACKEND_DNS_REFRESH < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_DNS_REFRESH: must be a non-negative duration"))
+	}
+
+	// The worker settings replace the older concurrency limits, which are still honoured
+	BACKEND_WORKERS, err := strconv.Atoi(getEnv("BACKEND_WORKERS", getEnv("BACKEND_MAX_CONCURRENCY", "0")))
+	if err != nil || BACKEND_WORKERS < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_WORKERS: must be a non-negative integer"))
+	}
+	BACKEND_MODEL_WORKERS, err := parseWorkerMap("BACKEND_MODEL_WORKERS", getEnv("BACKEND_MODEL_WORKERS", getEnv("BACKEND_MODEL_CONCURRENCY", "")))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	BACKEND_QUEUE_SIZE, err := strconv.Atoi(getEnv("BACKEND_QUEUE_SIZE", "100"))
+	if err != nil || BACKEND_QUEUE_SIZE < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_QUEUE_SIZE: must be a non-negative integer"))
+	}
+	BACKEND_QUEUE_TIMEOUT, err := time.ParseDuration(getEnv("BACKEND_QUEUE_TIMEOUT", "2s"))
+	if err != nil || BACKEND_QUEUE_TIMEOUT < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_QUEUE_TIMEOUT: must be a non-negative duration"))
+	}
+
+	PRIORITY, priorityErrs := parsePrioritySettings()
+	errs = append(errs, priorityErrs...)
+
+	BACKEND_BALANCE := getEnv("BACKEND_BALANCE", BalanceLeastOutstanding)
+	if BACKEND_BALANCE != BalanceLeastOutstanding && BACKEND_BALANCE != BalanceRoundRobin {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_BALANCE %q: must be least_outstanding or round_robin", BACKEND_BALANCE))
+	}
+	BACKEND_EJECT_FAILURES, err := strconv.Atoi(getEnv("BACKEND_EJECT_FAILURES", "3"))
+	if err != nil || BACKEND_EJECT_FAILURES < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_EJECT_FAILURES: must be a non-negative integer"))
+	}
+	BACKEND_HEALTH_INTERVAL, err := time.ParseDuration(getEnv("BACKEND_HEALTH_INTERVAL", "10s"))
+	if err != nil || BACKEND_HEALTH_INTERVAL < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_HEALTH_INTERVAL: must be a non-negative duration"))
+	}
+
+	// Compressing requests to the backend needs a verifier that accepts gzip bodies
+	BACKEND_GZIP := strings.ToLower(getEnv("BACKEND_GZIP", "false")) == "true"
+	// Finishing a call whose client left still caches the verdict for its retry
+	BACKEND_COMPLETE_ABANDONED := strings.ToLower(getEnv("BACKEND_COMPLETE_ABANDONED", "false")) == "true"
+
+	BACKEND_RETRIES, err := strconv.Atoi(getEnv("BACKEND_RETRIES", "2"))
+	if err != nil || BACKEND_RETRIES < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_RETRIES: must be a non-negative integer"))
+	}
+	BACKEND_RETRY_BASE, err := time.ParseDuration(getEnv("BACKEND_RETRY_BASE", "250ms"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_RETRY_BASE: %w", err))
+	}
+	BACKEND_RETRY_MAX, err := time.ParseDuration(getEnv("BACKEND_RETRY_MAX", "5s"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_RETRY_MAX: %w", err))
+	}
+	BREAKER_THRESHOLD, err := strconv.Atoi(getEnv("BREAKER_THRESHOLD", "5"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid BREAKER_THRESHOLD: %w", err))
+	}
+	BREAKER_COOLDOWN, err := time.ParseDuration(getEnv("BREAKER_COOLDOWN", "30s"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid BREAKER_COOLDOWN: %w", err))
+	}
+
+	// Announced request IDs hold a wait queue place for at most EXPECT_TTL
+	EXPECT_TTL, err := time.ParseDuration(getEnv("EXPECT_TTL", "5m"))
+	if err != nil || EXPECT_TTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid EXPECT_TTL: must be a positive duration"))
+	}
+	EXPECT_MAX_PER_KEY, err := strconv.Atoi(getEnv("EXPECT_MAX_PER_KEY", "1000"))
+	if err != nil || EXPECT_MAX_PER_KEY < 0 {
+		errs = append(errs, fmt.Errorf("invalid EXPECT_MAX_PER_KEY: must be a non-negative integer"))
+	}
+
+	// Throttled clients are told to wait BACKOFF_BASE, doubling per consecutive
+	// rejection up to BACKOFF_MAX
+	BACKOFF_BASE, err := time.ParseDuration(getEnv("BACKOFF_BASE", "250ms"))
+	if err != nil || BACKOFF_BASE <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKOFF_BASE: must be a positive duration"))
+	}
+	BACKOFF_MAX, err := time.ParseDuration(getEnv("BACKOFF_MAX", "1m"))
+	if err != nil || BACKOFF_MAX < BACKOFF_BASE {
+		errs = append(errs, fmt.Errorf("invalid BACKOFF_MAX: must be a duration of at least BACKOFF_BASE"))
+	}
+
+	METAGRAPH_URL := getEnv("METAGRAPH_URL", "")
+	METAGRAPH_NETUID, err := strconv.Atoi(getEnv("METAGRAPH_NETUID", "4"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid METAGRAPH_NETUID: %w", err))
+	}
+	METAGRAPH_MIN_STAKE, err := strconv.ParseFloat(getEnv("METAGRAPH_MIN_STAKE", "1000"), 64)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid METAGRAPH_MIN_STAKE: %w", err))
+	}
+	METAGRAPH_REQUIRE_PERMIT, err := strconv.ParseBool(getEnv("METAGRAPH_REQUIRE_PERMIT", "true"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid METAGRAPH_REQUIRE_PERMIT: %w", err))
+	}
+	METAGRAPH_SYNC_INTERVAL, err := time.ParseDuration(getEnv("METAGRAPH_SYNC_INTERVAL", "10m"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid METAGRAPH_SYNC_INTERVAL: %w", err))
+	}
+
+	KEY_CACHE_TTL, err := time.ParseDuration(getEnv("KEY_CACHE_TTL", "30s"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid KEY_CACHE_TTL: %w", err))
+	}
+	KEY_CACHE_STALE_TTL, err := time.ParseDuration(getEnv("KEY_CACHE_STALE_TTL", "1h"))
+	if err != nil || KEY_CACHE_STALE_TTL < 0 {
+		errs = append(errs, fmt.Errorf("invalid KEY_CACHE_STALE_TTL: must be a non-negative duration"))
+	}
+	DEGRADED_MODE := strings.ToLower(getEnv("DEGRADED_MODE", "true")) == "true"
+	KEY_USAGE_FLUSH_INTERVAL, err := time.ParseDuration(getEnv("KEY_USAGE_FLUSH_INTERVAL", "10s"))
+	if err != nil || KEY_USAGE_FLUSH_INTERVAL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid KEY_USAGE_FLUSH_INTERVAL: must be a positive duration"))
+	}
+
+	DAILY_QUOTA, err := strconv.Atoi(getEnv("DAILY_QUOTA", "0"))
+	if err != nil || DAILY_QUOTA < 0 {
+		errs = append(errs, fmt.Errorf("invalid DAILY_QUOTA: must be a non-negative integer"))
+	}
+
+	RATE_LIMIT_RPS, err := strconv.ParseFloat(getEnv("RATE_LIMIT_RPS", "0"), 64)
+	if err != nil || RATE_LIMIT_RPS < 0 {
+		errs = append(errs, fmt.Errorf("invalid RATE_LIMIT_RPS: must be a non-negative number"))
+	}
+	RATE_LIMIT_BURST, err := strconv.Atoi(getEnv("RATE_LIMIT_BURST", "10"))
+	if err != nil || RATE_LIMIT_BURST < 1 {
+		errs = append(errs, fmt.Errorf("invalid RATE_LIMIT_BURST: must be a positive integer"))
+	}
+	weights, err := NewStakeWeight(getEnv("STAKE_WEIGHT_EXPR", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	SUBTENSOR_URL := getEnv("SUBTENSOR_URL", "")
+	SUBNET_TEMPO, err := strconv.ParseInt(getEnv("SUBNET_TEMPO", "360"), 10, 64)
+	if err != nil || SUBNET_TEMPO < 1 {
+		errs = append(errs, fmt.Errorf("invalid SUBNET_TEMPO: must be a positive integer"))
+	}
+	EPOCH_QUOTA, err := strconv.Atoi(getEnv("EPOCH_QUOTA", "0"))
+	if err != nil || EPOCH_QUOTA < 0 {
+		errs = append(errs, fmt.Errorf("invalid EPOCH_QUOTA: must be a non-negative integer"))
+	}
+
+	KEY_ROTATION_GRACE, err := time.ParseDuration(getEnv("KEY_ROTATION_GRACE", "0s"))
+	if err != nil || KEY_ROTATION_GRACE < 0 {
+		errs = append(errs, fmt.Errorf("invalid KEY_ROTATION_GRACE: must be a non-negative duration"))
+	}
+
+	ADMIN_SESSION_TTL, err := time.ParseDuration(getEnv("ADMIN_SESSION_TTL", "15m"))
+	if err != nil || ADMIN_SESSION_TTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ADMIN_SESSION_TTL: must be a positive duration"))
+	}
+
+	LEGACY_SUNSET := getEnv("LEGACY_SUNSET", "")
+	if LEGACY_SUNSET != "" {
+		if sunset, err := time.Parse(time.DateOnly, LEGACY_SUNSET); err != nil {
+			errs = append(errs, fmt.Errorf("invalid LEGACY_SUNSET: must be a date like 2006-01-02"))
+		} else {
+			LEGACY_SUNSET = sunset.UTC().Format(http.TimeFormat)
+		}
+	}
+
+	MIN_BACKEND_VERSION := getEnv("MIN_BACKEND_VERSION", "")
+	if MIN_BACKEND_VERSION != "" {
+		if _, err := parseVersion(MIN_BACKEND_VERSION); err != nil {
+			errs = append(errs, fmt.Errorf("invalid MIN_BACKEND_VERSION: %w", err))
+		}
+	}
+	BACKEND_VERSION_ENFORCE := strings.ToLower(getEnv("BACKEND_VERSION_ENFORCE", "false")) == "true"
+
+	SHUTDOWN_TIMEOUT, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "60s"))
+	if err != nil || SHUTDOWN_TIMEOUT <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SHUTDOWN_TIMEOUT: must be a positive duration"))
+	}
+
+	EPISTULA_AUTH := strings.ToLower(getEnv("EPISTULA_AUTH", "false")) == "true"
+	EPISTULA_MAX_SKEW, err := time.ParseDuration(getEnv("EPISTULA_MAX_SKEW", "30s"))
+	if err != nil || EPISTULA_MAX_SKEW <= 0 {
+		errs = append(errs, fmt.Errorf("invalid EPISTULA_MAX_SKEW: must be a positive duration"))
+	}
+	EPISTULA_RECEIVER := getEnv("EPISTULA_RECEIVER", "")
+	if EPISTULA_AUTH && EPISTULA_RECEIVER == "" {
+		// Without it, signatures made for any other receiver would be accepted
+		errs = append(errs, fmt.Errorf("EPISTULA_RECEIVER is required when EPISTULA_AUTH is true"))
+	}
+
+	WARMUP_KEYS, err := strconv.Atoi(getEnv("WARMUP_KEYS", "1000"))
+	if err != nil || WARMUP_KEYS < 0 {
+		errs = append(errs, fmt.Errorf("invalid WARMUP_KEYS: must be a non-negative integer"))
+	}
+	WARMUP_REQUEST := getEnv("WARMUP_REQUEST", "")
+	if WARMUP_REQUEST != "" && !json.Valid([]byte(WARMUP_REQUEST)) {
+		errs = append(errs, fmt.Errorf("invalid WARMUP_REQUEST: must be a JSON verification request"))
+	}
+
+	CANARY_REQUEST := getEnv("CANARY_REQUEST", "")
+	if CANARY_REQUEST != "" && !json.Valid([]byte(CANARY_REQUEST)) {
+		errs = append(errs, fmt.Errorf("invalid CANARY_REQUEST: must be a JSON verification request"))
+	}
+	CANARY_INTERVAL, err := time.ParseDuration(getEnv("CANARY_INTERVAL", "1m"))
+	if err != nil || CANARY_INTERVAL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid CANARY_INTERVAL: must be a positive duration"))
+	}
+	CANARY_MAX_LATENCY, err := time.ParseDuration(getEnv("CANARY_MAX_LATENCY", "30s"))
+	if err != nil || CANARY_MAX_LATENCY <= 0 {
+		errs = append(errs, fmt.Errorf("invalid CANARY_MAX_LATENCY: must be a positive duration"))
+	}
+
+	SHADOW_BACKEND_URL := strings.TrimSuffix(getEnv("SHADOW_BACKEND_URL", ""), "/")
+	SHADOW_PERCENT, err := strconv.ParseFloat(getEnv("SHADOW_PERCENT", "0"), 64)
+	if err != nil || SHADOW_PERCENT < 0 || SHADOW_PERCENT > 100 {
+		errs = append(errs, fmt.Errorf("invalid SHADOW_PERCENT: must be between 0 and 100"))
+	}
+
+	TRACING_ENDPOINT := getEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""))
+	TRACING_SAMPLE, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil || TRACING_SAMPLE < 0 || TRACING_SAMPLE > 1 {
+		errs = append(errs, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: must be between 0 and 1"))
+	}
+
+	ERROR_SINK_DSN := getEnv("SENTRY_DSN", "")
+	ERROR_SINK_URL := getEnv("ERROR_SINK_URL", "")
+	ERROR_SINK_ENV := getEnv("ERROR_SINK_ENVIRONMENT", "production")
+	if ERROR_SINK_URL != "" && !strings.HasPrefix(ERROR_SINK_URL, "http://") && !strings.HasPrefix(ERROR_SINK_URL, "https://") {
+		errs = append(errs, fmt.Errorf("invalid ERROR_SINK_URL: must be an http(s) URL"))
+	}
+
+	SCHEMA_CHECK := getEnv("SCHEMA_CHECK", "strict")
+	if SCHEMA_CHECK != "strict" && SCHEMA_CHECK != "warn" && SCHEMA_CHECK != "off" {
+		errs = append(errs, fmt.Errorf("invalid SCHEMA_CHECK %q: must be strict, warn or off", SCHEMA_CHECK))
+	}
+
+	DB_MIGRATE := strings.ToLower(getEnv("DB_MIGRATE", "true")) == "true"
+
+	SERVER, serverErrs := parseServerSettings()
+	errs = append(errs, serverErrs...)
+
+	QUARANTINE, quarantineErrs := parseQuarantineSettings()
+	errs = append(errs, quarantineErrs...)
+
+	VALIDATE_PAYLOADS := strings.ToLower(getEnv("VALIDATE_PAYLOADS", "true")) == "true"
+	VALIDATE_MAX_CHUNKS, err := strconv.Atoi(getEnv("VALIDATE_MAX_CHUNKS", "50000"))
+	if err != nil || VALIDATE_MAX_CHUNKS < 0 {
+		errs = append(errs, fmt.Errorf("invalid VALIDATE_MAX_CHUNKS: must be a non-negative integer"))
+	}
+
+	SANITIZE, sanitizeErrs := parseSanitizeSettings()
+	errs = append(errs, sanitizeErrs...)
+
+	IDS, idErrs := parseIDSettings()
+	errs = append(errs, idErrs...)
+
+	OUTBOUND, outboundErrs := parseOutboundSettings()
+	errs = append(errs, outboundErrs...)
+
+	ANALYTICS_MIRROR, mirrorErrs := parseMirrorSettings()
+	errs = append(errs, mirrorErrs...)
+
+	MOCK, mockErrs := parseMockSettings()
+	errs = append(errs, mockErrs...)
+	if MOCK.Enabled {
+		fmt.Printf("Warning: MOCK_BACKEND is enabled, verdicts are scripted and no backend is contacted\n")
+	}
+
+	SOAK, soakErrs := parseSoakSettings(MOCK)
+	errs = append(errs, soakErrs...)
+
+	ERROR_MESSAGES, messageErrs := parseMessageTemplates()
+	errs = append(errs, messageErrs...)
+
+	ACCESS_LOG, accessLogErrs := parseAccessLogSettings()
+	errs = append(errs, accessLogErrs...)
+
+	KEY_PRUNE, keyPruneErrs := parseKeyPruneSettings()
+	errs = append(errs, keyPruneErrs...)
+
+	LOG_RETENTION, logRetentionErrs := parseLogRetentionSettings()
+	errs = append(errs, logRetentionErrs...)
+
+	BACKEND_AUTH, backendAuthErrs := parseBackendAuthSettings()
+	errs = append(errs, backendAuthErrs...)
+
+	SHUTDOWN_REPORT_URL, err := parseShutdownReportURL()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	CAPTURE_REDACT_FIELDS := splitList(getEnv("CAPTURE_REDACT_FIELDS", "api_key,authorization,password,secret,token"))
+	CAPTURE_MAX_BYTES, err := strconv.Atoi(getEnv("CAPTURE_MAX_BYTES", "65536"))
+	if err != nil || CAPTURE_MAX_BYTES <= 0 {
+		errs = append(errs, fmt.Errorf("invalid CAPTURE_MAX_BYTES: must be a positive integer"))
+	}
+
+	BACKEND_TIMEOUT, err := time.ParseDuration(getEnv("BACKEND_TIMEOUT", "120s"))
+	if err != nil || BACKEND_TIMEOUT <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_TIMEOUT: must be a positive duration"))
+	}
+	ADAPTIVE_TIMEOUTS, adaptiveErrs := parseAdaptiveTimeoutSettings()
+	errs = append(errs, adaptiveErrs...)
+	BACKEND_MODEL_TIMEOUTS, err := parseTimeoutMap("BACKEND_MODEL_TIMEOUTS", getEnv("BACKEND_MODEL_TIMEOUTS", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if SERVER.WriteTimeout > 0 {
+		for model, timeout := range BACKEND_MODEL_TIMEOUTS {
+			if timeout > SERVER.WriteTimeout {
+				fmt.Printf("Warning: backend timeout for %s (%s) exceeds SERVER_WRITE_TIMEOUT (%s)\n", model, timeout, SERVER.WriteTimeout)
+			}
+		}
+		if BACKEND_TIMEOUT > SERVER.WriteTimeout {
+			fmt.Printf("Warning: BACKEND_TIMEOUT (%s) exceeds SERVER_WRITE_TIMEOUT (%s)\n", BACKEND_TIMEOUT, SERVER.WriteTimeout)
+		}
+	}
+
+	CACHE_BACKEND := getEnv("CACHE_BACKEND", "memory")
+	CACHE_KEY_MODE := getEnv("CACHE_KEY_MODE", CacheKeyRequestID)
+	if CACHE_KEY_MODE != CacheKeyRequestID && CACHE_KEY_MODE != CacheKeyContent {
+		errs = append(errs, fmt.Errorf("invalid CACHE_KEY_MODE %q: must be request_id or content", CACHE_KEY_MODE))
+	}
+	RESULT_CACHE, resultCacheErrs := parseResultCacheSettings()
+	errs = append(errs, resultCacheErrs...)
+	REDIS_URL := getEnv("REDIS_URL", "redis://redis:6379/0")
+	REDIS_PREFIX := getEnv("REDIS_PREFIX", "verifier-proxy:")
+
+	policies, err := parsePolicies(getEnv("VERIFY_POLICIES", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	accessRules, err := parseAccessRules(getEnv("ACCESS_RULES", ""))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) != 0 {
 		return nil, errs
 	}
 
-	sqlClient, err := sql.Open("mysql", DSN)
+	sqlClient, err := OpenDB(DB_DRIVER, DSN)
 	if err != nil {
 		return nil, []error{errors.New("failed initializing sqlClient"), err}
 	}
@@ -141,21 +718,194 @@ func InitConfig() (*Config, []error) {
 		return nil, []error{errors.New("failed ping to sql db"), err}
 	}
 
-	cache := NewVerificationCache()
-	cache.StartCleanupRoutine(5 * time.Minute)
+	if err := migratePlaintextKeys(sqlClient); err != nil {
+		return nil, []error{errors.New("failed migrating API keys"), err}
+	}
+
+	if DB_MIGRATE {
+		applied, err := Migrate(sqlClient)
+		if err != nil {
+			return nil, []error{errors.New("failed migrating database schema"), err}
+		}
+		for _, name := range applied {
+			fmt.Printf("Applied migration %s\n", name)
+		}
+	}
+
+	cache, err := newCache(CACHE_BACKEND, REDIS_URL, REDIS_PREFIX)
+	if err != nil {
+		return nil, []error{errors.New("failed initializing cache"), err}
+	}
+	redisCache, _ := cache.(*RedisCache)
+
+	abuse := NewAbuseDetector(AbuseSettings{
+		Window:            ABUSE_WINDOW,
+		MinSamples:        ABUSE_MIN_SAMPLES,
+		FailureRate:       ABUSE_FAILURE_RATE,
+		ResubmitThreshold: ABUSE_RESUBMIT_THRESHOLD,
+		AutoThrottle:      ABUSE_AUTO_THROTTLE,
+		ThrottleInterval:  ABUSE_THROTTLE_INTERVAL,
+	})
+	abuse.StartCleanupRoutine(5 * time.Minute)
 
 	cfg := &Config{
 		Env: Environment{
-			Debug:         DEBUG,
-			HaproxyURL:    HAPROXY_URL,
-			AdminHotkey:   ADMIN_HOTKEY,
-			AdminKeyValue: ADMIN_KEY_VALUE,
+			Debug:             DEBUG,
+			HaproxyURL:        HAPROXY_URL,
+			AdminHotkey:       ADMIN_HOTKEY,
+			AdminKeyValue:     ADMIN_KEY_VALUE,
+			ConsensusBackends: CONSENSUS_BACKENDS,
+			ConsensusMode:     CONSENSUS_MODE,
+			ConsensusModels:   CONSENSUS_MODELS,
+			DedupWindow:       DEDUP_WINDOW,
+			AsyncWorkers:      ASYNC_WORKERS,
+			AsyncJobLease:     ASYNC_JOB_LEASE,
+			RequireChallenge:  REQUIRE_HOTKEY_CHALLENGE,
+			BackendRetries:    BACKEND_RETRIES,
+			BackendRetryBase:  BACKEND_RETRY_BASE,
+			BackendRetryMax:   BACKEND_RETRY_MAX,
+			BackendPrewarm:    BACKEND_PREWARM_CONNS,
+			BackendDNSRefresh: BACKEND_DNS_REFRESH,
+			BackendHealthTick: BACKEND_HEALTH_INTERVAL,
+			MetagraphInterval: METAGRAPH_SYNC_INTERVAL,
+			RateLimitRPS:      RATE_LIMIT_RPS,
+			RateLimitBurst:    RATE_LIMIT_BURST,
+			EpochQuota:        EPOCH_QUOTA,
+			KeyRotationGrace:  KEY_ROTATION_GRACE,
+			AdminSessionTTL:   ADMIN_SESSION_TTL,
+			LegacySunset:      LEGACY_SUNSET,
+			ShutdownTimeout:   SHUTDOWN_TIMEOUT,
+			Server:            SERVER,
+			SchemaCheck:       SCHEMA_CHECK,
+			Migrate:           DB_MIGRATE,
+			EpistulaAuth:      EPISTULA_AUTH,
+			EpistulaMaxSkew:   EPISTULA_MAX_SKEW,
+			EpistulaReceiver:  EPISTULA_RECEIVER,
+			WarmupKeys:        WARMUP_KEYS,
+			WarmupRequest:     WARMUP_REQUEST,
+			CanaryRequest:     CANARY_REQUEST,
+			CanaryInterval:    CANARY_INTERVAL,
+			CanaryMaxLatency:  CANARY_MAX_LATENCY,
+			TracingEndpoint:   TRACING_ENDPOINT,
+			ShadowBackendURL:  SHADOW_BACKEND_URL,
+			ShadowPercent:     SHADOW_PERCENT,
+			Quarantine:        QUARANTINE,
+			Sanitize:          SANITIZE,
+			ValidatePayloads:  VALIDATE_PAYLOADS,
+			ValidateMaxChunks: VALIDATE_MAX_CHUNKS,
+			CaptureRedact:     CAPTURE_REDACT_FIELDS,
+			CaptureMaxBytes:   CAPTURE_MAX_BYTES,
+			DailyQuota:        DAILY_QUOTA,
+			CacheKeyMode:      CACHE_KEY_MODE,
+			IDs:               IDS,
+			Outbound:          OUTBOUND,
+			AnalyticsMirror:   ANALYTICS_MIRROR,
+			Mock:              MOCK,
+			Soak:              SOAK,
+			Messages:          ERROR_MESSAGES,
+			AccessLog:         ACCESS_LOG,
+			KeyPrune:          KEY_PRUNE,
+			LogRetention:      LOG_RETENTION,
+			ExpectTTL:         EXPECT_TTL,
+			BackendAuth:       BACKEND_AUTH,
+			ShutdownReportURL: SHUTDOWN_REPORT_URL,
+			BackendGzip:       BACKEND_GZIP,
+			CompleteAbandoned: BACKEND_COMPLETE_ABANDONED,
+			ResultCache:       RESULT_CACHE,
+			TracingSample:     TRACING_SAMPLE,
+			ErrorSinkDSN:      ERROR_SINK_DSN,
+			ErrorSinkURL:      ERROR_SINK_URL,
+			ErrorSinkEnv:      ERROR_SINK_ENV,
+			Priority:          PRIORITY,
+
+			BackendDefaultTimeout: BACKEND_TIMEOUT,
+			BackendModelTimeouts:  BACKEND_MODEL_TIMEOUTS,
 		},
+		Keys:      NewKeyCache(NewSQLKeyStore(sqlClient), KEY_CACHE_TTL, KEY_CACHE_STALE_TTL),
+		Database:  NewDatabaseMonitor(sqlClient, DEGRADED_MODE),
+		Nonces:    NewNonceCache(redisCache),
+		Transport: NewBackendTransport(OUTBOUND, BACKEND_AUTH, BACKEND_POOL),
+		Balancer:  NewBalancer(BACKEND_BALANCE, BACKEND_EJECT_FAILURES),
+		Workers:   NewBackendPool(BACKEND_WORKERS, BACKEND_MODEL_WORKERS, BACKEND_QUEUE_SIZE, BACKEND_QUEUE_TIMEOUT, PRIORITY.Aging),
+		Versions:  NewVersionTracker(MIN_BACKEND_VERSION, BACKEND_VERSION_ENFORCE),
+		Inflight:  &singleflight.Group{},
+		Limiter:   NewRateLimiter(),
+		Backoff:   NewBackoff(BACKOFF_BASE, BACKOFF_MAX),
+		Traffic:   NewTraffic(),
+		Timeouts:  NewAdaptiveTimeouts(ADAPTIVE_TIMEOUTS),
+		Weights:   weights,
+		Chain:     metagraph.NewChain(SUBTENSOR_URL, METAGRAPH_NETUID, SUBNET_TEMPO),
+		Epochs:    NewEpochUsage(),
 		SqlClient: sqlClient,
 		Cache:     cache,
+		Abuse:     abuse,
+		JobQueue:  make(chan string, ASYNC_QUEUE_SIZE),
+		Mirror:    make(chan []byte, ANALYTICS_MIRROR.QueueSize),
+		Breaker:   NewCircuitBreaker(BREAKER_THRESHOLD, BREAKER_COOLDOWN),
+		Routes:    NewRouteTable(),
+		Payloads:  NewPayloadLibrary(),
+		Legacy:    NewLegacyTracker(sqlClient),
+		Captures:  NewCaptureRegistry(),
+		Usage:     NewUsageTracker(sqlClient),
+		Decisions: NewDecisionLog(sqlClient),
+		Metagraph: metagraph.NewStore(
+			METAGRAPH_URL, METAGRAPH_NETUID, METAGRAPH_MIN_STAKE, METAGRAPH_REQUIRE_PERMIT,
+		),
+	}
+
+	// Backend calls take their timeout from the request context, so one client serves them all
+	cfg.Client = &http.Client{Transport: cfg.Transport}
+	outbound := OUTBOUND.Transport()
+	cfg.Chain.Transport = outbound
+	cfg.Metagraph.Transport = outbound
+
+	cfg.SetRuntime(&Runtime{
+		Policies: policies,
+		Access:   accessRules,
+		Retention: RetentionPolicy{
+			Default: PAYLOAD_RETENTION,
+			Models:  retentionModels,
+			Tiers:   retentionTiers,
+		},
+		Quarantine:    QUARANTINE,
+		Sanitize:      SANITIZE,
+		EpochQuota:    EPOCH_QUOTA,
+		DailyQuota:    DAILY_QUOTA,
+		DedupWindow:   DEDUP_WINDOW,
+		ShadowPercent: SHADOW_PERCENT,
+	})
+
+	cfg.Keys.StartFlushRoutine(KEY_USAGE_FLUSH_INTERVAL)
+	cfg.Usage.StartFlushRoutine(KEY_USAGE_FLUSH_INTERVAL)
+	cfg.Decisions.StartFlushRoutine(KEY_USAGE_FLUSH_INTERVAL)
+	cfg.Database.StartCheckRoutine(5 * time.Second)
+	cfg.Nonces.StartCleanupRoutine(time.Minute)
+	cfg.Limiter.StartCleanupRoutine(10 * time.Minute)
+	cfg.Backoff.StartCleanupRoutine(10 * time.Minute)
+	cfg.Expected = NewExpectations(cfg.Workers, EXPECT_MAX_PER_KEY)
+	cfg.Expected.StartCleanupRoutine(time.Minute)
+	cfg.Chain.StartPollRoutine(metagraph.BlockTime)
+
+	if err := cfg.Routes.Reload(sqlClient); err != nil {
+		fmt.Printf("Warning: Failed to load model routes: %v\n", err)
+	}
+	cfg.Routes.StartRefreshRoutine(sqlClient, 30*time.Second)
+
+	if err := cfg.Payloads.Reload(sqlClient); err != nil {
+		fmt.Printf("Warning: Failed to load synthetic payloads: %v\n", err)
+	}
+	cfg.Payloads.StartRefreshRoutine(sqlClient, time.Minute)
+
+	if err := loadFlaggedKeys(cfg); err != nil {
+		fmt.Printf("Warning: Failed to load flagged keys: %v\n", err)
 	}
 
+	apikey.Configure(IDS.Key)
+
 	if ADMIN_KEY_VALUE != "" {
+		if len(ADMIN_KEY_VALUE) < IDS.MinKeyLength {
+			fmt.Printf("Warning: ADMIN_API_KEY is shorter than API_KEY_MIN_LENGTH and will be refused\n")
+		}
 		if err := ensureAdminKey(cfg); err != nil {
 			fmt.Printf("Warning: Failed to setup admin key: %v\n", err)
 		}
@@ -174,8 +924,8 @@ func ensureAdminKey(cfg *Config) error {
 
 	if count == 0 {
 		_, err = cfg.SqlClient.Exec(
-			"INSERT INTO api_keys (hotkey, key_value, is_admin, created_at) VALUES (?, ?, TRUE, ?)",
-			cfg.Env.AdminHotkey, cfg.Env.AdminKeyValue, time.Now(),
+			"INSERT INTO api_keys (hotkey, key_hash, key_hint, is_admin, scopes, created_at) VALUES (?, ?, ?, TRUE, ?, ?)",
+			cfg.Env.AdminHotkey, apikey.Hash(cfg.Env.AdminKeyValue), apikey.Hint(cfg.Env.AdminKeyValue), ScopeAll, time.Now(),
 		)
 		if err != nil {
 			return fmt.Errorf("failed to create admin key: %w", err)
@@ -183,8 +933,8 @@ func ensureAdminKey(cfg *Config) error {
 		fmt.Printf("Created admin API key with hotkey '%s'\n", cfg.Env.AdminHotkey)
 	} else {
 		_, err = cfg.SqlClient.Exec(
-			"UPDATE api_keys SET key_value = ? WHERE hotkey = ?",
-			cfg.Env.AdminKeyValue, cfg.Env.AdminHotkey,
+			"UPDATE api_keys SET key_hash = ?, key_hint = ?, is_admin = TRUE, scopes = ? WHERE hotkey = ?",
+			apikey.Hash(cfg.Env.AdminKeyValue), apikey.Hint(cfg.Env.AdminKeyValue), ScopeAll, cfg.Env.AdminHotkey,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to update admin key: %w", err)
@@ -194,3 +944,22 @@ func ensureAdminKey(cfg *Config) error {
 
 	return nil
 }
+
+// loadFlaggedKeys restores unresolved key flags so throttling survives restarts
+func loadFlaggedKeys(cfg *Config) error {
+	rows, err := cfg.SqlClient.Query("SELECT DISTINCT hotkey FROM key_flags WHERE resolved_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to query key flags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hotkey string
+		if err := rows.Scan(&hotkey); err != nil {
+			return fmt.Errorf("failed to scan key flag: %w", err)
+		}
+		cfg.Abuse.Flag(hotkey)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Dialect is the SQL database the proxy stores its state in
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// driverNames maps dialects to their database/sql driver
+var driverNames = map[Dialect]string{
+	DialectMySQL:    "mysql",
+	DialectPostgres: "pgx",
+	DialectSQLite:   "sqlite",
+}
+
+// DB is a database handle that accepts MySQL-style `?` placeholders for every
+// dialect, so queries are written once and rewritten for the driver in use
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// OpenDB opens a database of the given dialect
+func OpenDB(dialect Dialect, dsn string) (*DB, error) {
+	driver, ok := driverNames[dialect]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver %q", dialect)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{DB: db, Dialect: dialect}, nil
+}
+
+// Rebind rewrites `?` placeholders into the dialect's bind syntax
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == '?' && !quoted:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Upsert returns the clause that turns an INSERT into an update of the row
+// with the same keys. Columns in set take the inserted value and columns in
+// add are incremented by it.
+func (d Dialect) Upsert(table string, keys, set, add []string) string {
+	var assignments []string
+	if d == DialectMySQL {
+		for _, c := range set {
+			assignments = append(assignments, c+" = VALUES("+c+")")
+		}
+		for _, c := range add {
+			assignments = append(assignments, c+" = "+c+" + VALUES("+c+")")
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	}
+
+	for _, c := range set {
+		assignments = append(assignments, c+" = excluded."+c)
+	}
+	for _, c := range add {
+		assignments = append(assignments, c+" = "+table+"."+c+" + excluded."+c)
+	}
+	return "ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(assignments, ", ")
+}
+
+// columnsQuery returns a query listing (table, column) pairs of the current schema
+func (d Dialect) columnsQuery() string {
+	switch d {
+	case DialectPostgres:
+		return "SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema()"
+	case DialectSQLite:
+		return "SELECT m.name, p.name FROM sqlite_master m JOIN pragma_table_info(m.name) p WHERE m.type = 'table'"
+	default:
+		return "SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = DATABASE()"
+	}
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Conn
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// liveColumns returns the columns of every table in the current schema, keyed
+// by lower-cased table and column name
+func (d Dialect) liveColumns(ctx context.Context, q queryer) (map[string]map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, d.columnsQuery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read live schema: %w", err)
+	}
+	defer rows.Close()
+
+	live := make(map[string]map[string]bool)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("failed to read live schema: %w", err)
+		}
+		table, column = strings.ToLower(table), strings.ToLower(column)
+		if live[table] == nil {
+			live[table] = make(map[string]bool)
+		}
+		live[table][column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read live schema: %w", err)
+	}
+	return live, nil
+}
+
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.DB.QueryRowContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// Begin starts a transaction whose statements are rebound like the DB's
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.Dialect}, nil
+}
+
+// InsertID runs an INSERT and returns the generated id of the new row.
+// Postgres has no LastInsertId, so the id is read back with RETURNING.
+func (db *DB) InsertID(query string, args ...any) (int64, error) {
+	if db.Dialect == DialectPostgres {
+		var id int64
+		err := db.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Tx is a transaction that accepts `?` placeholders for every dialect
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+func (tx *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(tx.dialect.Rebind(query), args...)
+}
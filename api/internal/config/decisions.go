@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxPendingDecisions bounds the decisions held while the database is
+// unreachable; older ones are dropped first
+const maxPendingDecisions = 10000
+
+// PolicyRecord is a policy evaluation waiting to be written to policy_decisions
+type PolicyRecord struct {
+	RequestID       string
+	Model           string
+	Score           float64
+	Decision        PolicyDecision
+	BackendVerified bool
+	At              time.Time
+}
+
+// DecisionLog buffers policy decisions in memory and writes them to the
+// policy_decisions table in batches, keeping the database out of the request path
+type DecisionLog struct {
+	db      *DB
+	pending []PolicyRecord
+	mutex   sync.Mutex
+}
+
+func NewDecisionLog(db *DB) *DecisionLog {
+	return &DecisionLog{db: db}
+}
+
+// Record queues a decision for the next flush
+func (d *DecisionLog) Record(r PolicyRecord) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.pending = append(d.pending, r)
+	if over := len(d.pending) - maxPendingDecisions; over > 0 {
+		d.pending = d.pending[over:]
+	}
+}
+
+// Flush writes the queued decisions in a single transaction. Decisions that
+// fail to write are kept for the next flush.
+func (d *DecisionLog) Flush() error {
+	d.mutex.Lock()
+	pending := d.pending
+	d.pending = nil
+	d.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := d.write(pending); err != nil {
+		d.mutex.Lock()
+		d.pending = append(pending, d.pending...)
+		if over := len(d.pending) - maxPendingDecisions; over > 0 {
+			d.pending = d.pending[over:]
+		}
+		d.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (d *DecisionLog) write(pending []PolicyRecord) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin policy decision flush: %w", err)
+	}
+	for _, r := range pending {
+		_, err := tx.Exec(
+			"INSERT INTO policy_decisions (request_id, model, score, threshold, in_grace, backend_verified, verified, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			r.RequestID, r.Model, r.Score, r.Decision.Threshold, r.Decision.InGrace, r.BackendVerified, r.Decision.Verified, r.At,
+		)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record policy decision: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit policy decision flush: %w", err)
+	}
+	return nil
+}
+
+// StartFlushRoutine periodically writes queued policy decisions
+func (d *DecisionLog) StartFlushRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := d.Flush(); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecisionLogFlush(t *testing.T) {
+	db := newTestDB(t)
+	decisions := NewDecisionLog(db)
+
+	decisions.Record(PolicyRecord{
+		RequestID:       "r1",
+		Model:           "m",
+		Score:           0.4,
+		Decision:        PolicyDecision{Verified: false, Threshold: 0.5, Overrode: true},
+		BackendVerified: true,
+		At:              time.Now(),
+	})
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM policy_decisions").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("decision written before flush")
+	}
+
+	if err := decisions.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	var verified, backendVerified bool
+	var threshold float64
+	err := db.QueryRow("SELECT threshold, backend_verified, verified FROM policy_decisions WHERE request_id = ?", "r1").Scan(&threshold, &backendVerified, &verified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if threshold != 0.5 || !backendVerified || verified {
+		t.Errorf("stored threshold %v, backend_verified %v, verified %v", threshold, backendVerified, verified)
+	}
+}
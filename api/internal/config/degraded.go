@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"api/internal/metrics"
+)
+
+// DatabaseMonitor tracks whether MySQL is reachable so requests can be served
+// in degraded mode from in-memory state while it is down
+type DatabaseMonitor struct {
+	Enabled bool
+
+	db   *DB
+	down atomic.Bool
+}
+
+func NewDatabaseMonitor(db *DB, enabled bool) *DatabaseMonitor {
+	return &DatabaseMonitor{Enabled: enabled, db: db}
+}
+
+// Down reports whether the last database check failed
+func (m *DatabaseMonitor) Down() bool {
+	return m.down.Load()
+}
+
+// Degraded reports whether requests are currently served in degraded mode
+func (m *DatabaseMonitor) Degraded() bool {
+	return m.Enabled && m.Down()
+}
+
+// Check pings the database and records the result
+func (m *DatabaseMonitor) Check() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := m.db.PingContext(ctx)
+	wasDown := m.down.Swap(err != nil)
+
+	switch {
+	case err != nil && !wasDown:
+		fmt.Printf("Warning: Database unreachable, entering degraded mode: %v\n", err)
+	case err == nil && wasDown:
+		fmt.Printf("Database reachable again, leaving degraded mode\n")
+	}
+
+	if err != nil {
+		metrics.DatabaseDown.Set(1)
+	} else {
+		metrics.DatabaseDown.Set(0)
+	}
+}
+
+// StartCheckRoutine periodically pings the database
+func (m *DatabaseMonitor) StartCheckRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			m.Check()
+		}
+	}()
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"sync"
+)
+
+type epochCount struct {
+	epoch int64
+	count int
+}
+
+// EpochUsage counts requests per hotkey within the current subnet epoch
+type EpochUsage struct {
+	counts map[string]*epochCount
+	mutex  sync.Mutex
+}
+
+func NewEpochUsage() *EpochUsage {
+	return &EpochUsage{
+		counts: make(map[string]*epochCount),
+	}
+}
+
+// Take counts a request against the hotkey's quota for the epoch and reports
+// whether it fits, along with the number of requests used so far
+func (u *EpochUsage) Take(hotkey string, epoch int64, quota int) (bool, int) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	c, ok := u.counts[hotkey]
+	if !ok || c.epoch != epoch {
+		c = &epochCount{epoch: epoch}
+		u.counts[hotkey] = c
+	}
+
+	if c.count >= quota {
+		return false, c.count
+	}
+
+	c.count++
+	return true, c.count
+}
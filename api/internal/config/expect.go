@@ -0,0 +1,119 @@
+package config
+
+import (
+	"sync"
+	"time"
+
+	"api/internal/metrics"
+)
+
+// Expectation is a verification a validator announced ahead of sending it
+type Expectation struct {
+	Model     string
+	Deadline  time.Time
+	ExpiresAt time.Time
+}
+
+type expectationKey struct {
+	hotkey    string
+	requestID string
+}
+
+// Expectations tracks the request IDs validators announced through
+// /verify/expect. Each holds a place in the backend wait queue until the
+// request arrives or the expectation expires. Expectations are local to the
+// replica they were announced to.
+type Expectations struct {
+	slots     *BackendPool
+	maxPerKey int
+	entries   map[expectationKey]Expectation
+	perKey    map[string]int
+	mutex     sync.Mutex
+}
+
+func NewExpectations(slots *BackendPool, maxPerKey int) *Expectations {
+	return &Expectations{
+		slots:     slots,
+		maxPerKey: maxPerKey,
+		entries:   make(map[expectationKey]Expectation),
+		perKey:    make(map[string]int),
+	}
+}
+
+// Add registers the request IDs of a hotkey, replacing earlier expectations of
+// the same IDs. It reports false, registering nothing, when the hotkey would
+// exceed its limit or the wait queue cannot hold the new places.
+func (e *Expectations) Add(hotkey string, requestIDs []string, expectation Expectation) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	added := 0
+	for _, id := range requestIDs {
+		if _, ok := e.entries[expectationKey{hotkey, id}]; !ok {
+			added++
+		}
+	}
+	if e.perKey[hotkey]+added > e.maxPerKey || !e.slots.Reserve(added) {
+		return false
+	}
+
+	for _, id := range requestIDs {
+		e.entries[expectationKey{hotkey, id}] = expectation
+	}
+	e.perKey[hotkey] += added
+	metrics.ExpectedRequests.WithLabelValues("registered").Add(float64(added))
+	return true
+}
+
+// Claim matches an arriving request to its expectation, freeing the place it
+// held in the wait queue for the request to use
+func (e *Expectations) Claim(hotkey, requestID string) (Expectation, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	key := expectationKey{hotkey, requestID}
+	expectation, ok := e.entries[key]
+	if !ok {
+		return Expectation{}, false
+	}
+	e.remove(key)
+
+	if time.Now().After(expectation.ExpiresAt) {
+		metrics.ExpectedRequests.WithLabelValues("expired").Inc()
+		return Expectation{}, false
+	}
+	metrics.ExpectedRequests.WithLabelValues("claimed").Inc()
+	return expectation, true
+}
+
+// remove drops an expectation and its queue place. The caller holds the mutex.
+func (e *Expectations) remove(key expectationKey) {
+	delete(e.entries, key)
+	e.slots.Unreserve(1)
+	if e.perKey[key.hotkey]--; e.perKey[key.hotkey] <= 0 {
+		delete(e.perKey, key.hotkey)
+	}
+}
+
+// Cleanup drops expectations whose request never arrived
+func (e *Expectations) Cleanup() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := time.Now()
+	for key, expectation := range e.entries {
+		if now.After(expectation.ExpiresAt) {
+			e.remove(key)
+			metrics.ExpectedRequests.WithLabelValues("expired").Inc()
+		}
+	}
+}
+
+func (e *Expectations) StartCleanupRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			e.Cleanup()
+		}
+	}()
+}
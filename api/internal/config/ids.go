@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"api/internal/apikey"
+)
+
+// minIDEntropy is the floor for request and job IDs, which only need to be
+// collision-free rather than unguessable
+const minIDEntropy = 64
+
+// IDSettings holds how API keys and request IDs are generated. Keys shorter
+// than MinKeyLength are refused, which lets a deployment move to longer keys
+// by raising KeyScheme.Length, rotating, and then raising MinKeyLength.
+type IDSettings struct {
+	Key          apikey.Scheme
+	MinEntropy   float64
+	MinKeyLength int
+	RequestID    apikey.Scheme
+}
+
+// parseIDSettings reads the key and request ID schemes from the environment
+func parseIDSettings() (IDSettings, []error) {
+	var errs []error
+	settings := IDSettings{
+		Key:       apikey.DefaultScheme,
+		RequestID: apikey.DefaultIDScheme,
+	}
+
+	settings.Key.Alphabet = getEnv("API_KEY_ALPHABET", apikey.DefaultScheme.Alphabet)
+	keyLength, err := strconv.Atoi(getEnv("API_KEY_LENGTH", strconv.Itoa(apikey.DefaultScheme.Length)))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid API_KEY_LENGTH: must be an integer"))
+	}
+	settings.Key.Length = keyLength
+
+	minEntropy, err := strconv.ParseFloat(getEnv("API_KEY_MIN_ENTROPY", "128"), 64)
+	if err != nil || minEntropy < 0 {
+		errs = append(errs, fmt.Errorf("invalid API_KEY_MIN_ENTROPY: must be a non-negative number of bits"))
+	}
+	settings.MinEntropy = minEntropy
+
+	if err := settings.Key.Validate(minEntropy); err != nil {
+		errs = append(errs, fmt.Errorf("invalid API key scheme: %w", err))
+	}
+
+	minKeyLength, err := strconv.Atoi(getEnv("API_KEY_MIN_LENGTH", "0"))
+	if err != nil || minKeyLength < 0 {
+		errs = append(errs, fmt.Errorf("invalid API_KEY_MIN_LENGTH: must be a non-negative integer"))
+	} else if minKeyLength > settings.Key.Length {
+		errs = append(errs, fmt.Errorf("invalid API_KEY_MIN_LENGTH: must not exceed API_KEY_LENGTH"))
+	}
+	settings.MinKeyLength = minKeyLength
+
+	settings.RequestID.Alphabet = getEnv("REQUEST_ID_ALPHABET", apikey.DefaultIDScheme.Alphabet)
+	idLength, err := strconv.Atoi(getEnv("REQUEST_ID_LENGTH", strconv.Itoa(apikey.DefaultIDScheme.Length)))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid REQUEST_ID_LENGTH: must be an integer"))
+	}
+	settings.RequestID.Length = idLength
+
+	if err := settings.RequestID.Validate(minIDEntropy); err != nil {
+		errs = append(errs, fmt.Errorf("invalid request ID scheme: %w", err))
+	}
+
+	return settings, errs
+}
+
+// NewID returns a new request or job ID
+func (c *Config) NewID() string {
+	id, _ := c.Env.IDs.RequestID.Generate()
+	return id
+}
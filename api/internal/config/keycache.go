@@ -0,0 +1,288 @@
+package config
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"api/internal/apikey"
+)
+
+// KeyInfo is the subset of an api_keys row needed to authenticate a request
+type KeyInfo struct {
+	Hotkey    string
+	Tier      string
+	Scopes    []string
+	IsAdmin   bool
+	Active    bool
+	Disabled  bool
+	ExpiresAt *time.Time
+
+	// Per-key rate limit overrides, nil when the global limit applies
+	RateLimitRPS   *float64
+	RateLimitBurst *int
+
+	// Per-key daily verification quota, nil when the global quota applies
+	DailyQuota *int
+
+	// Client networks the key may be used from, empty when any address is allowed
+	AllowedCIDRs []*net.IPNet
+}
+
+// setScopes fills the scopes from the stored column; IsAdmin is kept for the
+// checks that only care whether the key holds every scope
+func (k *KeyInfo) setScopes(value string) {
+	k.Scopes = ParseScopes(value)
+	k.IsAdmin = k.HasScope(ScopeAll)
+}
+
+// setAllowedCIDRs fills the allowlist from the stored column; entries are
+// validated when written, so any that no longer parse are skipped
+func (k *KeyInfo) setAllowedCIDRs(value sql.NullString) {
+	k.AllowedCIDRs = nil
+	for _, entry := range splitList(value.String) {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			k.AllowedCIDRs = append(k.AllowedCIDRs, network)
+		}
+	}
+}
+
+// AllowsIP reports whether the key may be used from the given client address
+func (k KeyInfo) AllowsIP(ip net.IP) bool {
+	if len(k.AllowedCIDRs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, network := range k.AllowedCIDRs {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether the key has passed its expiry time
+func (k KeyInfo) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+type keyCacheEntry struct {
+	info      KeyInfo
+	expiresAt time.Time
+}
+
+// Unknown key hashes are remembered briefly and in bounded number, so callers
+// guessing keys cannot grow the cache
+const (
+	keyMissTTL = 5 * time.Second
+	maxKeyMiss = 10000
+)
+
+// KeyCache keeps API key lookups and last-used updates out of the request path
+type KeyCache struct {
+	store    KeyStore
+	ttl      time.Duration
+	staleTTL time.Duration
+	entries  map[string]keyCacheEntry
+	misses   map[string]time.Time
+	lastUsed map[string]time.Time
+	mutex    sync.Mutex
+}
+
+func NewKeyCache(store KeyStore, ttl, staleTTL time.Duration) *KeyCache {
+	return &KeyCache{
+		store:    store,
+		ttl:      ttl,
+		staleTTL: staleTTL,
+		entries:  make(map[string]keyCacheEntry),
+		misses:   make(map[string]time.Time),
+		lastUsed: make(map[string]time.Time),
+	}
+}
+
+// Lookup returns the key's info, reading through to the store when the
+// cached entry is missing or stale. Unknown keys return sql.ErrNoRows. While
+// the database is unreachable, an expired entry is still served for staleTTL.
+func (k *KeyCache) Lookup(keyValue string) (KeyInfo, error) {
+	keyHash := apikey.Hash(keyValue)
+	return k.cached(keyHash, func() (KeyInfo, error) {
+		return k.store.KeyByHash(keyHash, time.Now())
+	})
+}
+
+// LookupSigner returns the key of a hotkey that signed a request, cached like Lookup
+func (k *KeyCache) LookupSigner(hotkey string) (KeyInfo, error) {
+	return k.cached("hotkey:"+hotkey, func() (KeyInfo, error) {
+		return k.store.KeyByHotkey(hotkey)
+	})
+}
+
+// cached serves the entry under cacheKey, calling load when it is missing or stale
+func (k *KeyCache) cached(cacheKey string, load func() (KeyInfo, error)) (KeyInfo, error) {
+	k.mutex.Lock()
+	entry, ok := k.entries[cacheKey]
+	missUntil, missed := k.misses[cacheKey]
+	k.mutex.Unlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.info, nil
+	}
+	if missed && time.Now().Before(missUntil) {
+		return KeyInfo{}, sql.ErrNoRows
+	}
+
+	info, err := load()
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		if ok && time.Now().Before(entry.expiresAt.Add(k.staleTTL)) {
+			return entry.info, nil
+		}
+		return KeyInfo{}, err
+	}
+
+	if err != nil {
+		if k.ttl > 0 {
+			k.mutex.Lock()
+			if len(k.misses) < maxKeyMiss {
+				k.misses[cacheKey] = time.Now().Add(min(k.ttl, keyMissTTL))
+			}
+			delete(k.entries, cacheKey)
+			k.mutex.Unlock()
+		}
+		return KeyInfo{}, sql.ErrNoRows
+	}
+
+	if k.ttl > 0 {
+		k.mutex.Lock()
+		k.entries[cacheKey] = keyCacheEntry{info: info, expiresAt: time.Now().Add(k.ttl)}
+		delete(k.misses, cacheKey)
+		k.mutex.Unlock()
+	}
+	return info, nil
+}
+
+// LookupHotkey reads a key's info by hotkey, bypassing the cache
+func (k *KeyCache) LookupHotkey(hotkey string) (KeyInfo, error) {
+	return k.store.KeyByHotkey(hotkey)
+}
+
+// InvalidateHotkey drops cached entries for a hotkey after it is changed
+func (k *KeyCache) InvalidateHotkey(hotkey string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	for keyHash, entry := range k.entries {
+		if entry.info.Hotkey == hotkey {
+			delete(k.entries, keyHash)
+		}
+	}
+	clear(k.misses)
+}
+
+// Touch records that a hotkey was used; the update is written by Flush
+func (k *KeyCache) Touch(hotkey string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.lastUsed[hotkey] = time.Now()
+}
+
+// Flush writes pending last-used timestamps in a single transaction
+func (k *KeyCache) Flush() error {
+	k.mutex.Lock()
+	pending := k.lastUsed
+	k.lastUsed = make(map[string]time.Time)
+	k.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := k.store.TouchKeys(pending); err != nil {
+		k.restore(pending)
+		return err
+	}
+
+	return nil
+}
+
+// Pending returns the number of last_used_at updates waiting to be flushed
+func (k *KeyCache) Pending() int {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	return len(k.lastUsed)
+}
+
+// restore re-queues timestamps from a failed flush unless newer ones arrived
+func (k *KeyCache) restore(pending map[string]time.Time) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	for hotkey, at := range pending {
+		if existing, ok := k.lastUsed[hotkey]; !ok || existing.Before(at) {
+			k.lastUsed[hotkey] = at
+		}
+	}
+}
+
+// Cleanup drops lookups that are past serving even while degraded
+func (k *KeyCache) Cleanup() {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := time.Now()
+	for keyHash, entry := range k.entries {
+		if now.After(entry.expiresAt.Add(k.staleTTL)) {
+			delete(k.entries, keyHash)
+		}
+	}
+	for keyHash, until := range k.misses {
+		if now.After(until) {
+			delete(k.misses, keyHash)
+		}
+	}
+}
+
+// StartFlushRoutine periodically writes last-used timestamps and drops expired lookups
+func (k *KeyCache) StartFlushRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := k.Flush(); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+			k.Cleanup()
+		}
+	}()
+}
+
+// Prime loads the most recently used active keys into the cache, returning how many were loaded
+func (k *KeyCache) Prime(limit int) (int, error) {
+	if k.ttl <= 0 || limit <= 0 {
+		return 0, nil
+	}
+
+	keys, err := k.store.RecentKeys(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	entries := make(map[string]keyCacheEntry, len(keys))
+	expiresAt := time.Now().Add(k.ttl)
+	for keyHash, info := range keys {
+		entries[keyHash] = keyCacheEntry{info: info, expiresAt: expiresAt}
+	}
+
+	k.mutex.Lock()
+	for keyHash, entry := range entries {
+		k.entries[keyHash] = entry
+	}
+	k.mutex.Unlock()
+
+	return len(entries), nil
+}
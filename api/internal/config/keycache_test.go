@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestDB returns a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := OpenDB(DialectSQLite, filepath.Join(t.TempDir(), "proxy.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestKeyCacheBoundsUnknownKeys(t *testing.T) {
+	keys := NewKeyCache(NewSQLKeyStore(newTestDB(t)), time.Minute, time.Hour)
+	for i := 0; i < maxKeyMiss+100; i++ {
+		if _, err := keys.Lookup(fmt.Sprintf("unknown_%d", i)); err == nil {
+			t.Fatal("unknown key was found")
+		}
+	}
+	if len(keys.misses) != maxKeyMiss {
+		t.Fatalf("cached %d unknown keys, want %d", len(keys.misses), maxKeyMiss)
+	}
+	for _, until := range keys.misses {
+		if time.Until(until) > keyMissTTL {
+			t.Fatalf("unknown key cached until %s, past the %s miss TTL", until, keyMissTTL)
+		}
+	}
+}
+
+func TestKeyCacheCachesSigners(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := db.Exec("INSERT INTO api_keys (hotkey, key_hash, key_hint, scopes) VALUES (?, ?, ?, ?)", "validator", "hash", "hint", ScopeVerify); err != nil {
+		t.Fatal(err)
+	}
+	keys := NewKeyCache(NewSQLKeyStore(db), time.Minute, time.Hour)
+
+	if _, err := keys.LookupSigner("validator"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE api_keys SET disabled = TRUE WHERE hotkey = ?", "validator"); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := keys.LookupSigner("validator"); err != nil || key.Disabled {
+		t.Fatalf("signer lookup was not served from the cache: %+v, %v", key, err)
+	}
+
+	keys.InvalidateHotkey("validator")
+	if key, err := keys.LookupSigner("validator"); err != nil || !key.Disabled {
+		t.Fatalf("signer lookup after invalidation = %+v, %v, want the disabled key", key, err)
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+)
+
+// migratePlaintextKeys replaces the plaintext key columns of a pre-hashing
+// api_keys table with SHA-256 digests. It is a no-op once key_value is gone.
+func migratePlaintextKeys(db *DB) error {
+	// Plaintext keys predate support for other databases
+	if db.Dialect != DialectMySQL {
+		return nil
+	}
+
+	columns := make(map[string]bool)
+	rows, err := db.Query("SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'api_keys'")
+	if err != nil {
+		return fmt.Errorf("failed to inspect api_keys: %w", err)
+	}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to inspect api_keys: %w", err)
+		}
+		columns[column] = true
+	}
+	rows.Close()
+
+	if !columns["key_value"] {
+		return nil
+	}
+
+	for _, column := range []struct{ name, definition string }{
+		{"key_hash", "CHAR(64) NULL"},
+		{"key_hint", "VARCHAR(8) NULL"},
+		{"previous_key_hash", "CHAR(64) NULL"},
+	} {
+		if columns[column.name] {
+			continue
+		}
+		if _, err := db.Exec("ALTER TABLE api_keys ADD COLUMN " + column.name + " " + column.definition); err != nil {
+			return fmt.Errorf("failed to add %s: %w", column.name, err)
+		}
+	}
+
+	previous := "NULL"
+	if columns["previous_key_value"] {
+		previous = "SHA2(previous_key_value, 256)"
+	}
+	result, err := db.Exec(
+		"UPDATE api_keys SET key_hash = SHA2(key_value, 256), key_hint = RIGHT(key_value, 4), previous_key_hash = " + previous + " WHERE key_hash IS NULL",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to hash API keys: %w", err)
+	}
+
+	alter := "ALTER TABLE api_keys MODIFY key_hash CHAR(64) NOT NULL, ADD UNIQUE (key_hash), ADD UNIQUE (previous_key_hash), DROP COLUMN key_value"
+	if columns["previous_key_value"] {
+		alter += ", DROP COLUMN previous_key_value"
+	}
+	if _, err := db.Exec(alter); err != nil {
+		return fmt.Errorf("failed to drop plaintext key columns: %w", err)
+	}
+
+	migrated, _ := result.RowsAffected()
+	fmt.Printf("Migrated %d plaintext API keys to hashes\n", migrated)
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// KeyStore is the storage the key cache reads API keys from and writes
+// last-used times to
+type KeyStore interface {
+	// KeyByHash returns the key whose current hash, or previous hash still
+	// within its rotation grace period, is keyHash. Unknown keys return sql.ErrNoRows.
+	KeyByHash(keyHash string, now time.Time) (KeyInfo, error)
+	// KeyByHotkey returns the key of a hotkey. Unknown hotkeys return sql.ErrNoRows.
+	KeyByHotkey(hotkey string) (KeyInfo, error)
+	// RecentKeys returns up to limit usable keys by hash, most recently used first
+	RecentKeys(limit int) (map[string]KeyInfo, error)
+	// TouchKeys records when each hotkey was last used
+	TouchKeys(lastUsed map[string]time.Time) error
+}
+
+const keyColumns = "hotkey, tier, scopes, active, disabled, expires_at, rate_limit_rps, rate_limit_burst, daily_quota, allowed_cidrs"
+
+// sqlKeyStore is the KeyStore backed by the api_keys table
+type sqlKeyStore struct {
+	db *DB
+}
+
+func NewSQLKeyStore(db *DB) KeyStore {
+	return &sqlKeyStore{db: db}
+}
+
+// scanKey reads the keyColumns of a row, after any leading destinations
+func scanKey(row interface{ Scan(...any) error }, leading ...any) (KeyInfo, error) {
+	var info KeyInfo
+	var scopes string
+	var cidrs sql.NullString
+	dest := append(leading, &info.Hotkey, &info.Tier, &scopes, &info.Active, &info.Disabled, &info.ExpiresAt, &info.RateLimitRPS, &info.RateLimitBurst, &info.DailyQuota, &cidrs)
+	if err := row.Scan(dest...); err != nil {
+		return KeyInfo{}, err
+	}
+	info.setScopes(scopes)
+	info.setAllowedCIDRs(cidrs)
+	return info, nil
+}
+
+func (s *sqlKeyStore) KeyByHash(keyHash string, now time.Time) (KeyInfo, error) {
+	return scanKey(s.db.QueryRow(
+		"SELECT "+keyColumns+" FROM api_keys WHERE key_hash = ? OR (previous_key_hash = ? AND previous_key_expires_at > ?)",
+		keyHash, keyHash, now,
+	))
+}
+
+func (s *sqlKeyStore) KeyByHotkey(hotkey string) (KeyInfo, error) {
+	return scanKey(s.db.QueryRow("SELECT "+keyColumns+" FROM api_keys WHERE hotkey = ?", hotkey))
+}
+
+func (s *sqlKeyStore) RecentKeys(limit int) (map[string]KeyInfo, error) {
+	rows, err := s.db.Query(
+		"SELECT key_hash, "+keyColumns+" FROM api_keys WHERE active = TRUE AND disabled = FALSE ORDER BY last_used_at DESC LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]KeyInfo)
+	for rows.Next() {
+		var keyHash string
+		info, err := scanKey(rows, &keyHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan key: %w", err)
+		}
+		keys[keyHash] = info
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load keys: %w", err)
+	}
+	return keys, nil
+}
+
+// TouchKeys writes the timestamps in a single transaction
+func (s *sqlKeyStore) TouchKeys(lastUsed map[string]time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin last_used_at flush: %w", err)
+	}
+	for hotkey, at := range lastUsed {
+		if _, err := tx.Exec("UPDATE api_keys SET last_used_at = ? WHERE hotkey = ?", at, hotkey); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to update last_used_at: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit last_used_at flush: %w", err)
+	}
+	return nil
+}
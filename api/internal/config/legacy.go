@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LegacyUsage is how often one key has called one deprecated endpoint
+type LegacyUsage struct {
+	Route     string    `json:"route"`
+	Successor string    `json:"successor"`
+	Hotkey    string    `json:"hotkey"`
+	Count     int64     `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// LegacyTracker records calls to deprecated endpoints per key in the
+// legacy_usage table, so operators can tell when an alias is no longer used by
+// any replica's callers
+type LegacyTracker struct {
+	db *DB
+}
+
+func NewLegacyTracker(db *DB) *LegacyTracker {
+	return &LegacyTracker{db: db}
+}
+
+// Record counts a call to a deprecated route by a hotkey
+func (t *LegacyTracker) Record(route, successor, hotkey string) error {
+	_, err := t.db.Exec(
+		"INSERT INTO legacy_usage (route, hotkey, successor, calls, last_seen) VALUES (?, ?, ?, ?, ?) "+
+			t.db.Dialect.Upsert("legacy_usage", []string{"route", "hotkey"}, []string{"successor", "last_seen"}, []string{"calls"}),
+		route, hotkey, successor, 1, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record legacy usage: %w", err)
+	}
+	return nil
+}
+
+// All returns the recorded usage sorted by route and then most recent use
+func (t *LegacyTracker) All() ([]LegacyUsage, error) {
+	rows, err := t.db.Query("SELECT route, successor, hotkey, calls, last_seen FROM legacy_usage ORDER BY route, last_seen DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []LegacyUsage{}
+	for rows.Next() {
+		var u LegacyUsage
+		if err := rows.Scan(&u.Route, &u.Successor, &u.Hotkey, &u.Count, &u.LastSeen); err != nil {
+			return nil, fmt.Errorf("failed to read legacy usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
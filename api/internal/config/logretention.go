@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// LogRetentionSettings controls how long verification logs are kept. Rows
+// older than Age are deleted every Interval, BatchSize rows at a time so the
+// janitor never holds long locks on a busy table.
+type LogRetentionSettings struct {
+	Age       time.Duration
+	Interval  time.Duration
+	BatchSize int
+}
+
+// Enabled reports whether old verification logs are deleted at all
+func (s LogRetentionSettings) Enabled() bool {
+	return s.Age > 0
+}
+
+// parseLogRetentionSettings reads the verification log retention from the environment
+func parseLogRetentionSettings() (LogRetentionSettings, []error) {
+	var errs []error
+	var settings LogRetentionSettings
+
+	var err error
+	settings.Age, err = time.ParseDuration(getEnv("LOG_RETENTION", "0s"))
+	if err != nil || settings.Age < 0 {
+		errs = append(errs, fmt.Errorf("invalid LOG_RETENTION: must be a non-negative duration"))
+	}
+	settings.Interval, err = time.ParseDuration(getEnv("LOG_RETENTION_INTERVAL", "1h"))
+	if err != nil || settings.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid LOG_RETENTION_INTERVAL: must be a positive duration"))
+	}
+	settings.BatchSize, err = strconv.Atoi(getEnv("LOG_RETENTION_BATCH", "5000"))
+	if err != nil || settings.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid LOG_RETENTION_BATCH: must be a positive integer"))
+	}
+
+	return settings, errs
+}
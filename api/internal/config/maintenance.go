@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Maintenance modes. Rejected requests fail until the window ends; queued
+// ones are accepted on the async endpoint and run once it has ended.
+const (
+	MaintenanceReject = "reject"
+	MaintenanceQueue  = "queue"
+)
+
+// MaintenanceWindow takes a model out of service between Start and End
+type MaintenanceWindow struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Mode    string    `json:"mode"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Validate checks the window is well formed and not already over
+func (w MaintenanceWindow) Validate(now time.Time) error {
+	if w.Mode != MaintenanceReject && w.Mode != MaintenanceQueue {
+		return fmt.Errorf("mode must be %s or %s", MaintenanceReject, MaintenanceQueue)
+	}
+	if !w.End.After(w.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if !w.End.After(now) {
+		return fmt.Errorf("end must be in the future")
+	}
+	return nil
+}
+
+// ActiveMaintenance returns the window the route is in at now. When windows
+// overlap, the one ending last is returned so clients are not told to retry
+// into the next window.
+func (r ModelRoute) ActiveMaintenance(now time.Time) (MaintenanceWindow, bool) {
+	var active MaintenanceWindow
+	found := false
+	for _, w := range r.Maintenance {
+		if now.Before(w.Start) || !now.Before(w.End) {
+			continue
+		}
+		if !found || w.End.After(active.End) {
+			active = w
+			found = true
+		}
+	}
+	return active, found
+}
+
+// Maintenance returns the maintenance window a model is in at now
+func (t *RouteTable) Maintenance(model string, now time.Time) (MaintenanceWindow, bool) {
+	route, ok := t.Lookup(model)
+	if !ok {
+		return MaintenanceWindow{}, false
+	}
+	return route.ActiveMaintenance(now)
+}
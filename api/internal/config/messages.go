@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MessageTemplate replaces the human-readable message of an error code. The
+// message may use {message} for the proxy's own message, {code} and
+// {request_id}. HelpURL points the client at a runbook or status page.
+type MessageTemplate struct {
+	Message string `json:"message,omitempty"`
+	HelpURL string `json:"help_url,omitempty"`
+}
+
+// MessageTemplates maps error codes to operator templates. A key of the form
+// "CODE.lang", such as "QUOTA_EXCEEDED.de", is used for clients whose
+// Accept-Language prefers that language over the plain "CODE" entry.
+type MessageTemplates map[string]MessageTemplate
+
+// Lookup returns the template for code in the language the client prefers most
+func (m MessageTemplates) Lookup(code string, acceptLanguage string) (MessageTemplate, bool) {
+	if len(m) == 0 {
+		return MessageTemplate{}, false
+	}
+	base, ok := m[code]
+	for _, lang := range preferredLanguages(acceptLanguage) {
+		t, found := m[code+"."+lang]
+		if !found {
+			if primary, _, cut := strings.Cut(lang, "-"); cut {
+				t, found = m[code+"."+primary]
+			}
+		}
+		if found {
+			// Translations share the help URL of the plain entry unless they set their own
+			if t.HelpURL == "" {
+				t.HelpURL = base.HelpURL
+			}
+			return t, true
+		}
+	}
+	return base, ok
+}
+
+// Render fills the template's placeholders, keeping message when the template
+// only adds a help URL
+func (t MessageTemplate) Render(code string, message string, requestID string) string {
+	if t.Message == "" {
+		return message
+	}
+	return strings.NewReplacer(
+		"{message}", message,
+		"{code}", code,
+		"{request_id}", requestID,
+	).Replace(t.Message)
+}
+
+// preferredLanguages returns the lowercased language tags of an Accept-Language
+// header, most preferred first
+func preferredLanguages(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			langs = append(langs, weighted{tag, q})
+		}
+	}
+	slices.SortStableFunc(langs, func(a, b weighted) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	tags := make([]string, len(langs))
+	for i, l := range langs {
+		tags[i] = l.lang
+	}
+	return tags
+}
+
+// parseMessageTemplates reads the error message templates from ERROR_MESSAGES,
+// or from the JSON file named by ERROR_MESSAGES_FILE
+func parseMessageTemplates() (MessageTemplates, []error) {
+	var errs []error
+
+	raw := getEnv("ERROR_MESSAGES", "")
+	file := getEnv("ERROR_MESSAGES_FILE", "")
+	if raw != "" && file != "" {
+		return nil, append(errs, fmt.Errorf("set only one of ERROR_MESSAGES and ERROR_MESSAGES_FILE"))
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, append(errs, fmt.Errorf("invalid ERROR_MESSAGES_FILE: %w", err))
+		}
+		raw = string(data)
+	}
+	if raw == "" {
+		return nil, errs
+	}
+
+	var templates MessageTemplates
+	if err := json.Unmarshal([]byte(raw), &templates); err != nil {
+		return nil, append(errs, fmt.Errorf("invalid ERROR_MESSAGES: %w", err))
+	}
+	for key, t := range templates {
+		if t.Message == "" && t.HelpURL == "" {
+			errs = append(errs, fmt.Errorf("invalid ERROR_MESSAGES: %s sets neither message nor help_url", key))
+		}
+	}
+
+	return templates, errs
+}
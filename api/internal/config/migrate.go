@@ -0,0 +1,204 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationLock serializes migrations across replicas starting at the same time
+const migrationLock = "verifier_proxy_migrations"
+
+// migration is one embedded, versioned schema change
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// loadMigrations returns the embedded migrations of a dialect ordered by
+// version. Files are named migrations/<dialect>/<version>_<name>.sql.
+func loadMigrations(dialect Dialect) ([]migration, error) {
+	dir := path.Join("migrations", string(dialect))
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s has no version prefix", entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+
+		body, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, migration{Version: version, Name: name, SQL: string(body)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements splits a migration into statements ending in a semicolon at
+// the end of a line, dropping comment lines
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// Migrate applies every embedded migration the database has not seen yet and
+// returns the names of those applied
+func Migrate(db *DB) ([]string, error) {
+	migrations, err := loadMigrations(db.Dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve connection: %w", err)
+	}
+	defer conn.Close()
+
+	unlock, err := lockMigrations(ctx, conn, db.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+
+	var ran []string
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		// MySQL commits DDL implicitly, so a failed migration is not rolled back
+		// and must be written to be safe to re-run
+		for _, statement := range splitStatements(m.SQL) {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return ran, fmt.Errorf("migration %s failed: %w", m.Name, err)
+			}
+		}
+
+		// Tables created by hand before migrations existed are skipped by
+		// CREATE TABLE IF NOT EXISTS, so bring their columns up to date
+		if err := addMissingColumns(ctx, conn, db.Dialect, m.SQL); err != nil {
+			return ran, fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+
+		if _, err := conn.ExecContext(ctx,
+			db.Dialect.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+			m.Version, m.Name, time.Now(),
+		); err != nil {
+			return ran, fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+		}
+		ran = append(ran, m.Name)
+	}
+
+	return ran, nil
+}
+
+// lockMigrations takes a lock held by the connection until unlock is called.
+// SQLite serializes writers itself and has no named locks.
+func lockMigrations(ctx context.Context, conn *sql.Conn, dialect Dialect) (func(), error) {
+	switch dialect {
+	case DialectMySQL:
+		var locked sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", migrationLock).Scan(&locked); err != nil {
+			return nil, fmt.Errorf("failed to take migration lock: %w", err)
+		}
+		if locked.Int64 != 1 {
+			return nil, fmt.Errorf("timed out waiting for migration lock")
+		}
+		return func() { conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLock) }, nil
+	case DialectPostgres:
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", migrationLock); err != nil {
+			return nil, fmt.Errorf("failed to take migration lock: %w", err)
+		}
+		return func() { conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", migrationLock) }, nil
+	default:
+		return func() {}, nil
+	}
+}
+
+// addMissingColumns adds the columns of tables created in ddl that the live
+// tables lack, using the definitions from the CREATE TABLE statements
+func addMissingColumns(ctx context.Context, conn *sql.Conn, dialect Dialect, ddl string) error {
+	live, err := dialect.liveColumns(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for table, columns := range parseColumnDefinitions(ddl) {
+		for _, column := range columns {
+			if live[strings.ToLower(table)][strings.ToLower(column.Name)] {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx, "ALTER TABLE "+table+" ADD COLUMN "+column.Definition); err != nil {
+				return fmt.Errorf("failed to add %s.%s: %w", table, column.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+-- Durable trail of key management operations
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_hotkey VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    detail TEXT,
+    proxy_request_id VARCHAR(64),
+    source_ip VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_admin_audit_created (created_at),
+    INDEX idx_admin_audit_target (target)
+);
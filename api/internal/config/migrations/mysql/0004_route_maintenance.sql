@@ -0,0 +1,2 @@
+-- Scheduled maintenance windows per model route
+ALTER TABLE model_routes ADD COLUMN maintenance JSON;
@@ -0,0 +1,3 @@
+-- Unused key policy: keys flagged as unused and keys exempted by an admin
+ALTER TABLE api_keys ADD COLUMN prune_flagged_at TIMESTAMP NULL;
+ALTER TABLE api_keys ADD COLUMN prune_exempt BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+-- Async job claims, so only one replica runs a given job until its lease expires
+ALTER TABLE verification_jobs ADD COLUMN owner VARCHAR(255) NULL;
+ALTER TABLE verification_jobs ADD COLUMN lease_until TIMESTAMP NULL;
@@ -0,0 +1,9 @@
+-- Calls to deprecated endpoint aliases per hotkey, shared across replicas
+CREATE TABLE IF NOT EXISTS legacy_usage (
+    route VARCHAR(255) NOT NULL,
+    hotkey VARCHAR(255) NOT NULL,
+    successor VARCHAR(255) NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    last_seen TIMESTAMP NOT NULL,
+    PRIMARY KEY (route, hotkey)
+);
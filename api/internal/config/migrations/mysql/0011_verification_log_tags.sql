@@ -0,0 +1,2 @@
+-- Request tags of a logged verification, a JSON array
+ALTER TABLE verification_logs ADD COLUMN tags TEXT NULL;
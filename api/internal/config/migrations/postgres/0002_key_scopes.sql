@@ -0,0 +1,5 @@
+-- Per-key scopes replace is_admin for authorization. Admin keys keep full
+-- access through the "*" scope; every other key may verify.
+ALTER TABLE api_keys ADD COLUMN scopes VARCHAR(512) NOT NULL DEFAULT 'verify';
+
+UPDATE api_keys SET scopes = '*' WHERE is_admin = TRUE;
@@ -0,0 +1,13 @@
+-- Durable trail of key management operations
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id BIGSERIAL PRIMARY KEY,
+    actor_hotkey VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    detail TEXT,
+    proxy_request_id VARCHAR(64),
+    source_ip VARCHAR(64),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_created ON admin_audit (created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_target ON admin_audit (target);
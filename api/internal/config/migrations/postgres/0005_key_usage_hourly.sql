@@ -0,0 +1,8 @@
+-- Verification counts per hotkey and UTC hour, for usage heatmaps
+CREATE TABLE IF NOT EXISTS key_usage_hourly (
+    hotkey VARCHAR(255) NOT NULL,
+    hour CHAR(13) NOT NULL,
+    verifications BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (hotkey, hour)
+);
+CREATE INDEX IF NOT EXISTS idx_key_usage_hourly_hour ON key_usage_hourly (hour);
@@ -0,0 +1,2 @@
+-- Looking up a verification result by request_id for the key that requested it
+CREATE INDEX IF NOT EXISTS idx_verification_logs_request ON verification_logs (hotkey, request_id);
@@ -0,0 +1,2 @@
+-- Per-key client address allowlist, a comma-separated list of CIDRs
+ALTER TABLE api_keys ADD COLUMN allowed_cidrs TEXT NULL;
@@ -0,0 +1,193 @@
+-- Baseline schema. Tables that already exist are left as they are and only
+-- gain the columns they are missing.
+
+-- API keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    hotkey VARCHAR(255) PRIMARY KEY,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    key_hint VARCHAR(8) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP NULL,
+    is_admin BOOLEAN DEFAULT FALSE,
+    tier VARCHAR(32) NOT NULL DEFAULT 'standard',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    challenge VARCHAR(255) NULL,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    auto_provisioned BOOLEAN NOT NULL DEFAULT FALSE,
+    rate_limit_rps REAL NULL,
+    rate_limit_burst INT NULL,
+    previous_key_hash CHAR(64) NULL UNIQUE,
+    previous_key_expires_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL,
+    daily_quota INT NULL
+);
+
+-- Audit of verdicts adjusted by per-model verification policies
+CREATE TABLE IF NOT EXISTS policy_decisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    score REAL NOT NULL,
+    threshold REAL NOT NULL,
+    in_grace BOOLEAN NOT NULL,
+    backend_verified BOOLEAN NOT NULL,
+    verified BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_policy_decisions_model ON policy_decisions (model);
+
+-- Per-backend verdicts recorded in multi-verifier consensus mode
+CREATE TABLE IF NOT EXISTS consensus_verdicts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    backend VARCHAR(512) NOT NULL,
+    verified BOOLEAN NOT NULL,
+    cause TEXT,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_consensus_verdicts_request ON consensus_verdicts (request_id);
+
+-- Suspicious-pattern flags raised against hotkeys for review
+CREATE TABLE IF NOT EXISTS key_flags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    hotkey VARCHAR(255) NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    details TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL
+);
+CREATE INDEX IF NOT EXISTS idx_key_flags_hotkey ON key_flags (hotkey);
+
+-- Asynchronous verification jobs, persisted before they are acknowledged
+CREATE TABLE IF NOT EXISTS verification_jobs (
+    id VARCHAR(64) PRIMARY KEY,
+    hotkey VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    status VARCHAR(16) NOT NULL,
+    request BLOB NOT NULL,
+    consensus BOOLEAN DEFAULT FALSE,
+    skip_dedup BOOLEAN DEFAULT FALSE,
+    retention VARCHAR(16) NOT NULL DEFAULT 'full',
+    result BLOB NULL,
+    error TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL
+);
+CREATE INDEX IF NOT EXISTS idx_verification_jobs_status ON verification_jobs (status);
+
+-- Model to verifier backend routing, managed through /admin/routes
+CREATE TABLE IF NOT EXISTS model_routes (
+    model VARCHAR(255) PRIMARY KEY,
+    backend_url VARCHAR(512) NOT NULL,
+    path VARCHAR(255) NOT NULL DEFAULT '/verify',
+    backend_server VARCHAR(255) NOT NULL,
+    cause_rules TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Verification counts and token totals per hotkey, model and UTC day
+CREATE TABLE IF NOT EXISTS key_usage (
+    hotkey VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    verifications BIGINT NOT NULL DEFAULT 0,
+    verified BIGINT NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    response_tokens BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (hotkey, day, model)
+);
+CREATE INDEX IF NOT EXISTS idx_key_usage_day ON key_usage (day);
+
+-- Payloads rejected as hostile, kept for abuse forensics instead of forwarded
+CREATE TABLE IF NOT EXISTS payload_quarantine (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    hotkey VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    payload BLOB NOT NULL,
+    source_ip VARCHAR(64),
+    reviewed_at TIMESTAMP NULL,
+    reviewed_by VARCHAR(255),
+    review_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_payload_quarantine_hotkey ON payload_quarantine (hotkey, created_at);
+CREATE INDEX IF NOT EXISTS idx_payload_quarantine_reviewed ON payload_quarantine (reviewed_at);
+
+-- Audit of verdicts replaced by an administrator
+CREATE TABLE IF NOT EXISTS verdict_overrides (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id VARCHAR(255) NOT NULL,
+    hotkey VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    previous_verified BOOLEAN NOT NULL,
+    verified BOOLEAN NOT NULL,
+    reason TEXT NOT NULL,
+    admin_hotkey VARCHAR(255) NOT NULL,
+    proxy_request_id VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_verdict_overrides_request ON verdict_overrides (request_id);
+
+-- Reference requests with a known verdict, replayed by the canary prober
+CREATE TABLE IF NOT EXISTS synthetic_payloads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    model VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    request TEXT NOT NULL,
+    expect_verified BOOLEAN NOT NULL DEFAULT TRUE,
+    source VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (model, name)
+);
+
+-- Audit of admin requests made on behalf of another hotkey
+CREATE TABLE IF NOT EXISTS impersonation_audit (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    admin_hotkey VARCHAR(255) NOT NULL,
+    target_hotkey VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    path VARCHAR(512) NOT NULL,
+    proxy_request_id VARCHAR(64),
+    source_ip VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_target ON impersonation_audit (target_hotkey);
+
+-- Caller-supplied tags on verification requests, for per-tag outcome stats
+CREATE TABLE IF NOT EXISTS verification_tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    hotkey VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    verified BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_verification_tags_hotkey_tag ON verification_tags (hotkey, tag);
+
+-- Audit trail of every verification outcome served
+CREATE TABLE IF NOT EXISTS verification_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id VARCHAR(255),
+    hotkey VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    verified BOOLEAN NOT NULL,
+    cause TEXT,
+    cause_code VARCHAR(32),
+    error TEXT,
+    input_tokens BIGINT,
+    response_tokens BIGINT,
+    gpus INT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL,
+    source VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_verification_logs_created ON verification_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_verification_logs_hotkey ON verification_logs (hotkey, created_at);
+CREATE INDEX IF NOT EXISTS idx_verification_logs_model ON verification_logs (model, created_at);
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MirrorSettings controls the asynchronous copy of verifications sent to an
+// external analytics endpoint. Copies are redacted and best-effort: they are
+// dropped when the queue is full and never retried.
+type MirrorSettings struct {
+	URL            string
+	QueueSize      int
+	Workers        int
+	Timeout        time.Duration
+	IncludePayload bool
+	HashHotkeys    bool
+	Redact         []string
+}
+
+// Enabled reports whether a mirror endpoint is configured
+func (s MirrorSettings) Enabled() bool {
+	return s.URL != ""
+}
+
+// parseMirrorSettings reads the analytics mirror settings from the environment
+func parseMirrorSettings() (MirrorSettings, []error) {
+	var errs []error
+
+	settings := MirrorSettings{
+		URL:            getEnv("ANALYTICS_MIRROR_URL", ""),
+		IncludePayload: strings.ToLower(getEnv("ANALYTICS_MIRROR_PAYLOAD", "true")) == "true",
+		HashHotkeys:    strings.ToLower(getEnv("ANALYTICS_MIRROR_HASH_HOTKEYS", "true")) == "true",
+		Redact:         splitList(getEnv("ANALYTICS_MIRROR_REDACT_FIELDS", "api_key,authorization,password,secret,token")),
+	}
+
+	if settings.URL != "" {
+		if u, err := url.Parse(settings.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid ANALYTICS_MIRROR_URL: must be an absolute http(s) URL"))
+		}
+	}
+
+	queueSize, err := strconv.Atoi(getEnv("ANALYTICS_MIRROR_QUEUE_SIZE", "1000"))
+	if err != nil || queueSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ANALYTICS_MIRROR_QUEUE_SIZE: must be a positive integer"))
+	}
+	settings.QueueSize = queueSize
+
+	workers, err := strconv.Atoi(getEnv("ANALYTICS_MIRROR_WORKERS", "2"))
+	if err != nil || workers <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ANALYTICS_MIRROR_WORKERS: must be a positive integer"))
+	}
+	settings.Workers = workers
+
+	timeout, err := time.ParseDuration(getEnv("ANALYTICS_MIRROR_TIMEOUT", "5s"))
+	if err != nil || timeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid ANALYTICS_MIRROR_TIMEOUT: must be a positive duration"))
+	}
+	settings.Timeout = timeout
+
+	return settings, errs
+}
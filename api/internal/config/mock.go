@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MockResponse is the scripted answer of the in-process mock backend for a model.
+// A Status of 500 or above makes the mock fail the way an unreachable backend would.
+type MockResponse struct {
+	Verified bool     `json:"verified"`
+	Cause    string   `json:"cause,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	Score    *float64 `json:"score,omitempty"`
+	Status   int      `json:"status,omitempty"`
+	Latency  Duration `json:"latency,omitempty"`
+}
+
+// Duration is a time.Duration that unmarshals from strings such as "250ms"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as 250ms")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MockSettings replaces the verifier backend with scripted responses so the
+// proxy can run without haproxy and Valis
+type MockSettings struct {
+	Enabled   bool
+	Responses map[string]MockResponse
+}
+
+// Response returns the scripted response for a model, falling back to the "*"
+// entry and then to a plain verified answer
+func (m MockSettings) Response(model string) MockResponse {
+	if resp, ok := m.Responses[model]; ok {
+		return resp
+	}
+	if resp, ok := m.Responses["*"]; ok {
+		return resp
+	}
+	return MockResponse{Verified: true}
+}
+
+// parseMockSettings reads the mock backend settings from the environment
+func parseMockSettings() (MockSettings, []error) {
+	var errs []error
+
+	settings := MockSettings{
+		Enabled: strings.ToLower(getEnv("MOCK_BACKEND", "false")) == "true",
+	}
+
+	raw := getEnv("MOCK_BACKEND_RESPONSES", "")
+	if raw == "" {
+		return settings, errs
+	}
+
+	if err := json.Unmarshal([]byte(raw), &settings.Responses); err != nil {
+		errs = append(errs, fmt.Errorf("invalid MOCK_BACKEND_RESPONSES: %w", err))
+		return settings, errs
+	}
+	for model, resp := range settings.Responses {
+		if resp.Status != 0 && (resp.Status < 100 || resp.Status > 599) {
+			errs = append(errs, fmt.Errorf("invalid MOCK_BACKEND_RESPONSES: status for %s must be an HTTP status", model))
+		}
+		if resp.Latency < 0 {
+			errs = append(errs, fmt.Errorf("invalid MOCK_BACKEND_RESPONSES: latency for %s must not be negative", model))
+		}
+	}
+
+	return settings, errs
+}
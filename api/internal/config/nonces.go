@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NonceCache remembers used request nonces until they expire, to reject
+// replays. With a shared Redis cache the nonces are recorded there, so a
+// request cannot be replayed against another replica.
+type NonceCache struct {
+	shared *RedisCache
+	seen   map[string]time.Time
+	mutex  sync.Mutex
+}
+
+// NewNonceCache returns a nonce cache backed by shared, or by process memory when shared is nil
+func NewNonceCache(shared *RedisCache) *NonceCache {
+	return &NonceCache{
+		shared: shared,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Use records a nonce, returning false if it was already used and has not
+// expired. If Redis cannot be reached the nonce is checked in memory only.
+func (n *NonceCache) Use(nonce string, ttl time.Duration) bool {
+	if n.shared != nil {
+		fresh, err := n.shared.SetNX("nonce:"+nonce, ttl)
+		if err == nil {
+			return fresh
+		}
+		fmt.Printf("Warning: Failed to record nonce in redis: %v\n", err)
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := time.Now()
+	if expiresAt, ok := n.seen[nonce]; ok && now.Before(expiresAt) {
+		return false
+	}
+	n.seen[nonce] = now.Add(ttl)
+	return true
+}
+
+// Cleanup drops expired nonces
+func (n *NonceCache) Cleanup() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	now := time.Now()
+	for nonce, expiresAt := range n.seen {
+		if now.After(expiresAt) {
+			delete(n.seen, nonce)
+		}
+	}
+}
+
+// StartCleanupRoutine periodically drops expired nonces
+func (n *NonceCache) StartCleanupRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			n.Cleanup()
+		}
+	}()
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// OutboundSettings controls how the proxy reaches verifier backends and other
+// upstreams: through an explicit proxy or the one in HTTP(S)_PROXY, and
+// trusting a private CA bundle in addition to the system roots
+type OutboundSettings struct {
+	ProxyURL string
+	CAFile   string
+
+	proxy   *url.URL
+	rootCAs *x509.CertPool
+}
+
+// parseOutboundSettings reads the egress proxy and CA bundle from the environment
+func parseOutboundSettings() (OutboundSettings, []error) {
+	var errs []error
+
+	settings := OutboundSettings{
+		ProxyURL: getEnv("OUTBOUND_PROXY", ""),
+		CAFile:   getEnv("OUTBOUND_CA_FILE", ""),
+	}
+
+	if settings.ProxyURL != "" {
+		proxy, err := url.Parse(settings.ProxyURL)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid OUTBOUND_PROXY: must be a URL such as http://proxy:3128"))
+		} else {
+			settings.proxy = proxy
+		}
+	}
+
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid OUTBOUND_CA_FILE: %w", err))
+			return settings, errs
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			errs = append(errs, fmt.Errorf("invalid OUTBOUND_CA_FILE: no PEM certificates found in %s", settings.CAFile))
+		}
+		settings.rootCAs = pool
+	}
+
+	return settings, errs
+}
+
+// RedactedProxy returns the proxy URL with any password masked
+func (s OutboundSettings) RedactedProxy() string {
+	if s.proxy == nil {
+		return s.ProxyURL
+	}
+	return s.proxy.Redacted()
+}
+
+// Transport returns a transport for outbound calls with the proxy and CA
+// bundle applied. Without OUTBOUND_PROXY the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY variables are honoured.
+func (s OutboundSettings) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if s.proxy != nil {
+		transport.Proxy = http.ProxyURL(s.proxy)
+	}
+	if s.rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{RootCAs: s.rootCAs}
+	}
+	return transport
+}
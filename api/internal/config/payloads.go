@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SyntheticPayload is a known reference request for a model, used to probe
+// backends with traffic that has a known verdict
+type SyntheticPayload struct {
+	Id             int64           `json:"id"`
+	Model          string          `json:"model"`
+	Name           string          `json:"name"`
+	Request        json.RawMessage `json:"request"`
+	ExpectVerified bool            `json:"expect_verified"`
+	Source         string          `json:"source"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// PayloadLibrary is an in-memory copy of the synthetic_payloads table
+type PayloadLibrary struct {
+	payloads []SyntheticPayload
+	mutex    sync.RWMutex
+}
+
+func NewPayloadLibrary() *PayloadLibrary {
+	return &PayloadLibrary{}
+}
+
+// All returns every payload ordered by model and name
+func (l *PayloadLibrary) All() []SyntheticPayload {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	return append([]SyntheticPayload(nil), l.payloads...)
+}
+
+// ForModel returns the payloads registered for a model
+func (l *PayloadLibrary) ForModel(model string) []SyntheticPayload {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	var payloads []SyntheticPayload
+	for _, payload := range l.payloads {
+		if payload.Model == model {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads
+}
+
+// Reload replaces the library contents with the rows in synthetic_payloads
+func (l *PayloadLibrary) Reload(db *DB) error {
+	rows, err := db.Query("SELECT id, model, name, request, expect_verified, source, created_at FROM synthetic_payloads ORDER BY model, name")
+	if err != nil {
+		return fmt.Errorf("failed to query synthetic payloads: %w", err)
+	}
+	defer rows.Close()
+
+	var payloads []SyntheticPayload
+	for rows.Next() {
+		var payload SyntheticPayload
+		if err := rows.Scan(&payload.Id, &payload.Model, &payload.Name, &payload.Request, &payload.ExpectVerified, &payload.Source, &payload.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan synthetic payload: %w", err)
+		}
+		payloads = append(payloads, payload)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read synthetic payloads: %w", err)
+	}
+
+	l.mutex.Lock()
+	l.payloads = payloads
+	l.mutex.Unlock()
+
+	return nil
+}
+
+// StartRefreshRoutine periodically reloads the library so cases added through
+// other replicas are picked up
+func (l *PayloadLibrary) StartRefreshRoutine(db *DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := l.Reload(db); err != nil {
+				fmt.Printf("Warning: Failed to refresh synthetic payloads: %v\n", err)
+			}
+		}
+	}()
+}
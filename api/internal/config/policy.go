@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// VerificationPolicy decides the final verdict for a model from the backend's raw score
+type VerificationPolicy struct {
+	Threshold      float64   `json:"threshold"`
+	GraceThreshold float64   `json:"grace_threshold"`
+	GraceUntil     time.Time `json:"grace_until"`
+}
+
+// PolicyDecision is the outcome of evaluating a VerificationPolicy against a score
+type PolicyDecision struct {
+	Verified  bool
+	Threshold float64
+	InGrace   bool
+	Overrode  bool
+}
+
+// Evaluate applies the policy to a backend score and verdict
+func (p VerificationPolicy) Evaluate(score float64, backendVerified bool, now time.Time) PolicyDecision {
+	threshold := p.Threshold
+	inGrace := !p.GraceUntil.IsZero() && now.Before(p.GraceUntil)
+	if inGrace {
+		threshold = p.GraceThreshold
+	}
+
+	verified := score >= threshold
+	return PolicyDecision{
+		Verified:  verified,
+		Threshold: threshold,
+		InGrace:   inGrace,
+		Overrode:  verified != backendVerified,
+	}
+}
+
+// parsePolicies reads per-model policies from a JSON object keyed by model name
+func parsePolicies(raw string) (map[string]VerificationPolicy, error) {
+	policies := make(map[string]VerificationPolicy)
+	if raw == "" {
+		return policies, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &policies); err != nil {
+		return nil, fmt.Errorf("invalid VERIFY_POLICIES: %w", err)
+	}
+
+	// A missing threshold would decode as 0 and verify every result
+	var thresholds map[string]struct {
+		Threshold      *float64 `json:"threshold"`
+		GraceThreshold *float64 `json:"grace_threshold"`
+	}
+	if err := json.Unmarshal([]byte(raw), &thresholds); err != nil {
+		return nil, fmt.Errorf("invalid VERIFY_POLICIES: %w", err)
+	}
+
+	for model, p := range policies {
+		if thresholds[model].Threshold == nil {
+			return nil, fmt.Errorf("invalid VERIFY_POLICIES: policy for %s must set a threshold", model)
+		}
+		if p.Threshold < 0 || p.Threshold > 1 {
+			return nil, fmt.Errorf("invalid VERIFY_POLICIES: threshold for %s must be between 0 and 1", model)
+		}
+		if !p.GraceUntil.IsZero() && thresholds[model].GraceThreshold == nil {
+			return nil, fmt.Errorf("invalid VERIFY_POLICIES: policy for %s sets grace_until and must set a grace_threshold", model)
+		}
+		if !p.GraceUntil.IsZero() && (p.GraceThreshold < 0 || p.GraceThreshold > 1) {
+			return nil, fmt.Errorf("invalid VERIFY_POLICIES: grace_threshold for %s must be between 0 and 1", model)
+		}
+	}
+
+	return policies, nil
+}
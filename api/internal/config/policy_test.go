@@ -0,0 +1,16 @@
+package config
+
+import "testing"
+
+func TestParsePoliciesRequiresThresholds(t *testing.T) {
+	for raw, valid := range map[string]bool{
+		`{"m":{"threshold":0.8}}`: true,
+		`{"m":{"threshold":0.8,"grace_threshold":0.5,"grace_until":"2030-01-01T00:00:00Z"}}`: true,
+		`{"m":{"grace_threshold":0.5}}`:                                false,
+		`{"m":{"threshold":0.8,"grace_until":"2030-01-01T00:00:00Z"}}`: false,
+	} {
+		if _, err := parsePolicies(raw); (err == nil) != valid {
+			t.Errorf("parsePolicies(%s) error = %v, want valid %v", raw, err, valid)
+		}
+	}
+}
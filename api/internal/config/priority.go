@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Sources of a backend call's scheduling priority
+const (
+	PriorityOff   = "off"
+	PriorityTier  = "tier"
+	PriorityStake = "stake"
+)
+
+// PrioritySettings decides which saturated backend calls are served first.
+// Higher levels go first; a queued call gains a level for every Aging it
+// waits, so low priority keys are delayed but never starved.
+type PrioritySettings struct {
+	Source string
+	// Tiers maps a key tier to its level; unlisted tiers are level 0
+	Tiers map[string]int
+	// StakeLevels are ascending stake thresholds; a hotkey's level is the
+	// number of thresholds its stake reaches
+	StakeLevels []float64
+	Aging       time.Duration
+}
+
+// Level returns the priority of a call from a key of tier whose hotkey has stake
+func (p PrioritySettings) Level(tier string, stake float64) int {
+	switch p.Source {
+	case PriorityTier:
+		return p.Tiers[tier]
+	case PriorityStake:
+		level := 0
+		for _, threshold := range p.StakeLevels {
+			if stake >= threshold {
+				level++
+			}
+		}
+		return level
+	default:
+		return 0
+	}
+}
+
+// parsePrioritySettings reads the scheduling priority settings from the environment
+func parsePrioritySettings() (PrioritySettings, []error) {
+	var errs []error
+
+	settings := PrioritySettings{
+		Source: getEnv("PRIORITY_SOURCE", PriorityOff),
+		Tiers:  make(map[string]int),
+	}
+	if settings.Source != PriorityOff && settings.Source != PriorityTier && settings.Source != PriorityStake {
+		errs = append(errs, fmt.Errorf("invalid PRIORITY_SOURCE %q: must be off, tier or stake", settings.Source))
+	}
+
+	if raw := getEnv("PRIORITY_TIERS", ""); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &settings.Tiers); err != nil {
+			errs = append(errs, fmt.Errorf("invalid PRIORITY_TIERS: %w", err))
+		}
+	}
+
+	for _, value := range splitList(getEnv("PRIORITY_STAKE_LEVELS", "")) {
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 {
+			errs = append(errs, fmt.Errorf("invalid PRIORITY_STAKE_LEVELS: %q must be a non-negative stake", value))
+			continue
+		}
+		settings.StakeLevels = append(settings.StakeLevels, threshold)
+	}
+	slices.Sort(settings.StakeLevels)
+
+	aging, err := time.ParseDuration(getEnv("PRIORITY_AGING", "500ms"))
+	if err != nil || aging <= 0 {
+		errs = append(errs, fmt.Errorf("invalid PRIORITY_AGING: must be a positive duration"))
+	}
+	settings.Aging = aging
+
+	return settings, errs
+}
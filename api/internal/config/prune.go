@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// KeyPruneSettings controls the unused key policy. Keys unused for After are
+// flagged, and with Disable set, disabled once they have stayed unused for
+// Notice after being flagged. WebhookURL, when set, is notified of flagged keys.
+type KeyPruneSettings struct {
+	After      time.Duration
+	Notice     time.Duration
+	Disable    bool
+	Interval   time.Duration
+	WebhookURL string
+}
+
+// Enabled reports whether unused keys are flagged at all
+func (s KeyPruneSettings) Enabled() bool {
+	return s.After > 0
+}
+
+// parseKeyPruneSettings reads the unused key policy from the environment
+func parseKeyPruneSettings() (KeyPruneSettings, []error) {
+	var errs []error
+
+	settings := KeyPruneSettings{
+		Disable:    strings.ToLower(getEnv("KEY_PRUNE_DISABLE", "false")) == "true",
+		WebhookURL: getEnv("KEY_PRUNE_WEBHOOK", ""),
+	}
+
+	var err error
+	settings.After, err = time.ParseDuration(getEnv("KEY_PRUNE_AFTER", "0s"))
+	if err != nil || settings.After < 0 {
+		errs = append(errs, fmt.Errorf("invalid KEY_PRUNE_AFTER: must be a non-negative duration"))
+	}
+	settings.Notice, err = time.ParseDuration(getEnv("KEY_PRUNE_NOTICE", "168h"))
+	if err != nil || settings.Notice < 0 {
+		errs = append(errs, fmt.Errorf("invalid KEY_PRUNE_NOTICE: must be a non-negative duration"))
+	}
+	settings.Interval, err = time.ParseDuration(getEnv("KEY_PRUNE_INTERVAL", "1h"))
+	if err != nil || settings.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid KEY_PRUNE_INTERVAL: must be a positive duration"))
+	}
+	if settings.WebhookURL != "" {
+		if u, err := url.Parse(settings.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid KEY_PRUNE_WEBHOOK: must be an absolute http(s) URL"))
+		}
+	}
+	if settings.Disable && !settings.Enabled() {
+		errs = append(errs, fmt.Errorf("invalid KEY_PRUNE_DISABLE: requires KEY_PRUNE_AFTER"))
+	}
+
+	return settings, errs
+}
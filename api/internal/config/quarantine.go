@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// QuarantineSettings holds the limits beyond which a verification payload is
+// treated as hostile and quarantined instead of forwarded. A zero limit
+// disables that check.
+type QuarantineSettings struct {
+	MaxChunks     int
+	MaxFieldBytes int64
+	MaxDepth      int
+}
+
+// parseQuarantineSettings reads the quarantine limits from the environment
+func parseQuarantineSettings() (QuarantineSettings, []error) {
+	var errs []error
+	var settings QuarantineSettings
+
+	maxChunks, err := strconv.Atoi(getEnv("QUARANTINE_MAX_CHUNKS", "100000"))
+	if err != nil || maxChunks < 0 {
+		errs = append(errs, fmt.Errorf("invalid QUARANTINE_MAX_CHUNKS: must be a non-negative integer"))
+	}
+	settings.MaxChunks = maxChunks
+
+	maxFieldBytes, err := bytes.Parse(getEnv("QUARANTINE_MAX_FIELD_SIZE", "1M"))
+	if err != nil || maxFieldBytes < 0 {
+		errs = append(errs, fmt.Errorf("invalid QUARANTINE_MAX_FIELD_SIZE: must be a size such as 1M"))
+	}
+	settings.MaxFieldBytes = maxFieldBytes
+
+	maxDepth, err := strconv.Atoi(getEnv("QUARANTINE_MAX_DEPTH", "32"))
+	if err != nil || maxDepth < 0 {
+		errs = append(errs, fmt.Errorf("invalid QUARANTINE_MAX_DEPTH: must be a non-negative integer"))
+	}
+	settings.MaxDepth = maxDepth
+
+	return settings, errs
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"golang.org/x/time/rate"
+)
+
+// StakeWeight turns a hotkey's stake into a multiplier for its rate limit
+type StakeWeight struct {
+	program *vm.Program
+}
+
+// NewStakeWeight compiles a policy expression over `stake` and `min_stake`,
+// for example "min(10, max(1, stake / min_stake))". An empty expression
+// weights every hotkey equally.
+func NewStakeWeight(source string) (*StakeWeight, error) {
+	if source == "" {
+		return &StakeWeight{}, nil
+	}
+
+	program, err := expr.Compile(source,
+		expr.Env(map[string]any{"stake": 0.0, "min_stake": 0.0}),
+		expr.AsFloat64(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid STAKE_WEIGHT_EXPR: %w", err)
+	}
+
+	return &StakeWeight{program: program}, nil
+}
+
+// Weight evaluates the expression for a stake, never returning less than zero
+func (w *StakeWeight) Weight(stake, minStake float64) (float64, error) {
+	if w.program == nil {
+		return 1, nil
+	}
+
+	out, err := expr.Run(w.program, map[string]any{"stake": stake, "min_stake": minStake})
+	if err != nil {
+		return 0, err
+	}
+
+	weight, _ := out.(float64)
+	if weight < 0 {
+		weight = 0
+	}
+	return weight, nil
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter holds a token bucket per hotkey
+type RateLimiter struct {
+	entries map[string]*limiterEntry
+	mutex   sync.Mutex
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		entries: make(map[string]*limiterEntry),
+	}
+}
+
+// Allow takes a token from the hotkey's bucket, resizing the bucket if its limit
+// changed, and returns how long to wait when no token is available
+func (l *RateLimiter) Allow(hotkey string, rps float64, burst int) (bool, time.Duration) {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	entry, ok := l.entries[hotkey]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+		l.entries[hotkey] = entry
+	} else {
+		if entry.limiter.Limit() != rate.Limit(rps) {
+			entry.limiter.SetLimitAt(now, rate.Limit(rps))
+		}
+		if entry.limiter.Burst() != burst {
+			entry.limiter.SetBurstAt(now, burst)
+		}
+	}
+	entry.lastSeen = now
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// Cleanup drops buckets that have not been used for the given idle period
+func (l *RateLimiter) Cleanup(idle time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	cutoff := time.Now().Add(-idle)
+	for hotkey, entry := range l.entries {
+		if entry.lastSeen.Before(cutoff) {
+			delete(l.entries, hotkey)
+		}
+	}
+}
+
+func (l *RateLimiter) StartCleanupRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			l.Cleanup(interval)
+		}
+	}()
+}
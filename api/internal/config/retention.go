@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Payload retention tiers, from most to least retained
+const (
+	RetentionFull     = "full"
+	RetentionMetadata = "metadata"
+	RetentionNone     = "none"
+)
+
+var retentionRank = map[string]int{
+	RetentionFull:     0,
+	RetentionMetadata: 1,
+	RetentionNone:     2,
+}
+
+// RetentionPolicy decides how much of a verification payload is persisted
+type RetentionPolicy struct {
+	Default string
+	Models  map[string]string
+	Tiers   map[string]string
+}
+
+// Resolve returns the retention for a model and key tier. When both the model
+// and the tier are configured, the more restrictive setting wins.
+func (p RetentionPolicy) Resolve(model, tier string) string {
+	retention := p.Default
+	modelRetention, hasModel := p.Models[model]
+	tierRetention, hasTier := p.Tiers[tier]
+
+	switch {
+	case hasModel && hasTier:
+		retention = modelRetention
+		if retentionRank[tierRetention] > retentionRank[modelRetention] {
+			retention = tierRetention
+		}
+	case hasModel:
+		retention = modelRetention
+	case hasTier:
+		retention = tierRetention
+	}
+
+	return retention
+}
+
+func validRetention(value string) bool {
+	_, ok := retentionRank[value]
+	return ok
+}
+
+// parseRetentionMap reads a JSON object mapping a model or tier to a retention
+func parseRetentionMap(name, raw string) (map[string]string, error) {
+	values := make(map[string]string)
+	if raw == "" {
+		return values, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	for key, value := range values {
+		if !validRetention(value) {
+			return nil, fmt.Errorf("invalid %s: retention for %s must be full, metadata or none", name, key)
+		}
+	}
+
+	return values, nil
+}
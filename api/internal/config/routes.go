@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ModelRoute maps a model to the verifier backends that serve it. BackendURL
+// may hold a comma-separated list, which the proxy balances between.
+type ModelRoute struct {
+	Model         string              `json:"model"`
+	BackendURL    string              `json:"backend_url"`
+	Path          string              `json:"path"`
+	BackendServer string              `json:"backend_server"`
+	CauseRules    []CauseRule         `json:"cause_rules,omitempty"`
+	Maintenance   []MaintenanceWindow `json:"maintenance,omitempty"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// Backends returns the backend base URLs of the route
+func (r ModelRoute) Backends() []string {
+	var backends []string
+	for _, backend := range strings.Split(r.BackendURL, ",") {
+		if backend = strings.TrimSpace(backend); backend != "" {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
+
+// RouteTable is an in-memory copy of the model_routes table. Reload swaps in a
+// new map, so readers never block and never see a partial reload.
+type RouteTable struct {
+	routes atomic.Pointer[map[string]ModelRoute]
+}
+
+func NewRouteTable() *RouteTable {
+	t := &RouteTable{}
+	t.routes.Store(&map[string]ModelRoute{})
+	return t
+}
+
+func (t *RouteTable) snapshot() map[string]ModelRoute {
+	return *t.routes.Load()
+}
+
+// Lookup returns the route for a model
+func (t *RouteTable) Lookup(model string) (ModelRoute, bool) {
+	route, ok := t.snapshot()[model]
+	return route, ok
+}
+
+// Empty reports whether no routes are registered
+func (t *RouteTable) Empty() bool {
+	return len(t.snapshot()) == 0
+}
+
+// Models returns the registered model names in sorted order
+func (t *RouteTable) Models() []string {
+	routes := t.snapshot()
+	models := make([]string, 0, len(routes))
+	for model := range routes {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// All returns every registered route sorted by model
+func (t *RouteTable) All() []ModelRoute {
+	current := t.snapshot()
+	routes := make([]ModelRoute, 0, len(current))
+	for _, route := range current {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Model < routes[j].Model })
+	return routes
+}
+
+// Reload replaces the table contents with the rows in model_routes
+func (t *RouteTable) Reload(db *DB) error {
+	rows, err := db.Query("SELECT model, backend_url, path, backend_server, cause_rules, maintenance, updated_at FROM model_routes")
+	if err != nil {
+		return fmt.Errorf("failed to query model routes: %w", err)
+	}
+	defer rows.Close()
+
+	routes := make(map[string]ModelRoute)
+	for rows.Next() {
+		var route ModelRoute
+		var causeRules, maintenance []byte
+		if err := rows.Scan(&route.Model, &route.BackendURL, &route.Path, &route.BackendServer, &causeRules, &maintenance, &route.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to scan model route: %w", err)
+		}
+		if len(causeRules) > 0 {
+			if err := json.Unmarshal(causeRules, &route.CauseRules); err != nil {
+				return fmt.Errorf("invalid cause rules for %s: %w", route.Model, err)
+			}
+			if route.CauseRules, err = CompileCauseRules(route.CauseRules); err != nil {
+				return fmt.Errorf("invalid cause rules for %s: %w", route.Model, err)
+			}
+		}
+		if len(maintenance) > 0 {
+			if err := json.Unmarshal(maintenance, &route.Maintenance); err != nil {
+				return fmt.Errorf("invalid maintenance windows for %s: %w", route.Model, err)
+			}
+		}
+		routes[route.Model] = route
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read model routes: %w", err)
+	}
+
+	t.routes.Store(&routes)
+
+	return nil
+}
+
+// StartRefreshRoutine periodically reloads the table so changes made through
+// other replicas are picked up
+func (t *RouteTable) StartRefreshRoutine(db *DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := t.Reload(db); err != nil {
+				fmt.Printf("Warning: Failed to refresh model routes: %v\n", err)
+			}
+		}
+	}()
+}
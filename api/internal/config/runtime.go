@@ -0,0 +1,43 @@
+package config
+
+import "time"
+
+// Runtime holds the settings that may change while the proxy is running.
+// Handlers read them through Config.Runtime, which returns an immutable
+// snapshot, so a request sees one consistent set of settings even if they are
+// replaced halfway through it.
+type Runtime struct {
+	Policies      map[string]VerificationPolicy
+	Access        []AccessRule
+	Retention     RetentionPolicy
+	Quarantine    QuarantineSettings
+	Sanitize      SanitizeSettings
+	EpochQuota    int
+	DailyQuota    int
+	DedupWindow   time.Duration
+	ShadowPercent float64
+}
+
+// Runtime returns the current runtime settings. The snapshot must not be modified.
+func (c *Config) Runtime() *Runtime {
+	return c.runtime.Load()
+}
+
+// SetRuntime replaces the runtime settings
+func (c *Config) SetRuntime(r *Runtime) {
+	c.runtime.Store(r)
+}
+
+// UpdateRuntime applies fn to a copy of the current settings and swaps it in,
+// retrying if another update won the race. fn must replace rather than modify
+// the maps and slices it changes, as they are shared with the old snapshot.
+func (c *Config) UpdateRuntime(fn func(*Runtime)) {
+	for {
+		current := c.runtime.Load()
+		next := *current
+		fn(&next)
+		if c.runtime.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
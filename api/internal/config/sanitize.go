@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// SanitizeSettings controls how raw_chunks are scrubbed before they reach the
+// verifier backend
+type SanitizeSettings struct {
+	ControlChars  bool
+	InvalidUTF8   bool
+	MaxFieldBytes int64
+}
+
+// Enabled reports whether any sanitization is configured
+func (s SanitizeSettings) Enabled() bool {
+	return s.ControlChars || s.InvalidUTF8 || s.MaxFieldBytes > 0
+}
+
+// parseSanitizeSettings reads the chunk sanitization settings from the environment
+func parseSanitizeSettings() (SanitizeSettings, []error) {
+	var errs []error
+
+	settings := SanitizeSettings{
+		ControlChars: strings.ToLower(getEnv("SANITIZE_CONTROL_CHARS", "true")) == "true",
+		InvalidUTF8:  strings.ToLower(getEnv("SANITIZE_INVALID_UTF8", "true")) == "true",
+	}
+
+	maxFieldBytes, err := bytes.Parse(getEnv("SANITIZE_MAX_FIELD_SIZE", "0"))
+	if err != nil || maxFieldBytes < 0 {
+		errs = append(errs, fmt.Errorf("invalid SANITIZE_MAX_FIELD_SIZE: must be a size such as 64K, or 0 to disable"))
+	}
+	settings.MaxFieldBytes = maxFieldBytes
+
+	return settings, errs
+}
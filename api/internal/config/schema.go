@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var createTablePattern = regexp.MustCompile(`(?is)CREATE TABLE(?: IF NOT EXISTS)?\s+` + "`?" + `(\w+)` + "`?" + `\s*\((.*?)\n\);`)
+
+// ParseSchema returns the columns of every table created by a DDL script
+func ParseSchema(ddl string) map[string][]string {
+	tables := make(map[string][]string)
+	for _, match := range createTablePattern.FindAllStringSubmatch(ddl, -1) {
+		var columns []string
+		for _, line := range strings.Split(match[2], "\n") {
+			fields := strings.Fields(strings.TrimSpace(line))
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "--") {
+				continue
+			}
+			switch strings.ToUpper(fields[0]) {
+			case "INDEX", "KEY", "PRIMARY", "UNIQUE", "CONSTRAINT", "FOREIGN":
+				continue
+			}
+			columns = append(columns, strings.Trim(fields[0], "`"))
+		}
+		tables[match[1]] = columns
+	}
+	return tables
+}
+
+// columnDefinition is a column name and its full definition from a CREATE TABLE
+type columnDefinition struct {
+	Name       string
+	Definition string
+}
+
+// parseColumnDefinitions returns the column definitions of every table created by a DDL script
+func parseColumnDefinitions(ddl string) map[string][]columnDefinition {
+	tables := make(map[string][]columnDefinition)
+	for _, match := range createTablePattern.FindAllStringSubmatch(ddl, -1) {
+		var columns []columnDefinition
+		for _, line := range strings.Split(match[2], "\n") {
+			line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+			fields := strings.Fields(line)
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "--") {
+				continue
+			}
+			switch strings.ToUpper(fields[0]) {
+			case "INDEX", "KEY", "PRIMARY", "UNIQUE", "CONSTRAINT", "FOREIGN":
+				continue
+			}
+			columns = append(columns, columnDefinition{Name: strings.Trim(fields[0], "`"), Definition: line})
+		}
+		tables[match[1]] = columns
+	}
+	return tables
+}
+
+// CheckSchema compares the live database against the tables and columns in ddl,
+// returning one error per missing table or column
+func CheckSchema(db *DB, ddl string) ([]error, error) {
+	live, err := db.Dialect.liveColumns(context.Background(), db.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := ParseSchema(ddl)
+	tables := make([]string, 0, len(expected))
+	for table := range expected {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var drift []error
+	for _, table := range tables {
+		columns, ok := live[table]
+		if !ok {
+			drift = append(drift, fmt.Errorf("table %s is missing", table))
+			continue
+		}
+		for _, column := range expected[table] {
+			if !columns[column] {
+				drift = append(drift, fmt.Errorf("table %s is missing column %s", table, column))
+			}
+		}
+	}
+
+	return drift, nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scopes grant a key access to a group of endpoints. ScopeAll grants every
+// scope and is what administrator keys hold.
+const (
+	ScopeAll        = "*"
+	ScopeVerify     = "verify"
+	ScopeKeysRead   = "keys:read"
+	ScopeKeysWrite  = "keys:write"
+	ScopeCacheAdmin = "cache:admin"
+	ScopeConsensus  = "verify:consensus"
+)
+
+// KnownScopes lists the scopes that can be granted
+var KnownScopes = []string{ScopeAll, ScopeVerify, ScopeKeysRead, ScopeKeysWrite, ScopeCacheAdmin, ScopeConsensus}
+
+// ParseScopes splits the stored comma-separated scopes column
+func ParseScopes(value string) []string {
+	return splitList(value)
+}
+
+// FormatScopes joins scopes for storage, sorted and without duplicates
+func FormatScopes(scopes []string) string {
+	sorted := slices.Clone(scopes)
+	slices.Sort(sorted)
+	return strings.Join(slices.Compact(sorted), ",")
+}
+
+// ValidateScopes checks that every scope is one that can be granted
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !slices.Contains(KnownScopes, scope) {
+			return fmt.Errorf("unknown scope %q, must be one of %s", scope, strings.Join(KnownScopes, ", "))
+		}
+	}
+	return nil
+}
+
+// HasScope reports whether the key holds scope, directly or through ScopeAll
+func (k KeyInfo) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, ScopeAll) || slices.Contains(k.Scopes, scope)
+}
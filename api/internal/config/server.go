@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// ServerSettings configures the HTTP listener
+type ServerSettings struct {
+	ListenAddr   string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	MaxBodySize  string
+	TLS          TLSSettings
+	// GzipMinLength is the smallest response compressed for clients that
+	// accept gzip; a negative value turns response compression off
+	GzipMinLength int
+	// TrustedProxies are the peers whose X-Forwarded-For entries are believed
+	// when resolving the client address; empty trusts loopback and private ranges
+	TrustedProxies []*net.IPNet
+}
+
+// NormalizeCIDRs validates a list of CIDRs, returning them in canonical form
+func NormalizeCIDRs(entries []string) ([]string, error) {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a CIDR such as 10.0.0.0/8", entry)
+		}
+		normalized = append(normalized, network.String())
+	}
+	return normalized, nil
+}
+
+// BackendTimeout returns the backend request timeout for a model
+func (e Environment) BackendTimeout(model string) time.Duration {
+	if timeout, ok := e.BackendModelTimeouts[model]; ok {
+		return timeout
+	}
+	return e.BackendDefaultTimeout
+}
+
+// parseTimeoutMap reads a JSON object mapping a model to a duration string
+func parseTimeoutMap(name, raw string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	if raw == "" {
+		return timeouts, nil
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	for model, value := range values {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid %s: timeout for %s must be a positive duration", name, model)
+		}
+		timeouts[model] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// parseServerSettings reads the listener settings from the environment
+func parseServerSettings() (ServerSettings, []error) {
+	var errs []error
+
+	settings := ServerSettings{
+		ListenAddr:  getEnv("LISTEN_ADDR", ":80"),
+		MaxBodySize: getEnv("MAX_BODY_SIZE", "32M"),
+	}
+
+	for _, d := range []struct {
+		name     string
+		fallback string
+		target   *time.Duration
+	}{
+		{"SERVER_READ_TIMEOUT", "30s", &settings.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", "150s", &settings.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", "120s", &settings.IdleTimeout},
+	} {
+		value, err := time.ParseDuration(getEnv(d.name, d.fallback))
+		if err != nil || value < 0 {
+			errs = append(errs, fmt.Errorf("invalid %s: must be a non-negative duration", d.name))
+		}
+		*d.target = value
+	}
+
+	gzipMinLength, err := strconv.Atoi(getEnv("GZIP_MIN_LENGTH", "1024"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid GZIP_MIN_LENGTH: must be an integer"))
+	}
+	settings.GzipMinLength = gzipMinLength
+
+	proxies, err := NormalizeCIDRs(splitList(getEnv("TRUSTED_PROXIES", "")))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err))
+	}
+	for _, proxy := range proxies {
+		_, network, _ := net.ParseCIDR(proxy)
+		settings.TrustedProxies = append(settings.TrustedProxies, network)
+	}
+
+	if size, err := bytes.Parse(settings.MaxBodySize); err != nil || size <= 0 {
+		errs = append(errs, fmt.Errorf("invalid MAX_BODY_SIZE: must be a size such as 32M"))
+	}
+
+	tlsSettings, tlsErrs := parseTLSSettings()
+	settings.TLS = tlsSettings
+	errs = append(errs, tlsErrs...)
+
+	return settings, errs
+}
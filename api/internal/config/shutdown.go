@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ShutdownReport summarises a graceful shutdown, so operators can tell whether
+// a deploy lost work
+type ShutdownReport struct {
+	Instance  string    `json:"instance"`
+	StartedAt time.Time `json:"started_at"`
+	StoppedAt time.Time `json:"stopped_at"`
+	Uptime    string    `json:"uptime"`
+
+	RequestsTotal  int64 `json:"requests_total"`
+	RequestsFailed int64 `json:"requests_failed"`
+
+	// Requests in flight when shutdown began, those that finished while
+	// draining and those still running when the drain timeout cut them off
+	InflightAtShutdown int64  `json:"inflight_at_shutdown"`
+	Drained            int64  `json:"drained"`
+	Dropped            int64  `json:"dropped"`
+	DrainDuration      string `json:"drain_duration"`
+
+	// Async jobs still queued are persisted and re-driven on the next start;
+	// queued analytics mirror events are lost
+	JobsPending   int `json:"jobs_pending"`
+	MirrorDropped int `json:"mirror_dropped"`
+
+	KeyUsageFlushed int      `json:"key_usage_flushed"`
+	UsageFlushed    int      `json:"usage_flushed"`
+	FlushErrors     []string `json:"flush_errors,omitempty"`
+}
+
+// FlushWrites writes out buffered key and usage updates, reporting how many
+// were written and what failed
+func (c *Config) FlushWrites(report *ShutdownReport) {
+	if c.Keys != nil {
+		pending := c.Keys.Pending()
+		if err := c.Keys.Flush(); err != nil {
+			report.FlushErrors = append(report.FlushErrors, err.Error())
+		}
+		report.KeyUsageFlushed = pending - c.Keys.Pending()
+	}
+	if c.Usage != nil {
+		pending := c.Usage.Pending()
+		if err := c.Usage.Flush(); err != nil {
+			report.FlushErrors = append(report.FlushErrors, err.Error())
+		}
+		report.UsageFlushed = pending - c.Usage.Pending()
+	}
+}
+
+// parseShutdownReportURL reads where shutdown reports are posted, if anywhere
+func parseShutdownReportURL() (string, error) {
+	raw := getEnv("SHUTDOWN_REPORT_URL", "")
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid SHUTDOWN_REPORT_URL: must be an http(s) URL")
+	}
+	return raw, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/bytes"
+)
+
+// SoakSettings controls the soak-test mode, in which the proxy drives its own
+// verification pipeline with synthetic load against the mock backend and
+// watches goroutine and heap growth for leaks. The warmup should outlast the
+// longest cache TTL so the baseline is taken once caches have filled.
+type SoakSettings struct {
+	Enabled            bool
+	RPS                float64
+	Concurrency        int
+	Warmup             time.Duration
+	SampleInterval     time.Duration
+	MaxGoroutineGrowth int
+	MaxHeapGrowth      int64
+}
+
+// parseSoakSettings reads the soak-test settings from the environment. Soak
+// mode requires the mock backend so it never sends load to a real verifier.
+func parseSoakSettings(mock MockSettings) (SoakSettings, []error) {
+	var errs []error
+
+	settings := SoakSettings{
+		Enabled: strings.ToLower(getEnv("SOAK_TEST", "false")) == "true",
+	}
+	if settings.Enabled && !mock.Enabled {
+		errs = append(errs, fmt.Errorf("invalid SOAK_TEST: requires MOCK_BACKEND=true"))
+	}
+
+	rps, err := strconv.ParseFloat(getEnv("SOAK_RPS", "50"), 64)
+	if err != nil || rps <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SOAK_RPS: must be a positive number"))
+	}
+	settings.RPS = rps
+
+	concurrency, err := strconv.Atoi(getEnv("SOAK_CONCURRENCY", "32"))
+	if err != nil || concurrency <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SOAK_CONCURRENCY: must be a positive integer"))
+	}
+	settings.Concurrency = concurrency
+
+	warmup, err := time.ParseDuration(getEnv("SOAK_WARMUP", "90m"))
+	if err != nil || warmup < 0 {
+		errs = append(errs, fmt.Errorf("invalid SOAK_WARMUP: must be a non-negative duration"))
+	}
+	settings.Warmup = warmup
+
+	sampleInterval, err := time.ParseDuration(getEnv("SOAK_SAMPLE_INTERVAL", "1m"))
+	if err != nil || sampleInterval <= 0 {
+		errs = append(errs, fmt.Errorf("invalid SOAK_SAMPLE_INTERVAL: must be a positive duration"))
+	}
+	settings.SampleInterval = sampleInterval
+
+	maxGoroutineGrowth, err := strconv.Atoi(getEnv("SOAK_MAX_GOROUTINE_GROWTH", "100"))
+	if err != nil || maxGoroutineGrowth < 0 {
+		errs = append(errs, fmt.Errorf("invalid SOAK_MAX_GOROUTINE_GROWTH: must be a non-negative integer"))
+	}
+	settings.MaxGoroutineGrowth = maxGoroutineGrowth
+
+	maxHeapGrowth, err := bytes.Parse(getEnv("SOAK_MAX_HEAP_GROWTH", "64M"))
+	if err != nil || maxHeapGrowth < 0 {
+		errs = append(errs, fmt.Errorf("invalid SOAK_MAX_HEAP_GROWTH: must be a size such as 64M"))
+	}
+	settings.MaxHeapGrowth = maxHeapGrowth
+
+	return settings, errs
+}
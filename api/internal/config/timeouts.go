@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api/internal/metrics"
+)
+
+// AdaptiveTimeoutSettings derive a model's backend timeout from its recent
+// latencies: Factor times the Percentile latency of the last Window successful
+// calls, never below Min nor above the model's configured timeout
+type AdaptiveTimeoutSettings struct {
+	Enabled    bool
+	Percentile float64
+	Factor     float64
+	Min        time.Duration
+	Window     int
+	MinSamples int
+}
+
+// parseAdaptiveTimeoutSettings reads the adaptive timeout settings from the environment
+func parseAdaptiveTimeoutSettings() (AdaptiveTimeoutSettings, []error) {
+	var errs []error
+
+	settings := AdaptiveTimeoutSettings{
+		Enabled: strings.ToLower(getEnv("BACKEND_ADAPTIVE_TIMEOUT", "false")) == "true",
+	}
+
+	var err error
+	settings.Percentile, err = strconv.ParseFloat(getEnv("BACKEND_ADAPTIVE_PERCENTILE", "0.99"), 64)
+	if err != nil || settings.Percentile <= 0 || settings.Percentile > 1 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_ADAPTIVE_PERCENTILE: must be in (0, 1]"))
+	}
+	settings.Factor, err = strconv.ParseFloat(getEnv("BACKEND_ADAPTIVE_FACTOR", "2"), 64)
+	if err != nil || settings.Factor < 1 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_ADAPTIVE_FACTOR: must be at least 1"))
+	}
+	settings.Min, err = time.ParseDuration(getEnv("BACKEND_ADAPTIVE_MIN", "5s"))
+	if err != nil || settings.Min <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_ADAPTIVE_MIN: must be a positive duration"))
+	}
+	settings.Window, err = strconv.Atoi(getEnv("BACKEND_ADAPTIVE_WINDOW", "500"))
+	if err != nil || settings.Window <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_ADAPTIVE_WINDOW: must be a positive integer"))
+	}
+	settings.MinSamples, err = strconv.Atoi(getEnv("BACKEND_ADAPTIVE_MIN_SAMPLES", "50"))
+	if err != nil || settings.MinSamples <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_ADAPTIVE_MIN_SAMPLES: must be a positive integer"))
+	}
+
+	return settings, errs
+}
+
+// latencyWindow is a ring of a model's most recent backend latencies
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// AdaptiveTimeouts tracks backend latencies per model and picks timeouts from them
+type AdaptiveTimeouts struct {
+	settings AdaptiveTimeoutSettings
+	models   map[string]*latencyWindow
+	mutex    sync.Mutex
+}
+
+func NewAdaptiveTimeouts(settings AdaptiveTimeoutSettings) *AdaptiveTimeouts {
+	return &AdaptiveTimeouts{
+		settings: settings,
+		models:   make(map[string]*latencyWindow),
+	}
+}
+
+// Observe records the latency of a successful backend call
+func (a *AdaptiveTimeouts) Observe(model string, latency time.Duration) {
+	if !a.settings.Enabled {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	window, ok := a.models[model]
+	if !ok {
+		window = &latencyWindow{}
+		a.models[model] = window
+	}
+	if len(window.samples) < a.settings.Window {
+		window.samples = append(window.samples, latency)
+		return
+	}
+	window.samples[window.next] = latency
+	window.next = (window.next + 1) % a.settings.Window
+}
+
+// Timeout returns the backend timeout for a model whose configured timeout is
+// ceiling. Until enough latencies are recorded, the ceiling is used as is.
+func (a *AdaptiveTimeouts) Timeout(model string, ceiling time.Duration) time.Duration {
+	if !a.settings.Enabled {
+		return ceiling
+	}
+
+	a.mutex.Lock()
+	window, ok := a.models[model]
+	if !ok || len(window.samples) < a.settings.MinSamples {
+		a.mutex.Unlock()
+		return ceiling
+	}
+	sorted := slices.Clone(window.samples)
+	a.mutex.Unlock()
+
+	slices.Sort(sorted)
+	index := min(int(float64(len(sorted))*a.settings.Percentile), len(sorted)-1)
+	timeout := time.Duration(float64(sorted[index]) * a.settings.Factor)
+	timeout = min(max(timeout, a.settings.Min), ceiling)
+
+	metrics.BackendTimeout.WithLabelValues(model).Set(timeout.Seconds())
+	return timeout
+}
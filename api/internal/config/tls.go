@@ -0,0 +1,66 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+// TLSSettings configures TLS termination by the proxy itself, for deployments
+// without a load balancer in front of it
+type TLSSettings struct {
+	CertFile string
+	KeyFile  string
+	// AutocertHosts are the hostnames certificates are requested for from
+	// Let's Encrypt; setting them enables ACME instead of CertFile and KeyFile
+	AutocertHosts    []string
+	AutocertCacheDir string
+	AutocertEmail    string
+	// RedirectAddr is where plain HTTP is redirected to HTTPS, empty to disable
+	RedirectAddr string
+}
+
+// Enabled reports whether the proxy terminates TLS
+func (t TLSSettings) Enabled() bool {
+	return t.CertFile != "" || len(t.AutocertHosts) > 0
+}
+
+// Autocert reports whether certificates are obtained through ACME
+func (t TLSSettings) Autocert() bool {
+	return len(t.AutocertHosts) > 0
+}
+
+// parseTLSSettings reads the TLS settings from the environment
+func parseTLSSettings() (TLSSettings, []error) {
+	var errs []error
+
+	settings := TLSSettings{
+		CertFile:         getEnv("TLS_CERT_FILE", ""),
+		KeyFile:          getEnv("TLS_KEY_FILE", ""),
+		AutocertHosts:    splitList(getEnv("TLS_AUTOCERT_HOSTS", "")),
+		AutocertCacheDir: getEnv("TLS_AUTOCERT_CACHE_DIR", "autocert-cache"),
+		AutocertEmail:    getEnv("TLS_AUTOCERT_EMAIL", ""),
+		RedirectAddr:     getEnv("TLS_REDIRECT_ADDR", ""),
+	}
+
+	switch {
+	case settings.Autocert() && (settings.CertFile != "" || settings.KeyFile != ""):
+		errs = append(errs, fmt.Errorf("invalid TLS_AUTOCERT_HOSTS: cannot be combined with TLS_CERT_FILE and TLS_KEY_FILE"))
+	case (settings.CertFile == "") != (settings.KeyFile == ""):
+		errs = append(errs, fmt.Errorf("invalid TLS_CERT_FILE: TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	case settings.CertFile != "":
+		if _, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile); err != nil {
+			errs = append(errs, fmt.Errorf("invalid TLS_CERT_FILE: %w", err))
+		}
+	}
+
+	if settings.RedirectAddr != "" {
+		if !settings.Enabled() {
+			errs = append(errs, fmt.Errorf("invalid TLS_REDIRECT_ADDR: requires TLS_CERT_FILE or TLS_AUTOCERT_HOSTS"))
+		} else if _, _, err := net.SplitHostPort(settings.RedirectAddr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid TLS_REDIRECT_ADDR: %w", err))
+		}
+	}
+
+	return settings, errs
+}
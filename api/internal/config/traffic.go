@@ -0,0 +1,43 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Traffic counts the HTTP requests served since startup, for the shutdown report
+type Traffic struct {
+	StartedAt time.Time
+
+	inflight atomic.Int64
+	total    atomic.Int64
+	failed   atomic.Int64
+}
+
+func NewTraffic() *Traffic {
+	return &Traffic{StartedAt: time.Now()}
+}
+
+// Begin records a request starting
+func (t *Traffic) Begin() {
+	t.inflight.Add(1)
+	t.total.Add(1)
+}
+
+// End records a request finishing with status
+func (t *Traffic) End(status int) {
+	t.inflight.Add(-1)
+	if status >= 500 {
+		t.failed.Add(1)
+	}
+}
+
+// Inflight returns the number of requests being handled
+func (t *Traffic) Inflight() int64 {
+	return t.inflight.Load()
+}
+
+// Total returns the number of requests received and how many failed with a server error
+func (t *Traffic) Total() (int64, int64) {
+	return t.total.Load(), t.failed.Load()
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"api/internal/metrics"
+)
+
+// BackendPoolSettings tune the backend connection pool. MaxPerHost of 0 leaves
+// connections per backend unbounded; HTTP/2 is only negotiated over TLS.
+type BackendPoolSettings struct {
+	MaxIdlePerHost int
+	MaxPerHost     int
+	IdleTimeout    time.Duration
+	HTTP2          bool
+}
+
+// parseBackendPoolSettings reads the backend connection pool settings from the environment
+func parseBackendPoolSettings() (BackendPoolSettings, []error) {
+	var errs []error
+
+	settings := BackendPoolSettings{
+		HTTP2: strings.ToLower(getEnv("BACKEND_HTTP2", "true")) == "true",
+	}
+
+	var err error
+	settings.MaxIdlePerHost, err = strconv.Atoi(getEnv("BACKEND_MAX_IDLE_CONNS", "64"))
+	if err != nil || settings.MaxIdlePerHost <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_MAX_IDLE_CONNS: must be a positive integer"))
+	}
+	settings.MaxPerHost, err = strconv.Atoi(getEnv("BACKEND_MAX_CONNS_PER_HOST", "0"))
+	if err != nil || settings.MaxPerHost < 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_MAX_CONNS_PER_HOST: must be a non-negative integer"))
+	}
+	settings.IdleTimeout, err = time.ParseDuration(getEnv("BACKEND_IDLE_CONN_TIMEOUT", "90s"))
+	if err != nil || settings.IdleTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("invalid BACKEND_IDLE_CONN_TIMEOUT: must be a positive duration"))
+	}
+
+	return settings, errs
+}
+
+// poolTrace counts whether each backend call got a pooled or a fresh connection
+var poolTrace = &httptrace.ClientTrace{
+	GotConn: func(info httptrace.GotConnInfo) {
+		metrics.BackendConnections.WithLabelValues(strconv.FormatBool(info.Reused)).Inc()
+	},
+}
+
+// countedConn tracks a backend connection in the open connections gauge until it is closed
+type countedConn struct {
+	net.Conn
+	addr string
+	once sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() { metrics.BackendOpenConnections.WithLabelValues(c.addr).Dec() })
+	return c.Conn.Close()
+}
+
+// BackendTransport is the connection pool shared by all calls to verifier
+// backends. It remembers what each backend hostname resolved to so that
+// pooled connections can be rotated when DNS moves a backend elsewhere, and
+// presents the proxy's backend credentials on every call.
+type BackendTransport struct {
+	*http.Transport
+
+	auth       BackendAuthSettings
+	mutex      sync.Mutex
+	addrs      map[string][]string
+	drainUntil time.Time
+}
+
+func NewBackendTransport(outbound OutboundSettings, auth BackendAuthSettings, pool BackendPoolSettings) *BackendTransport {
+	transport := outbound.Transport()
+	transport.MaxIdleConnsPerHost = pool.MaxIdlePerHost
+	transport.MaxIdleConns = 0
+	transport.MaxConnsPerHost = pool.MaxPerHost
+	transport.IdleConnTimeout = pool.IdleTimeout
+	if !pool.HTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	auth.Apply(transport)
+
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		metrics.BackendOpenConnections.WithLabelValues(addr).Inc()
+		return &countedConn{Conn: conn, addr: addr}, nil
+	}
+
+	return &BackendTransport{
+		Transport: transport,
+		auth:      auth,
+		addrs:     make(map[string][]string),
+	}
+}
+
+// RoundTrip sends a backend request with the service token added
+func (t *BackendTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(httptrace.WithClientTrace(req.Context(), poolTrace))
+	t.auth.SetToken(req.Header)
+	return t.Transport.RoundTrip(req)
+}
+
+// Refresh re-resolves the given hostnames and returns those whose addresses
+// changed since the last refresh. Idle connections are closed on a change and
+// again on every refresh until drain has passed, so connections that were in
+// flight during the change are not reused once they return to the pool.
+func (t *BackendTransport) Refresh(ctx context.Context, hosts []string, drain time.Duration) []string {
+	var changed []string
+	resolved := make(map[string][]string, len(hosts))
+	for _, host := range hosts {
+		if net.ParseIP(host) != nil {
+			continue
+		}
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err != nil || len(addrs) == 0 {
+			// Keep using the old connections rather than dropping them on a lookup failure
+			continue
+		}
+		slices.Sort(addrs)
+		resolved[host] = addrs
+	}
+
+	t.mutex.Lock()
+	for host, addrs := range resolved {
+		previous, seen := t.addrs[host]
+		if seen && !slices.Equal(previous, addrs) {
+			changed = append(changed, host)
+		}
+		t.addrs[host] = addrs
+	}
+	if len(changed) > 0 {
+		t.drainUntil = time.Now().Add(drain)
+	}
+	draining := time.Now().Before(t.drainUntil)
+	t.mutex.Unlock()
+
+	if len(changed) > 0 || draining {
+		t.CloseIdleConnections()
+	}
+
+	slices.Sort(changed)
+	return changed
+}
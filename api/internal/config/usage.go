@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type usageKey struct {
+	hotkey string
+	day    string
+	model  string
+}
+
+type usageDelta struct {
+	verifications  int64
+	verified       int64
+	inputTokens    int64
+	responseTokens int64
+}
+
+type hourKey struct {
+	hotkey string
+	hour   string
+}
+
+type dailyCount struct {
+	day   string
+	count int64
+}
+
+// UsageTracker accumulates per-hotkey usage in memory and writes it to the
+// key_usage and key_usage_hourly tables in batches, keeping the database out
+// of the request path
+type UsageTracker struct {
+	db      *DB
+	pending map[usageKey]*usageDelta
+	hourly  map[hourKey]int64
+	today   map[string]*dailyCount
+	mutex   sync.Mutex
+}
+
+func NewUsageTracker(db *DB) *UsageTracker {
+	return &UsageTracker{
+		db:      db,
+		pending: make(map[usageKey]*usageDelta),
+		hourly:  make(map[hourKey]int64),
+		today:   make(map[string]*dailyCount),
+	}
+}
+
+// usageHourFormat buckets hourly usage as text, so the buckets compare and
+// sort the same way in every database
+const usageHourFormat = "2006-01-02T15"
+
+// usageDay returns the UTC day usage is bucketed under
+func usageDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// usageHour returns the UTC hour usage is bucketed under
+func usageHour(t time.Time) string {
+	return t.UTC().Format(usageHourFormat)
+}
+
+// Record counts a served verification against the hotkey's usage
+func (u *UsageTracker) Record(hotkey, model string, verified bool, inputTokens, responseTokens int64) {
+	now := time.Now()
+	day := usageDay(now)
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	key := usageKey{hotkey: hotkey, day: day, model: model}
+	delta, ok := u.pending[key]
+	if !ok {
+		delta = &usageDelta{}
+		u.pending[key] = delta
+	}
+	delta.verifications++
+	if verified {
+		delta.verified++
+	}
+	delta.inputTokens += inputTokens
+	delta.responseTokens += responseTokens
+	u.hourly[hourKey{hotkey: hotkey, hour: usageHour(now)}]++
+
+	if count, ok := u.today[hotkey]; ok && count.day == day {
+		count.count++
+	}
+}
+
+// Today returns the number of verifications the hotkey has used today. The
+// stored count is read once per hotkey and day and then tracked in memory.
+func (u *UsageTracker) Today(hotkey string) (int64, error) {
+	day := usageDay(time.Now())
+
+	u.mutex.Lock()
+	if count, ok := u.today[hotkey]; ok && count.day == day {
+		u.mutex.Unlock()
+		return count.count, nil
+	}
+	u.mutex.Unlock()
+
+	var stored int64
+	err := u.db.QueryRow(
+		"SELECT COALESCE(SUM(verifications), 0) FROM key_usage WHERE hotkey = ? AND day = ?",
+		hotkey, day,
+	).Scan(&stored)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	// Usage recorded since the last flush is not in the stored total yet
+	for key, delta := range u.pending {
+		if key.hotkey == hotkey && key.day == day {
+			stored += delta.verifications
+		}
+	}
+	u.today[hotkey] = &dailyCount{day: day, count: stored}
+	return stored, nil
+}
+
+// Flush writes the accumulated usage to the database. Deltas that fail to
+// write are kept for the next flush.
+func (u *UsageTracker) Flush() error {
+	u.mutex.Lock()
+	pending := u.pending
+	u.pending = make(map[usageKey]*usageDelta)
+	hourly := u.hourly
+	u.hourly = make(map[hourKey]int64)
+
+	// Drop daily counters from previous days
+	day := usageDay(time.Now())
+	for hotkey, count := range u.today {
+		if count.day != day {
+			delete(u.today, hotkey)
+		}
+	}
+	u.mutex.Unlock()
+
+	var firstErr error
+	for key, delta := range pending {
+		_, err := u.db.Exec(
+			"INSERT INTO key_usage (hotkey, day, model, verifications, verified, input_tokens, response_tokens) VALUES (?, ?, ?, ?, ?, ?, ?) "+
+				u.db.Dialect.Upsert("key_usage", []string{"hotkey", "day", "model"}, nil,
+					[]string{"verifications", "verified", "input_tokens", "response_tokens"}),
+			key.hotkey, key.day, key.model, delta.verifications, delta.verified, delta.inputTokens, delta.responseTokens,
+		)
+		if err != nil {
+			u.restore(key, delta)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to flush usage: %w", err)
+			}
+		}
+	}
+	for key, count := range hourly {
+		_, err := u.db.Exec(
+			"INSERT INTO key_usage_hourly (hotkey, hour, verifications) VALUES (?, ?, ?) "+
+				u.db.Dialect.Upsert("key_usage_hourly", []string{"hotkey", "hour"}, nil, []string{"verifications"}),
+			key.hotkey, key.hour, count,
+		)
+		if err != nil {
+			u.mutex.Lock()
+			u.hourly[key] += count
+			u.mutex.Unlock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to flush hourly usage: %w", err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// Hourly returns the hotkey's verification counts per UTC hour since the given
+// time, keyed by the hour's start. Usage not flushed yet is included.
+func (u *UsageTracker) Hourly(hotkey string, since time.Time) (map[time.Time]int64, error) {
+	rows, err := u.db.Query(
+		"SELECT hour, verifications FROM key_usage_hourly WHERE hotkey = ? AND hour >= ?",
+		hotkey, usageHour(since),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hourly usage: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var hour string
+		var count int64
+		if err := rows.Scan(&hour, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan hourly usage: %w", err)
+		}
+		counts[hour] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hourly usage: %w", err)
+	}
+
+	u.mutex.Lock()
+	for key, count := range u.hourly {
+		if key.hotkey == hotkey {
+			counts[key.hour] += count
+		}
+	}
+	u.mutex.Unlock()
+
+	hours := make(map[time.Time]int64, len(counts))
+	for hour, count := range counts {
+		t, err := time.Parse(usageHourFormat, hour)
+		if err != nil || t.Before(since.UTC().Truncate(time.Hour)) {
+			continue
+		}
+		hours[t] = count
+	}
+	return hours, nil
+}
+
+// Pending returns the number of usage counters waiting to be flushed
+func (u *UsageTracker) Pending() int {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	return len(u.pending) + len(u.hourly)
+}
+
+func (u *UsageTracker) restore(key usageKey, delta *usageDelta) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	existing, ok := u.pending[key]
+	if !ok {
+		u.pending[key] = delta
+		return
+	}
+	existing.verifications += delta.verifications
+	existing.verified += delta.verified
+	existing.inputTokens += delta.inputTokens
+	existing.responseTokens += delta.responseTokens
+}
+
+// StartFlushRoutine periodically writes accumulated usage to the database
+func (u *UsageTracker) StartFlushRoutine(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := u.Flush(); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend version states relative to the minimum supported version
+const (
+	VersionOK           = "ok"
+	VersionUnknown      = "unknown"
+	VersionIncompatible = "incompatible"
+)
+
+// BackendVersion is the last verifier version reported by a backend
+type BackendVersion struct {
+	Backend  string    `json:"backend"`
+	Version  string    `json:"version,omitempty"`
+	Status   string    `json:"status"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// VersionTracker records backend versions and checks them against MinVersion
+type VersionTracker struct {
+	MinVersion string
+	Enforce    bool
+
+	backends map[string]BackendVersion
+	mutex    sync.RWMutex
+}
+
+func NewVersionTracker(minVersion string, enforce bool) *VersionTracker {
+	return &VersionTracker{
+		MinVersion: minVersion,
+		Enforce:    enforce,
+		backends:   make(map[string]BackendVersion),
+	}
+}
+
+// Observe records the version a backend reported and returns its state
+func (t *VersionTracker) Observe(backend, version string) BackendVersion {
+	state := BackendVersion{
+		Backend:  backend,
+		Version:  version,
+		Status:   t.check(version),
+		LastSeen: time.Now(),
+	}
+
+	t.mutex.Lock()
+	t.backends[backend] = state
+	t.mutex.Unlock()
+
+	return state
+}
+
+func (t *VersionTracker) check(version string) string {
+	if t.MinVersion == "" {
+		return VersionOK
+	}
+	if version == "" {
+		return VersionUnknown
+	}
+
+	cmp, err := CompareVersions(version, t.MinVersion)
+	if err != nil {
+		return VersionUnknown
+	}
+	if cmp < 0 {
+		return VersionIncompatible
+	}
+	return VersionOK
+}
+
+// All returns every observed backend sorted by URL
+func (t *VersionTracker) All() []BackendVersion {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	backends := make([]BackendVersion, 0, len(t.backends))
+	for _, b := range t.backends {
+		backends = append(backends, b)
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Backend < backends[j].Backend })
+	return backends
+}
+
+// CompareVersions compares two dotted versions such as v1.4.2, ignoring any
+// pre-release or build suffix. Missing components count as zero.
+func CompareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < 3; i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(v string) ([3]int, error) {
+	var parts [3]int
+
+	core := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 || core == "" {
+		return parts, fmt.Errorf("invalid version %q", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version %q", v)
+		}
+		parts[i] = n
+	}
+
+	return parts, nil
+}
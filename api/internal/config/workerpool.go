@@ -0,0 +1,346 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"api/internal/metrics"
+)
+
+// ErrBackendSaturated is returned when a backend call cannot get a worker,
+// either because the queue is full or the queue timeout passed
+var ErrBackendSaturated = errors.New("backend concurrency limit reached")
+
+// ErrDeadlinePassed is returned when a call's deadline passes before a worker
+// picks it up
+var ErrDeadlinePassed = errors.New("verification deadline passed")
+
+// defaultPool names the pool serving models without a pool of their own
+const defaultPool = "default"
+
+// BackendCall describes a backend call submitted to the worker pool
+type BackendCall struct {
+	Model string
+	// Deadline orders the call among queued calls; zero means none
+	Deadline time.Time
+	// Reserved lets the call queue in capacity reserved for expected requests
+	Reserved bool
+	// Priority is the call's level; higher levels are served first
+	Priority int
+}
+
+// queuedCall is a backend call waiting for, or being run by, a worker
+type queuedCall struct {
+	priority int
+	deadline time.Time
+	seq      uint64
+	queuedAt time.Time
+	run      func() ([]byte, error)
+	done     chan struct{}
+
+	body     []byte
+	err      error
+	panicked any
+}
+
+// level is the call's priority raised by one for every aging interval it has
+// waited, so that a stream of higher priority calls cannot starve it
+func (c *queuedCall) level(now time.Time, aging time.Duration) int {
+	return c.priority + int(now.Sub(c.queuedAt)/aging)
+}
+
+// before orders calls by aged priority, then earliest deadline first, with
+// calls without a deadline after those with one and ties served in arrival order
+func (c *queuedCall) before(other *queuedCall, now time.Time, aging time.Duration) bool {
+	if level, otherLevel := c.level(now, aging), other.level(now, aging); level != otherLevel {
+		return level > otherLevel
+	}
+	switch {
+	case c.deadline.IsZero() != other.deadline.IsZero():
+		return !c.deadline.IsZero()
+	case !c.deadline.Equal(other.deadline):
+		return c.deadline.Before(other.deadline)
+	default:
+		return c.seq < other.seq
+	}
+}
+
+// result returns what the call's worker produced, re-raising a panic in the
+// submitting goroutine so the request's recovery handles it
+func (c *queuedCall) result() ([]byte, error) {
+	if c.panicked != nil {
+		panic(c.panicked)
+	}
+	return c.body, c.err
+}
+
+// workerPool is a fixed set of workers serving one queue
+type workerPool struct {
+	name  string
+	queue []*queuedCall
+	idle  int
+	ready *sync.Cond
+}
+
+// next removes the call to serve first from the queue. The caller holds the mutex.
+func (p *workerPool) next(aging time.Duration) *queuedCall {
+	now := time.Now()
+	best := 0
+	for i, call := range p.queue[1:] {
+		if call.before(p.queue[best], now, aging) {
+			best = i + 1
+		}
+	}
+	call := p.queue[best]
+	p.queue = slices.Delete(p.queue, best, best+1)
+	return call
+}
+
+// BackendPool runs backend calls on bounded pools of workers: models with a
+// worker count of their own get a dedicated pool, every other model shares the
+// default pool. Calls beyond the free workers wait in a bounded queue for a
+// short while before being shed; free workers take the queued call with the
+// highest aged priority, then the nearest deadline.
+type BackendPool struct {
+	pools        map[string]*workerPool
+	shared       *workerPool
+	queueSize    int64
+	queueTimeout time.Duration
+	aging        time.Duration
+	waiting      atomic.Int64
+	inflight     atomic.Int64
+
+	mutex    sync.Mutex
+	seq      uint64
+	reserved int64
+}
+
+// NewBackendPool starts the workers; with no default workers, calls to models
+// without a pool run directly on the caller's goroutine
+func NewBackendPool(workers int, models map[string]int, queueSize int, queueTimeout, aging time.Duration) *BackendPool {
+	p := &BackendPool{
+		pools:        make(map[string]*workerPool, len(models)),
+		queueSize:    int64(queueSize),
+		queueTimeout: queueTimeout,
+		aging:        aging,
+	}
+	for model, n := range models {
+		p.pools[model] = p.start(model, n)
+	}
+	if workers > 0 {
+		p.shared = p.start(defaultPool, workers)
+	}
+	return p
+}
+
+// start launches a pool of n workers
+func (p *BackendPool) start(name string, n int) *workerPool {
+	pool := &workerPool{name: name, ready: sync.NewCond(&p.mutex)}
+	metrics.BackendWorkers.WithLabelValues(name).Set(float64(n))
+	for i := 0; i < n; i++ {
+		go p.work(pool)
+	}
+	return pool
+}
+
+// pool returns the pool serving model, or nil when its calls are unbounded
+func (p *BackendPool) pool(model string) *workerPool {
+	if pool, ok := p.pools[model]; ok {
+		return pool
+	}
+	return p.shared
+}
+
+// Do runs a backend call on a worker of the model's pool and returns its
+// result. Among queued calls of the same priority, a call with a deadline is
+// served ahead of calls with later or no deadlines, and gives up once its
+// deadline passes while still queued.
+func (p *BackendPool) Do(ctx context.Context, req BackendCall, run func() ([]byte, error)) ([]byte, error) {
+	pool := p.pool(req.Model)
+	if pool == nil {
+		release := p.held()
+		defer release()
+		return run()
+	}
+
+	p.mutex.Lock()
+	// Idle workers take the call at once; only calls beyond them queue
+	if pool.idle <= len(pool.queue) {
+		capacity := p.queueSize
+		if !req.Reserved {
+			capacity -= p.reserved
+		}
+		if p.waiting.Load() >= capacity {
+			p.mutex.Unlock()
+			return nil, ErrBackendSaturated
+		}
+	}
+	p.seq++
+	call := &queuedCall{priority: req.Priority, deadline: req.Deadline, seq: p.seq, queuedAt: time.Now(), run: run, done: make(chan struct{})}
+	pool.queue = append(pool.queue, call)
+	p.queued(pool, 1)
+	pool.ready.Signal()
+	p.mutex.Unlock()
+
+	timeout, shed := p.queueTimeout, ErrBackendSaturated
+	if !req.Deadline.IsZero() && time.Until(req.Deadline) < timeout {
+		timeout, shed = time.Until(req.Deadline), ErrDeadlinePassed
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-call.done:
+		return call.result()
+	case <-timer.C:
+		err = shed
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if p.withdraw(pool, call) {
+		return nil, err
+	}
+	// A worker picked the call up in the meantime; it sees the same context,
+	// so waiting for its answer is short when the caller has gone
+	<-call.done
+	return call.result()
+}
+
+// withdraw takes a call out of its pool's queue, reporting false when a
+// worker already picked it up
+func (p *BackendPool) withdraw(pool *workerPool, call *queuedCall) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for i, c := range pool.queue {
+		if c == call {
+			pool.queue = slices.Delete(pool.queue, i, i+1)
+			p.queued(pool, -1)
+			return true
+		}
+	}
+	return false
+}
+
+// queued adjusts the queue gauges by delta. The caller holds the mutex.
+func (p *BackendPool) queued(pool *workerPool, delta int64) {
+	p.waiting.Add(delta)
+	metrics.BackendQueued.Add(float64(delta))
+	metrics.BackendQueueDepth.WithLabelValues(pool.name).Set(float64(len(pool.queue)))
+}
+
+// work serves a pool's queue until the process exits
+func (p *BackendPool) work(pool *workerPool) {
+	for {
+		p.mutex.Lock()
+		for len(pool.queue) == 0 {
+			pool.idle++
+			pool.ready.Wait()
+			pool.idle--
+		}
+		call := pool.next(p.aging)
+		p.queued(pool, -1)
+		p.mutex.Unlock()
+
+		metrics.BackendQueueWait.WithLabelValues(pool.name).Observe(time.Since(call.queuedAt).Seconds())
+		p.execute(call)
+	}
+}
+
+// execute runs a call, keeping a panic from taking the worker down with it
+func (p *BackendPool) execute(call *queuedCall) {
+	release := p.held()
+	defer func() {
+		call.panicked = recover()
+		release()
+		close(call.done)
+	}()
+	call.body, call.err = call.run()
+}
+
+// Reserve sets aside n places in the wait queue for expected calls, reporting
+// false when the queue cannot hold them. Without worker pools nothing
+// queues, so any reservation succeeds.
+func (p *BackendPool) Reserve(n int) bool {
+	if p.shared == nil && len(p.pools) == 0 {
+		return true
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.reserved+int64(n) > p.queueSize {
+		return false
+	}
+	p.reserved += int64(n)
+	return true
+}
+
+// Unreserve returns queue places set aside by Reserve
+func (p *BackendPool) Unreserve(n int) {
+	if p.shared == nil && len(p.pools) == 0 {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.reserved = max(p.reserved-int64(n), 0)
+}
+
+// Inflight returns the number of backend calls being run
+func (p *BackendPool) Inflight() int64 {
+	return p.inflight.Load()
+}
+
+// Waiting returns the number of backend calls queued for a worker
+func (p *BackendPool) Waiting() int64 {
+	return p.waiting.Load()
+}
+
+// Backlog estimates how long the wait queue takes to drain, from how full it
+// is and how long a queued call may wait
+func (p *BackendPool) Backlog() time.Duration {
+	if p.queueSize <= 0 {
+		return p.queueTimeout
+	}
+	waiting := min(p.waiting.Load(), p.queueSize)
+	return time.Duration(int64(p.queueTimeout) * waiting / p.queueSize)
+}
+
+// held records a call as in flight, returning the function that ends it
+func (p *BackendPool) held() func() {
+	p.inflight.Add(1)
+	metrics.BackendInflight.Inc()
+	return func() {
+		p.inflight.Add(-1)
+		metrics.BackendInflight.Dec()
+	}
+}
+
+// parseWorkerMap reads a JSON object of model to worker count
+func parseWorkerMap(name, raw string) (map[string]int, error) {
+	workers := make(map[string]int)
+	if raw == "" {
+		return workers, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &workers); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	for model, n := range workers {
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid %s: worker count for %s must be a positive integer", name, model)
+		}
+	}
+
+	return workers, nil
+}
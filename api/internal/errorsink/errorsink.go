@@ -0,0 +1,212 @@
+package errorsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"api/internal/metrics"
+)
+
+const serviceName = "targon-verifier-proxy"
+
+// Kinds of reported errors
+const (
+	KindPanic   = "panic"
+	KindBackend = "backend"
+)
+
+// Event is an error reported to the sink
+type Event struct {
+	Kind    string            `json:"kind"`
+	Message string            `json:"message"`
+	Stack   string            `json:"stack,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+// sink delivers events from a bounded queue so reporting never blocks a request
+type sink struct {
+	send   func(Event) error
+	events chan Event
+	done   chan struct{}
+	mutex  sync.RWMutex
+	closed bool
+}
+
+var active *sink
+
+// Init starts reporting to a Sentry project when dsn is set, or otherwise
+// posting JSON events to url. With neither, Capture is a no-op. The returned
+// function flushes queued events.
+func Init(dsn, url, environment string) (func(context.Context) error, error) {
+	if dsn == "" && url == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	var send func(Event) error
+	if dsn != "" {
+		endpoint, auth, err := parseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		send = func(e Event) error { return sendSentry(client, endpoint, auth, environment, e) }
+	} else {
+		send = func(e Event) error { return sendWebhook(client, url, environment, e) }
+	}
+
+	s := &sink{send: send, events: make(chan Event, 256), done: make(chan struct{})}
+	go s.run()
+	active = s
+
+	return s.flush, nil
+}
+
+// Capture queues an event, dropping it when the queue is full so a panic
+// storm cannot back up into request handling
+func Capture(e Event) {
+	s := active
+	if s == nil {
+		return
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- e:
+	default:
+		metrics.ErrorReports.WithLabelValues(e.Kind, "dropped").Inc()
+	}
+}
+
+func (s *sink) run() {
+	defer close(s.done)
+	for e := range s.events {
+		if err := s.send(e); err != nil {
+			metrics.ErrorReports.WithLabelValues(e.Kind, "failed").Inc()
+			continue
+		}
+		metrics.ErrorReports.WithLabelValues(e.Kind, "sent").Inc()
+	}
+}
+
+// flush stops accepting events and waits for the queue to drain
+func (s *sink) flush(ctx context.Context) error {
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	s.mutex.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error reports not flushed: %w", ctx.Err())
+	}
+}
+
+// parseDSN turns a Sentry DSN such as https://key@host/42 into the project's
+// store endpoint and auth header
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: must be of the form https://key@host/project")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if project == "" {
+		return "", "", fmt.Errorf("invalid Sentry DSN: missing project id")
+	}
+
+	endpoint := fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:slash], project)
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s/1.0, sentry_key=%s", serviceName, u.User.Username())
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	return endpoint, auth, nil
+}
+
+func sendSentry(client *http.Client, endpoint, auth, environment string, e Event) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	hostname, _ := os.Hostname()
+
+	level := "error"
+	if e.Kind == KindPanic {
+		level = "fatal"
+	}
+	payload := map[string]any{
+		"event_id":    hex.EncodeToString(id),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"platform":    "go",
+		"level":       level,
+		"logger":      serviceName,
+		"server_name": hostname,
+		"environment": environment,
+		"message":     e.Message,
+		"tags":        e.Tags,
+		"exception": map[string]any{
+			"values": []map[string]string{{"type": e.Kind, "value": e.Message}},
+		},
+	}
+	if e.Stack != "" {
+		payload["extra"] = map[string]string{"stack": e.Stack}
+	}
+
+	return post(client, endpoint, map[string]string{"X-Sentry-Auth": auth}, payload)
+}
+
+func sendWebhook(client *http.Client, url, environment string, e Event) error {
+	return post(client, url, nil, struct {
+		Event
+		Service     string    `json:"service"`
+		Environment string    `json:"environment"`
+		Timestamp   time.Time `json:"timestamp"`
+	}{e, serviceName, environment, time.Now().UTC()})
+}
+
+func post(client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error sink returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package hotkey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Epistula request signing headers
+const (
+	HeaderEpistulaVersion   = "Epistula-Version"
+	HeaderEpistulaTimestamp = "Epistula-Timestamp"
+	HeaderEpistulaUuid      = "Epistula-Uuid"
+	HeaderEpistulaSignedBy  = "Epistula-Signed-By"
+	HeaderEpistulaSignedFor = "Epistula-Signed-For"
+	HeaderEpistulaSignature = "Epistula-Request-Signature"
+)
+
+// EpistulaVersion is the supported Epistula protocol version
+const EpistulaVersion = "2"
+
+// EpistulaMessage returns the message a sender signs for a request: the hex
+// SHA-256 of the body, the request UUID, the millisecond timestamp and the
+// receiving hotkey, joined with dots
+func EpistulaMessage(body []byte, uuid, timestamp, signedFor string) []byte {
+	digest := sha256.Sum256(body)
+	return []byte(fmt.Sprintf("%s.%s.%s.%s", hex.EncodeToString(digest[:]), uuid, timestamp, signedFor))
+}
@@ -0,0 +1,101 @@
+package hotkey
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/aidarkhanov/nanoid"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	ErrInvalidAddress   = errors.New("invalid ss58 address")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// NewChallenge returns a fresh message for the hotkey owner to sign
+func NewChallenge(address string) (string, error) {
+	nonce, err := nanoid.Generate("0123456789abcdefghijklmnopqrstuvwxyz", 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	return fmt.Sprintf("targon-verifier-proxy:activate:%s:%s", address, nonce), nil
+}
+
+// PublicKey decodes an SS58 hotkey address into its sr25519 public key
+func PublicKey(address string) ([32]byte, error) {
+	var pub [32]byte
+
+	raw, err := base58.Decode(address)
+	if err != nil {
+		return pub, ErrInvalidAddress
+	}
+
+	// Single byte network prefixes give 35 bytes, two byte prefixes give 36
+	var prefixLen int
+	switch len(raw) {
+	case 35:
+		prefixLen = 1
+	case 36:
+		prefixLen = 2
+	default:
+		return pub, ErrInvalidAddress
+	}
+
+	body := raw[:prefixLen+32]
+	checksum := blake2b.Sum512(append([]byte("SS58PRE"), body...))
+	if !bytes.Equal(checksum[:2], raw[prefixLen+32:]) {
+		return pub, ErrInvalidAddress
+	}
+
+	copy(pub[:], raw[prefixLen:prefixLen+32])
+	return pub, nil
+}
+
+// DecodeSignature parses a hex encoded sr25519 signature, with or without 0x prefix
+func DecodeSignature(signature string) ([64]byte, error) {
+	var sig [64]byte
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil || len(raw) != len(sig) {
+		return sig, ErrInvalidSignature
+	}
+
+	copy(sig[:], raw)
+	return sig, nil
+}
+
+// Verify checks that signature is the hotkey's sr25519 signature over message
+func Verify(address string, message []byte, signature string) error {
+	pubBytes, err := PublicKey(address)
+	if err != nil {
+		return err
+	}
+
+	sigBytes, err := DecodeSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	pub, err := schnorrkel.NewPublicKey(pubBytes)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	sig := new(schnorrkel.Signature)
+	if err := sig.Decode(sigBytes); err != nil {
+		return ErrInvalidSignature
+	}
+
+	ok, err := pub.Verify(sig, schnorrkel.NewSigningContext([]byte("substrate"), message))
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
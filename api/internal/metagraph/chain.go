@@ -0,0 +1,120 @@
+package metagraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlockTime is the target time between subtensor blocks
+const BlockTime = 12 * time.Second
+
+// Chain tracks the subtensor block height and the subnet's epoch boundaries
+type Chain struct {
+	URL    string
+	Netuid int
+	Tempo  int64
+
+	// Transport is used for calls to the subtensor endpoint, nil meaning the default
+	Transport http.RoundTripper
+
+	block     int64
+	fetchedAt time.Time
+	mutex     sync.RWMutex
+}
+
+func NewChain(url string, netuid int, tempo int64) *Chain {
+	return &Chain{
+		URL:    url,
+		Netuid: netuid,
+		Tempo:  tempo,
+	}
+}
+
+// Enabled reports whether a subtensor endpoint is configured
+func (c *Chain) Enabled() bool {
+	return c.URL != ""
+}
+
+// FetchBlock asks the subtensor node for the latest block number
+func (c *Chain) FetchBlock(client *http.Client) (int64, error) {
+	payload := []byte(`{"jsonrpc":"2.0","id":1,"method":"chain_getHeader","params":[]}`)
+	resp, err := client.Post(c.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch block header: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Result struct {
+			Number string `json:"number"`
+		} `json:"result"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return 0, fmt.Errorf("failed to decode block header: %w", err)
+	}
+	if out.Error != nil {
+		return 0, fmt.Errorf("subtensor error: %s", out.Error.Message)
+	}
+
+	block, err := strconv.ParseInt(strings.TrimPrefix(out.Result.Number, "0x"), 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number %q: %w", out.Result.Number, err)
+	}
+
+	return block, nil
+}
+
+// Block returns the current block height, extrapolated from the last fetch
+func (c *Chain) Block() int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.fetchedAt.IsZero() {
+		return 0
+	}
+	return c.block + int64(time.Since(c.fetchedAt)/BlockTime)
+}
+
+// Epoch returns the subnet epoch index for a block and the blocks left until it ends
+func (c *Chain) Epoch(block int64) (int64, int64) {
+	period := c.Tempo + 1
+	offset := block + int64(c.Netuid) + 1
+	return offset / period, period - offset%period
+}
+
+// StartPollRoutine keeps the block height current
+func (c *Chain) StartPollRoutine(interval time.Duration) {
+	if !c.Enabled() {
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: c.Transport}
+	poll := func() {
+		block, err := c.FetchBlock(client)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			return
+		}
+		c.mutex.Lock()
+		c.block = block
+		c.fetchedAt = time.Now()
+		c.mutex.Unlock()
+	}
+
+	go func() {
+		poll()
+		ticker := time.NewTicker(interval)
+		for range ticker.C {
+			poll()
+		}
+	}()
+}
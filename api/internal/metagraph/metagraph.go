@@ -0,0 +1,169 @@
+package metagraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Neuron is a single registered hotkey on the subnet
+type Neuron struct {
+	Uid             int     `json:"uid"`
+	Hotkey          string  `json:"hotkey"`
+	Stake           float64 `json:"stake"`
+	ValidatorPermit bool    `json:"validator_permit"`
+}
+
+// Snapshot is the metagraph as returned by the configured endpoint
+type Snapshot struct {
+	Block   int64    `json:"block"`
+	Netuid  int      `json:"netuid"`
+	Neurons []Neuron `json:"neurons"`
+}
+
+// Status describes the outcome of the most recent sync
+type Status struct {
+	Enabled     bool      `json:"enabled"`
+	LastSync    time.Time `json:"last_sync,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	Block       int64     `json:"block"`
+	Neurons     int       `json:"neurons"`
+	Eligible    int       `json:"eligible"`
+	Provisioned int       `json:"provisioned"`
+	Reenabled   int       `json:"reenabled"`
+	Disabled    int       `json:"disabled"`
+	NextSync    time.Time `json:"next_sync,omitempty"`
+}
+
+// Store holds the latest metagraph snapshot for use by request handlers
+type Store struct {
+	URL      string
+	Netuid   int
+	MinStake float64
+	Permit   bool
+
+	// Transport is used for calls to the metagraph endpoint, nil meaning the default
+	Transport http.RoundTripper
+
+	snapshot Snapshot
+	stakes   map[string]float64
+	status   Status
+	mutex    sync.RWMutex
+	requests chan struct{}
+}
+
+func NewStore(url string, netuid int, minStake float64, requirePermit bool) *Store {
+	return &Store{
+		URL:      url,
+		Netuid:   netuid,
+		MinStake: minStake,
+		Permit:   requirePermit,
+		stakes:   make(map[string]float64),
+		status:   Status{Enabled: url != ""},
+		requests: make(chan struct{}, 1),
+	}
+}
+
+// Enabled reports whether a metagraph endpoint is configured
+func (s *Store) Enabled() bool {
+	return s.URL != ""
+}
+
+// Fetch downloads the current metagraph snapshot
+func (s *Store) Fetch(client *http.Client) (Snapshot, error) {
+	var snapshot Snapshot
+
+	resp, err := client.Get(fmt.Sprintf("%s?netuid=%d", s.URL, s.Netuid))
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to fetch metagraph: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return snapshot, fmt.Errorf("metagraph endpoint returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to decode metagraph: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// Eligible returns the hotkeys that should hold an API key
+func (s *Store) Eligible(snapshot Snapshot) map[string]struct{} {
+	eligible := make(map[string]struct{})
+	for _, n := range snapshot.Neurons {
+		if n.Stake < s.MinStake {
+			continue
+		}
+		if s.Permit && !n.ValidatorPermit {
+			continue
+		}
+		eligible[n.Hotkey] = struct{}{}
+	}
+	return eligible
+}
+
+// Update replaces the stored snapshot
+func (s *Store) Update(snapshot Snapshot) {
+	stakes := make(map[string]float64, len(snapshot.Neurons))
+	for _, n := range snapshot.Neurons {
+		stakes[n.Hotkey] = n.Stake
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.snapshot = snapshot
+	s.stakes = stakes
+}
+
+// Stake returns the stake of a hotkey in the latest snapshot
+func (s *Store) Stake(hotkey string) (float64, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stake, ok := s.stakes[hotkey]
+	return stake, ok
+}
+
+// Block returns the block height of the latest snapshot
+func (s *Store) Block() int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.snapshot.Block
+}
+
+// SetStatus records the outcome of a sync
+func (s *Store) SetStatus(status Status) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status.Enabled = s.Enabled()
+	s.status = status
+}
+
+// RequestSync asks the sync routine to run ahead of its schedule, reporting
+// false when syncing is disabled. Requests made while one is pending are merged.
+func (s *Store) RequestSync() bool {
+	if !s.Enabled() {
+		return false
+	}
+	select {
+	case s.requests <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Status returns the outcome of the most recent sync
+func (s *Store) Status() Status {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.status
+}
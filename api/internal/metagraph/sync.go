@@ -0,0 +1,143 @@
+package metagraph
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"api/internal/apikey"
+	"api/internal/hotkey"
+
+	"go.uber.org/zap"
+)
+
+// DB is the part of the proxy's database handle that Sync uses
+type DB interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// Sync fetches the metagraph and reconciles auto-provisioned API keys with it:
+// eligible hotkeys without a key get one (pending a signed challenge), and
+// auto-provisioned keys of hotkeys that are no longer eligible are disabled.
+func (s *Store) Sync(db DB, client *http.Client) (Status, error) {
+	status := Status{}
+
+	snapshot, err := s.Fetch(client)
+	if err != nil {
+		return status, err
+	}
+	s.Update(snapshot)
+
+	eligible := s.Eligible(snapshot)
+	status.Block = snapshot.Block
+	status.Neurons = len(snapshot.Neurons)
+	status.Eligible = len(eligible)
+
+	rows, err := db.Query("SELECT hotkey, auto_provisioned, disabled FROM api_keys")
+	if err != nil {
+		return status, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	type keyState struct {
+		auto     bool
+		disabled bool
+	}
+	existing := make(map[string]keyState)
+	for rows.Next() {
+		var hk string
+		var state keyState
+		if err := rows.Scan(&hk, &state.auto, &state.disabled); err != nil {
+			rows.Close()
+			return status, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		existing[hk] = state
+	}
+	rows.Close()
+
+	for hk := range eligible {
+		state, ok := existing[hk]
+		if !ok {
+			// The key is never revealed; activation issues the validator a fresh one
+			keyValue, err := apikey.Generate()
+			if err != nil {
+				return status, fmt.Errorf("failed to generate API key: %w", err)
+			}
+			challenge, err := hotkey.NewChallenge(hk)
+			if err != nil {
+				return status, err
+			}
+			_, err = db.Exec(
+				"INSERT INTO api_keys (hotkey, key_hash, is_admin, active, challenge, auto_provisioned) VALUES (?, ?, false, false, ?, true)",
+				hk, apikey.Hash(keyValue), challenge,
+			)
+			if err != nil {
+				return status, fmt.Errorf("failed to provision key for %s: %w", hk, err)
+			}
+			status.Provisioned++
+			continue
+		}
+
+		if state.auto && state.disabled {
+			if _, err := db.Exec("UPDATE api_keys SET disabled = FALSE WHERE hotkey = ?", hk); err != nil {
+				return status, fmt.Errorf("failed to re-enable key for %s: %w", hk, err)
+			}
+			status.Reenabled++
+		}
+	}
+
+	for hk, state := range existing {
+		if _, ok := eligible[hk]; ok || !state.auto || state.disabled {
+			continue
+		}
+		if _, err := db.Exec("UPDATE api_keys SET disabled = TRUE WHERE hotkey = ?", hk); err != nil {
+			return status, fmt.Errorf("failed to disable key for %s: %w", hk, err)
+		}
+		status.Disabled++
+	}
+
+	return status, nil
+}
+
+// StartSyncRoutine runs Sync immediately, then on every interval and whenever
+// RequestSync asks for it
+func (s *Store) StartSyncRoutine(db DB, interval time.Duration, log *zap.SugaredLogger) {
+	if !s.Enabled() {
+		return
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: s.Transport}
+	next := time.Now()
+	run := func() {
+		status, err := s.Sync(db, client)
+		status.LastSync = time.Now()
+		status.NextSync = next
+		if err != nil {
+			status.LastError = err.Error()
+			log.Errorw("Metagraph sync failed", "error", err.Error())
+		} else {
+			log.Infow("Metagraph synced",
+				"block", status.Block,
+				"eligible", status.Eligible,
+				"provisioned", status.Provisioned,
+				"reenabled", status.Reenabled,
+				"disabled", status.Disabled,
+			)
+		}
+		s.SetStatus(status)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		next = next.Add(interval)
+		run()
+		for {
+			select {
+			case tick := <-ticker.C:
+				next = tick.Add(interval)
+			case <-s.requests:
+			}
+			run()
+		}
+	}()
+}
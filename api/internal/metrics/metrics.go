@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// VerifyRequests counts /verify requests by model
+	VerifyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_verify_requests_total",
+		Help: "Verification requests received, by model.",
+	}, []string{"model"})
+
+	// CacheLookups counts result cache lookups by outcome (hit or miss)
+	CacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_cache_lookups_total",
+		Help: "Verification result cache lookups, by model and result.",
+	}, []string{"model", "result"})
+
+	// Verdicts counts verification outcomes by model and verdict
+	Verdicts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_verdicts_total",
+		Help: "Verification verdicts returned, by model and verdict.",
+	}, []string{"model", "verdict"})
+
+	// VerifyErrors counts failed verification requests by model and reason
+	VerifyErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_verify_errors_total",
+		Help: "Verification requests that failed, by model and reason.",
+	}, []string{"model", "reason"})
+
+	// BackendLatency observes round-trip time to the verifier backend
+	BackendLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "verifier_proxy_backend_latency_seconds",
+		Help:    "Latency of verifier backend calls, by model and outcome.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"model", "outcome"})
+
+	// VerifyDuration observes total /verify handling time
+	VerifyDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "verifier_proxy_verify_duration_seconds",
+		Help:    "Total time spent handling /verify, by model.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+	}, []string{"model"})
+
+	// DatabaseDown is 1 while the database health check is failing
+	DatabaseDown = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_database_down",
+		Help: "Whether the database is currently unreachable.",
+	})
+
+	// CanaryRuns counts synthetic canary verifications by result
+	CanaryRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_canary_runs_total",
+		Help: "Synthetic canary verifications, by result.",
+	}, []string{"result"})
+
+	// CanaryHealthy is 1 while the latest canary verification passed within its latency budget
+	CanaryHealthy = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_canary_healthy",
+		Help: "Whether the latest synthetic canary verification passed.",
+	})
+
+	// CanaryLatency is the duration of the latest canary verification
+	CanaryLatency = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_canary_latency_seconds",
+		Help: "Duration of the latest synthetic canary verification.",
+	})
+
+	// ShadowComparisons counts shadow backend verdicts compared against the primary
+	ShadowComparisons = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_shadow_comparisons_total",
+		Help: "Verifications mirrored to the shadow backend, by model and result.",
+	}, []string{"model", "result"})
+
+	// SanitizedFields counts raw chunk fields rewritten before forwarding
+	SanitizedFields = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_sanitized_fields_total",
+		Help: "Raw chunk fields sanitized before forwarding, by model and kind.",
+	}, []string{"model", "kind"})
+
+	// AuthAttempts counts API key authentication outcomes
+	AuthAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_auth_attempts_total",
+		Help: "API key authentication attempts, by route and result.",
+	}, []string{"route", "result"})
+
+	// AdminAuthAttempts counts admin authentication outcomes
+	AdminAuthAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_admin_auth_attempts_total",
+		Help: "Admin authentication attempts, by route and result.",
+	}, []string{"route", "result"})
+
+	// LegacyRequests counts calls to deprecated endpoint aliases
+	LegacyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_legacy_requests_total",
+		Help: "Requests to deprecated endpoint aliases, by route.",
+	}, []string{"route"})
+
+	// DegradedRequests counts verifications served in degraded mode
+	DegradedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_degraded_requests_total",
+		Help: "Verification requests served while the database was unreachable, by model.",
+	}, []string{"model"})
+
+	// BackendInflight is the number of backend calls being run
+	BackendInflight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_inflight",
+		Help: "Backend calls being run.",
+	})
+
+	// BackendQueued is the number of backend calls waiting for a worker
+	BackendQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_queued",
+		Help: "Backend calls waiting for a worker.",
+	})
+
+	// BackendWorkers is the number of workers in each backend worker pool
+	BackendWorkers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_workers",
+		Help: "Workers in each backend worker pool.",
+	}, []string{"pool"})
+
+	// BackendQueueDepth is the number of backend calls queued in each worker pool
+	BackendQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_queue_depth",
+		Help: "Backend calls waiting for a worker, by pool.",
+	}, []string{"pool"})
+
+	// BackendQueueWait measures how long backend calls wait for a worker
+	BackendQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "verifier_proxy_backend_queue_wait_seconds",
+		Help:    "Time backend calls spent queued before a worker picked them up, by pool.",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5},
+	}, []string{"pool"})
+
+	// BackendHealthy is 0 while a backend is ejected from load balancing
+	BackendHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_healthy",
+		Help: "Whether a backend is taking traffic, by backend URL.",
+	}, []string{"backend"})
+
+	// BackendDNSChanges counts backend hostnames whose resolved addresses changed
+	BackendDNSChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_backend_dns_changes_total",
+		Help: "Backend DNS resolution changes that rotated pooled connections, by host.",
+	}, []string{"host"})
+
+	// AnalyticsMirror counts verification copies sent to the analytics endpoint by result
+	AnalyticsMirror = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_analytics_mirror_total",
+		Help: "Verification copies mirrored to the analytics endpoint, by result.",
+	}, []string{"result"})
+
+	// SoakRequests counts synthetic soak-test verifications by result
+	SoakRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_soak_requests_total",
+		Help: "Synthetic soak-test verifications, by result.",
+	}, []string{"result"})
+
+	// SoakGoroutines is the goroutine count at the latest soak-test sample
+	SoakGoroutines = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_soak_goroutines",
+		Help: "Goroutines at the latest soak-test sample.",
+	})
+
+	// SoakHeapBytes is the live heap after a forced GC at the latest soak-test sample
+	SoakHeapBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_soak_heap_bytes",
+		Help: "Live heap bytes after GC at the latest soak-test sample.",
+	})
+
+	// SoakLeakSuspected is 1 once goroutine or heap growth exceeded the soak-test limits
+	SoakLeakSuspected = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "verifier_proxy_soak_leak_suspected",
+		Help: "Whether the soak test has seen growth beyond its limits.",
+	})
+
+	// KeysPruned counts keys the unused key policy acted on, by action
+	KeysPruned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_keys_pruned_total",
+		Help: "Keys flagged, disabled or cleared by the unused key policy.",
+	}, []string{"action"})
+
+	// RowsExpired counts rows deleted by the verification log retention, by table
+	RowsExpired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_rows_expired_total",
+		Help: "Rows deleted for being older than the verification log retention, by table.",
+	}, []string{"table"})
+
+	// ExpectedRequests counts request IDs announced through /verify/expect, by outcome
+	ExpectedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_expected_requests_total",
+		Help: "Announced request IDs registered, claimed by their request or expired.",
+	}, []string{"outcome"})
+
+	// BackendTimeout is the adaptive backend timeout last chosen for each model
+	BackendTimeout = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_timeout_seconds",
+		Help: "Backend timeout chosen from recent latencies, by model.",
+	}, []string{"model"})
+
+	// BackendConnections counts backend calls by whether they reused a pooled connection
+	BackendConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_backend_connections_total",
+		Help: "Backend calls by whether their connection was reused from the pool.",
+	}, []string{"reused"})
+
+	// BackendOpenConnections is the number of open connections to each backend address
+	BackendOpenConnections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "verifier_proxy_backend_open_connections",
+		Help: "Open connections to backends, by address.",
+	}, []string{"addr"})
+
+	// ErrorReports counts panics and backend failures reported to the error sink
+	ErrorReports = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verifier_proxy_error_reports_total",
+		Help: "Errors reported to the error sink, by kind and delivery result.",
+	}, []string{"kind", "result"})
+)
+
+// Verdict returns the label value for a verdict
+func Verdict(verified bool) string {
+	if verified {
+		return "verified"
+	}
+	return "unverified"
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"net"
+
+	"api/internal/config"
+	"api/internal/shared"
+)
+
+// accessInput collects the attributes of a request that access rules match on
+func accessInput(cc *shared.Context, req *shared.VerificationRequest) config.AccessInput {
+	in := config.AccessInput{
+		Hotkey: cc.Hotkey,
+		Tier:   cc.Tier,
+		Model:  req.Model,
+		Chunks: len(req.RawChunks),
+	}
+	if cc.Context != nil {
+		in.IP = net.ParseIP(cc.RealIP())
+	}
+	switch v := req.RequestParams["max_tokens"].(type) {
+	case float64:
+		in.MaxTokens = int64(v)
+	case int:
+		in.MaxTokens = int64(v)
+	case int64:
+		in.MaxTokens = v
+	}
+	return in
+}
+
+// checkAccess returns the reason the request is denied by an access rule, if any
+func checkAccess(cc *shared.Context, req *shared.VerificationRequest) string {
+	rules := cc.Cfg.Runtime().Access
+	if len(rules) == 0 {
+		return ""
+	}
+
+	rule, reason := config.CheckAccess(rules, accessInput(cc, req))
+	if rule == nil {
+		return ""
+	}
+
+	cc.Log.Warnw("Request denied by access rule",
+		"rule", rule.Name,
+		"hotkey", cc.Hotkey,
+		"model", req.Model,
+		"reason", reason,
+	)
+	return reason
+}
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"math/rand"
+	"slices"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// AccessLog emits one structured record per request, including the
+// verification outcome of verify requests. It must run after the middleware
+// that sets up the request context.
+func AccessLog(settings config.AccessLogSettings) (echo.MiddlewareFunc, error) {
+	if settings.Format == config.AccessLogOff {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }, nil
+	}
+
+	logCfg := zap.NewProductionConfig()
+	if settings.Format == config.AccessLogConsole {
+		logCfg = zap.NewDevelopmentConfig()
+	}
+	logCfg.DisableCaller = true
+	logCfg.DisableStacktrace = true
+	logCfg.Sampling = nil
+	logger, err := logCfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	log := logger.Named("access").Sugar()
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if slices.Contains(settings.SkipPaths, c.Path()) {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Render the error now so the record carries its status
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			if status < 400 && settings.Sample < 1 && rand.Float64() >= settings.Sample {
+				return nil
+			}
+
+			fields := []any{
+				"method", c.Request().Method,
+				"path", c.Request().URL.Path,
+				"route", c.Path(),
+				"status", status,
+				"total_latency_ms", time.Since(start).Milliseconds(),
+				"bytes_out", c.Response().Size,
+			}
+			if cc, ok := c.(*shared.Context); ok {
+				fields = append(fields, "proxy_request_id", "req_"+cc.Reqid)
+				if cc.ClientReqid != "" {
+					fields = append(fields, "client_request_id", cc.ClientReqid)
+				}
+				if cc.Hotkey != "" {
+					fields = append(fields, "hotkey", cc.Hotkey)
+				}
+				if cc.Actor != "" {
+					fields = append(fields, "actor", cc.Actor)
+				}
+				if o := cc.Outcome; o.Model != "" {
+					fields = append(fields, "model", o.Model, "cache_hit", o.Source == SourceCache)
+					if o.Verified != nil {
+						fields = append(fields, "verified", *o.Verified, "source", o.Source)
+					}
+					if o.BackendLatency > 0 {
+						fields = append(fields, "backend_latency_ms", o.BackendLatency.Milliseconds())
+					}
+				}
+			}
+			log.Infow("request", fields...)
+			return nil
+		}
+	}, nil
+}
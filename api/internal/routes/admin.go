@@ -2,24 +2,44 @@ package routes
 
 import (
 	"database/sql"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"api/internal/apikey"
+	"api/internal/config"
+	"api/internal/hotkey"
+	"api/internal/metrics"
 	"api/internal/shared"
 
-	"github.com/aidarkhanov/nanoid"
 	"github.com/labstack/echo/v4"
 )
 
-// checkAdminAuth validates that the request has a valid admin API key
+// checkAdminAuth validates that the request has a valid admin API key. Requests
+// already authorized by RequireScope are let through with the narrower scope.
 func checkAdminAuth(c echo.Context) (bool, int, string) {
 	cc := c.(*shared.Context)
+	if cc.Scope != "" {
+		return true, 0, ""
+	}
+	return authorizeScope(c, config.ScopeAll)
+}
+
+// authorizeScope validates that the request has a valid API key holding scope
+func authorizeScope(c echo.Context, scope string) (bool, int, string) {
+	cc := c.(*shared.Context)
 
 	// Check admin authorization from Bearer token
 	authHeader := c.Request().Header.Get("Authorization")
 	if authHeader == "" {
+		if hk, ok := sessionHotkey(c); ok {
+			return checkAdminSession(c, hk, scope)
+		}
 		cc.Log.Warn("Missing Authorization header")
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authMissingHeader).Inc()
 		return false, http.StatusUnauthorized, "Authorization required"
 	}
 
@@ -27,31 +47,90 @@ func checkAdminAuth(c echo.Context) (bool, int, string) {
 	parts := strings.Split(authHeader, " ")
 	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
 		cc.Log.Warnw("Invalid Authorization format", "header", authHeader)
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authBadFormat).Inc()
 		return false, http.StatusUnauthorized, "Invalid authorization format. Use 'Bearer YOUR_API_KEY'"
 	}
 
 	apiKey := parts[1]
 
 	// Verify the API key is an admin key
-	var isAdmin bool
-	err := cc.Cfg.SqlClient.QueryRow(
-		"SELECT is_admin FROM api_keys WHERE key_value = ?",
-		apiKey,
-	).Scan(&isAdmin)
+	key, err := cc.Cfg.Keys.Lookup(apiKey)
 
 	if err == sql.ErrNoRows {
 		cc.Log.Warnw("Invalid API key used for admin operation", "key", apiKey)
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authUnknownKey).Inc()
 		return false, http.StatusUnauthorized, "Invalid API key"
 	} else if err != nil {
 		cc.Log.Errorw("Database error checking API key", "error", err.Error())
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authLookupError).Inc()
+		return false, http.StatusInternalServerError, "Internal server error"
+	}
+
+	if err := checkKeyLength(cc, key, apiKey); err != nil {
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authShortKey).Inc()
+		return false, http.StatusUnauthorized, err.Error()
+	}
+
+	if !key.HasScope(scope) {
+		cc.Log.Warnw("API key without the required scope used for admin operation", "hotkey", key.Hotkey, "scope", scope)
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), missingScopeReason(scope)).Inc()
+		return false, http.StatusForbidden, missingScopeMessage(scope)
+	}
+
+	if err := checkKeyState(key); err != nil {
+		cc.Log.Warnw("Unusable admin key used", "hotkey", key.Hotkey, "error", err.Error())
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), keyStateReason(key)).Inc()
+		return false, http.StatusUnauthorized, err.Error()
+	}
+
+	if err := checkKeyAddress(cc, key); err != nil {
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authIPNotAllowed).Inc()
+		return false, http.StatusForbidden, err.Error()
+	}
+
+	metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authSuccess).Inc()
+
+	cc.Hotkey = key.Hotkey
+	cc.Key = key
+	return true, 0, ""
+}
+
+// checkAdminSession authorizes a request carrying an admin session cookie. The
+// key is re-read so disabling or demoting it ends its sessions immediately.
+func checkAdminSession(c echo.Context, hk string, scope string) (bool, int, string) {
+	cc := c.(*shared.Context)
+
+	key, err := cc.Cfg.Keys.LookupHotkey(hk)
+	if err == sql.ErrNoRows {
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authBadSession).Inc()
+		return false, http.StatusUnauthorized, "Invalid session"
+	} else if err != nil {
+		cc.Log.Errorw("Database error checking session key", "error", err.Error(), "hotkey", hk)
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authLookupError).Inc()
 		return false, http.StatusInternalServerError, "Internal server error"
 	}
 
-	if !isAdmin {
-		cc.Log.Warnw("Non-admin API key used for admin operation")
-		return false, http.StatusForbidden, "Administrator privileges required"
+	if !key.HasScope(scope) {
+		cc.Log.Warnw("Session of key without the required scope used for admin operation", "hotkey", hk, "scope", scope)
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), missingScopeReason(scope)).Inc()
+		return false, http.StatusForbidden, missingScopeMessage(scope)
+	}
+
+	if err := checkKeyState(key); err != nil {
+		cc.Log.Warnw("Session of unusable admin key used", "hotkey", hk, "error", err.Error())
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), keyStateReason(key)).Inc()
+		return false, http.StatusUnauthorized, err.Error()
+	}
+
+	if err := checkKeyAddress(cc, key); err != nil {
+		metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authIPNotAllowed).Inc()
+		return false, http.StatusForbidden, err.Error()
 	}
 
+	metrics.AdminAuthAttempts.WithLabelValues(c.Path(), authSession).Inc()
+
+	cc.Hotkey = key.Hotkey
+	cc.Key = key
 	return true, 0, ""
 }
 
@@ -62,69 +141,117 @@ func AddKey(c echo.Context) error {
 
 	// Check admin authorization
 	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
-		return c.JSON(code, map[string]string{"error": errMsg})
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
 	}
 
 	var req shared.AddKeyRequest
 	if err := c.Bind(&req); err != nil {
 		cc.Log.Errorw("Failed to parse request", "error", err.Error())
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request format",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
 	}
 
 	if req.Hotkey == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "hotkey is required",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if req.Tier == "" {
+		req.Tier = "standard"
+	}
+
+	if len(req.Scopes) == 0 {
+		req.Scopes = []string{config.ScopeVerify}
+	}
+	if err := config.ValidateScopes(req.Scopes); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+	// keys:write alone may only create verify keys, otherwise it could mint an admin key
+	if !slices.Equal(req.Scopes, []string{config.ScopeVerify}) && !cc.Key.HasScope(config.ScopeAll) {
+		return c.JSON(http.StatusForbidden, errorResponse(cc, shared.CodeForbidden, missingScopeMessage(config.ScopeAll)))
+	}
+	scopes := config.FormatScopes(req.Scopes)
+	isAdmin := slices.Contains(req.Scopes, config.ScopeAll)
+
+	expiresAt, err := keyExpiry(req.ExpiresAt, req.TTL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
 	}
 
 	// Generate API key value
-	keyValue, err := nanoid.Generate("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 32)
+	keyValue, err := apikey.Generate()
 	if err != nil {
 		cc.Log.Errorw("Failed to generate API key", "error", err.Error())
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to generate API key",
-		})
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to generate API key"))
 	}
 
 	var count int
 	err = cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM api_keys WHERE hotkey = ?", req.Hotkey).Scan(&count)
 	if err != nil {
 		cc.Log.Errorw("Failed to check for existing hotkey", "error", err.Error())
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to check for existing hotkey",
-		})
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to check for existing hotkey"))
 	}
 
 	if count > 0 {
 		cc.Log.Warnw("Attempted to create duplicate hotkey", "hotkey", req.Hotkey)
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Hotkey already exists. Use a different hotkey or remove the existing one first.",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Hotkey already exists. Use a different hotkey or remove the existing one first."))
+	}
+
+	// Keys stay inactive until the hotkey owner signs the challenge, when required
+	active := true
+	var challenge string
+	if cc.Cfg.Env.RequireChallenge {
+		challenge, err = hotkey.NewChallenge(req.Hotkey)
+		if err != nil {
+			cc.Log.Errorw("Failed to generate challenge", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to generate challenge"))
+		}
+		active = false
 	}
 
 	_, err = cc.Cfg.SqlClient.Exec(
-		"INSERT INTO api_keys (hotkey, key_value, is_admin) VALUES (?, ?, false)",
-		req.Hotkey, keyValue,
+		"INSERT INTO api_keys (hotkey, key_hash, key_hint, is_admin, scopes, tier, active, challenge, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		req.Hotkey, apikey.Hash(keyValue), apikey.Hint(keyValue), isAdmin, scopes, req.Tier, active, sql.NullString{String: challenge, Valid: challenge != ""}, expiresAt,
 	)
 	if err != nil {
 		cc.Log.Errorw("Failed to insert API key", "error", err.Error())
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to store API key",
-		})
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to store API key"))
 	}
-	cc.Log.Infow("API key created", "hotkey", req.Hotkey)
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("API key created", "hotkey", req.Hotkey, "tier", req.Tier, "scopes", scopes, "active", active, "expires_at", expiresAt)
+	auditAdmin(cc, auditKeyAdd, req.Hotkey, map[string]any{"tier": req.Tier, "scopes": scopes, "expires_at": expiresAt})
 
-	// Return the new key
+	// Only a hash is stored, so this is the one time the key is revealed
 	return c.JSON(http.StatusOK, shared.ApiKey{
 		Hotkey:    req.Hotkey,
 		KeyValue:  keyValue,
 		CreatedAt: time.Now(),
-		IsAdmin:   false, // Always false for newly created keys
+		IsAdmin:   isAdmin,
+		Scopes:    config.ParseScopes(scopes),
+		Tier:      req.Tier,
+		Active:    active,
+		Challenge: challenge,
+		ExpiresAt: expiresAt,
 	})
 }
 
+// keyExpiry resolves an optional absolute expiry or time-to-live into an expiry time
+func keyExpiry(expiresAt *time.Time, ttl string) (*time.Time, error) {
+	if expiresAt != nil && ttl != "" {
+		return nil, fmt.Errorf("set only one of expires_at and ttl")
+	}
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("ttl must be a positive duration such as 72h")
+		}
+		t := time.Now().Add(d)
+		return &t, nil
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+	return expiresAt, nil
+}
+
 // RemoveKey handler for removing an API key
 func RemoveKey(c echo.Context) error {
 	cc := c.(*shared.Context)
@@ -132,106 +259,358 @@ func RemoveKey(c echo.Context) error {
 
 	// Check admin authorization
 	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
-		return c.JSON(code, map[string]string{"error": errMsg})
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
 	}
 
 	// Parse request body
 	var req shared.RemoveKeyRequest
 	if err := c.Bind(&req); err != nil {
 		cc.Log.Errorw("Failed to parse request", "error", err.Error())
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request format",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
 	}
 
 	// Validate required fields
 	if req.Hotkey == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "hotkey is required",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
 	}
 
 	// Delete the key from the database
 	result, err := cc.Cfg.SqlClient.Exec("DELETE FROM api_keys WHERE hotkey = ?", req.Hotkey)
 	if err != nil {
 		cc.Log.Errorw("Failed to delete API key", "error", err.Error())
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to delete API key",
-		})
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to delete API key"))
 	}
 
 	// Check if any rows were affected
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		cc.Log.Errorw("Failed to get rows affected", "error", err.Error())
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to confirm deletion",
-		})
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to confirm deletion"))
 	}
 
 	if rowsAffected == 0 {
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "API key not found",
-		})
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
 	}
 
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
 	cc.Log.Infow("API key removed", "hotkey", req.Hotkey)
+	auditAdmin(cc, auditKeyRemove, req.Hotkey, nil)
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "API key removed successfully",
 	})
 }
 
-// GetKey handler for retrieving an API key by hotkey
+// DisableKey handler for switching off an API key without deleting it
+func DisableKey(c echo.Context) error {
+	return setKeyDisabled(c, true)
+}
+
+// EnableKey handler for switching a disabled API key back on, optionally with a new expiry
+func EnableKey(c echo.Context) error {
+	return setKeyDisabled(c, false)
+}
+
+func setKeyDisabled(c echo.Context, disabled bool) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetKeyStateRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	expiresAt, err := keyExpiry(req.ExpiresAt, req.TTL)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	// A key switched by hand is taken out of metagraph sync, which would
+	// otherwise re-enable or disable it on its next pass, and restarts the
+	// unused key policy's notice period
+	query := "UPDATE api_keys SET disabled = ?, auto_provisioned = FALSE, prune_flagged_at = NULL WHERE hotkey = ?"
+	args := []any{disabled, req.Hotkey}
+	if expiresAt != nil {
+		query = "UPDATE api_keys SET disabled = ?, auto_provisioned = FALSE, prune_flagged_at = NULL, expires_at = ? WHERE hotkey = ?"
+		args = []any{disabled, expiresAt, req.Hotkey}
+	}
+
+	var count int
+	if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM api_keys WHERE hotkey = ?", req.Hotkey).Scan(&count); err != nil {
+		cc.Log.Errorw("Failed to check for existing hotkey", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update API key"))
+	}
+	if count == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+	}
+
+	if _, err := cc.Cfg.SqlClient.Exec(query, args...); err != nil {
+		cc.Log.Errorw("Failed to update API key", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update API key"))
+	}
+
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("API key state changed", "hotkey", req.Hotkey, "disabled", disabled, "expires_at", expiresAt)
+	if disabled {
+		auditAdmin(cc, auditKeyDisable, req.Hotkey, nil)
+	} else {
+		auditAdmin(cc, auditKeyEnable, req.Hotkey, map[string]any{"expires_at": expiresAt})
+	}
+
+	message := "API key enabled"
+	if disabled {
+		message = "API key disabled"
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": message,
+	})
+}
+
+// RotateKey handler for replacing a hotkey's API key, optionally keeping the
+// old key valid for a grace period
+func RotateKey(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.RotateKeyRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	grace := cc.Cfg.Env.KeyRotationGrace
+	if req.GracePeriod != "" {
+		d, err := time.ParseDuration(req.GracePeriod)
+		if err != nil || d < 0 {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "grace_period must be a non-negative duration such as 24h"))
+		}
+		grace = d
+	}
+
+	keyValue, err := apikey.Generate()
+	if err != nil {
+		cc.Log.Errorw("Failed to generate API key", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to generate API key"))
+	}
+
+	// MySQL applies single-table assignments left to right, so the previous
+	// key column receives the old hash before key_hash is replaced
+	query := "UPDATE api_keys SET previous_key_hash = NULL, previous_key_expires_at = NULL, key_hash = ?, key_hint = ? WHERE hotkey = ?"
+	args := []any{apikey.Hash(keyValue), apikey.Hint(keyValue), req.Hotkey}
+	var previousExpiry *time.Time
+	if grace > 0 {
+		expiry := time.Now().Add(grace)
+		previousExpiry = &expiry
+		query = "UPDATE api_keys SET previous_key_hash = key_hash, previous_key_expires_at = ?, key_hash = ?, key_hint = ? WHERE hotkey = ?"
+		args = []any{expiry, apikey.Hash(keyValue), apikey.Hint(keyValue), req.Hotkey}
+	}
+
+	result, err := cc.Cfg.SqlClient.Exec(query, args...)
+	if err != nil {
+		cc.Log.Errorw("Failed to rotate API key", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to rotate API key"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+	}
+
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("API key rotated", "hotkey", req.Hotkey, "grace_period", grace.String())
+	auditAdmin(cc, auditKeyRotate, req.Hotkey, map[string]any{"grace_period": grace.String()})
+
+	return c.JSON(http.StatusOK, shared.RotateKeyResponse{
+		Hotkey:               req.Hotkey,
+		KeyValue:             keyValue,
+		PreviousKeyExpiresAt: previousExpiry,
+	})
+}
+
+// GetKey handler for identifying a hotkey's API key. Only a hash is stored, so
+// the key itself cannot be returned; use rotate-key to issue a new one.
 func GetKey(c echo.Context) error {
 	cc := c.(*shared.Context)
 	defer cc.Log.Sync()
 
 	// Check admin authorization
 	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
-		return c.JSON(code, map[string]string{"error": errMsg})
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
 	}
 
 	// Parse request body
 	var req shared.GetKeyRequest
 	if err := c.Bind(&req); err != nil {
 		cc.Log.Errorw("Failed to parse request", "error", err.Error())
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request format",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
 	}
 
 	// Validate required fields
 	if req.Hotkey == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "hotkey is required",
-		})
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
 	}
 
 	// Query for the API key
-	var keyValue string
+	var keyHint sql.NullString
 	err := cc.Cfg.SqlClient.QueryRow(
-		"SELECT key_value FROM api_keys WHERE hotkey = ?",
+		"SELECT key_hint FROM api_keys WHERE hotkey = ?",
 		req.Hotkey,
-	).Scan(&keyValue)
+	).Scan(&keyHint)
 
 	if err == sql.ErrNoRows {
 		cc.Log.Warnw("API key not found", "hotkey", req.Hotkey)
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "API key not found",
-		})
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
 	} else if err != nil {
 		cc.Log.Errorw("Database error retrieving API key", "error", err.Error(), "hotkey", req.Hotkey)
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to retrieve API key",
-		})
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve API key"))
 	}
 
 	cc.Log.Infow("API key retrieved", "hotkey", req.Hotkey)
+	auditAdmin(cc, auditKeyGet, req.Hotkey, nil)
 
-	// Return only the key_value and hotkey
+	// Return the hotkey and a masked key
 	return c.JSON(http.StatusOK, map[string]string{
-		"hotkey":    req.Hotkey,
-		"key_value": keyValue,
+		"hotkey":     req.Hotkey,
+		"key_masked": maskKey(keyHint.String),
 	})
 }
+
+// maskKey renders a stored key hint in place of the key
+func maskKey(hint string) string {
+	return strings.Repeat("*", 28) + hint
+}
+
+// ListKeys handler for listing API keys with pagination and filters
+func ListKeys(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	var conditions []string
+	var args []any
+
+	if adminOnly, _ := strconv.ParseBool(c.QueryParam("admin_only")); adminOnly {
+		conditions = append(conditions, "is_admin = TRUE")
+	}
+
+	if flagged, _ := strconv.ParseBool(c.QueryParam("prune_flagged")); flagged {
+		conditions = append(conditions, "prune_flagged_at IS NOT NULL")
+	}
+
+	if unusedSince := c.QueryParam("unused_since"); unusedSince != "" {
+		since, err := time.Parse(time.RFC3339, unusedSince)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "unused_since must be an RFC3339 timestamp"))
+		}
+		conditions = append(conditions, "(last_used_at IS NULL OR last_used_at < ?)")
+		args = append(args, since)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM api_keys"+where, args...).Scan(&total); err != nil {
+		cc.Log.Errorw("Failed to count API keys", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list API keys"))
+	}
+
+	rows, err := cc.Cfg.SqlClient.Query(
+		"SELECT hotkey, key_hint, created_at, last_used_at, is_admin, scopes, tier, active, disabled, auto_provisioned, expires_at, prune_flagged_at, prune_exempt, allowed_cidrs FROM api_keys"+where+
+			" ORDER BY created_at, hotkey LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to list API keys", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list API keys"))
+	}
+	defer rows.Close()
+
+	keys := []shared.KeySummary{}
+	for rows.Next() {
+		var key shared.KeySummary
+		var keyHint, cidrs sql.NullString
+		var scopes string
+		if err := rows.Scan(&key.Hotkey, &keyHint, &key.CreatedAt, &key.LastUsed, &key.IsAdmin, &scopes, &key.Tier, &key.Active, &key.Disabled, &key.AutoProvisioned, &key.ExpiresAt, &key.PruneFlaggedAt, &key.PruneExempt, &cidrs); err != nil {
+			cc.Log.Errorw("Failed to scan API key", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list API keys"))
+		}
+		key.Scopes = config.ParseScopes(scopes)
+		key.KeyMasked = maskKey(keyHint.String)
+		if cidrs.String != "" {
+			key.AllowedCIDRs = strings.Split(cidrs.String, ",")
+		}
+		keys = append(keys, key)
+	}
+
+	return c.JSON(http.StatusOK, shared.KeyListResponse{
+		Keys:   keys,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// parsePagination reads the limit and offset query parameters
+func parsePagination(c echo.Context) (int, int, error) {
+	limit, offset := 50, 0
+
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			return 0, 0, fmt.Errorf("limit must be between 1 and 500")
+		}
+		limit = n
+	}
+
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+
+	return limit, offset, nil
+}
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"api/internal/config"
+)
+
+// A key with only keys:write must not be able to take over or lock out a key
+// holding every scope, while keys without it stay manageable
+func TestKeysWriteCannotChangeAdminKeys(t *testing.T) {
+	cfg := newTestConfig(t)
+	e := newTestServer(cfg)
+	admin := e.Group("/admin")
+	admin.DELETE("/keys/:hotkey", RemoveKey, RequireScope(config.ScopeKeysWrite))
+	admin.POST("/keys/:hotkey/disable", DisableKey, RequireScope(config.ScopeKeysWrite))
+	admin.POST("/keys/:hotkey/rotate", RotateKey, RequireScope(config.ScopeKeysWrite))
+	admin.PUT("/keys/:hotkey/allowed-cidrs", SetAllowedCIDRs, RequireScope(config.ScopeKeysWrite))
+	admin.PUT("/keys/:hotkey/rate-limit", SetRateLimit, RequireScope(config.ScopeKeysWrite))
+	admin.PUT("/keys/:hotkey/daily-quota", SetDailyQuota, RequireScope(config.ScopeKeysWrite))
+	admin.PUT("/keys/:hotkey/prune-exempt", SetPruneExempt, RequireScope(config.ScopeKeysWrite))
+
+	insertKey(t, cfg, "root", config.ScopeAll)
+	insertKey(t, cfg, "validator", config.ScopeVerify)
+	writer := insertKey(t, cfg, "operator", config.ScopeKeysWrite)
+
+	for _, tc := range []struct {
+		method, path, body string
+	}{
+		{http.MethodPost, "/admin/keys/%s/rotate", `{}`},
+		{http.MethodPost, "/admin/keys/%s/disable", `{}`},
+		{http.MethodPut, "/admin/keys/%s/allowed-cidrs", `{"allowed_cidrs":["10.0.0.0/8"]}`},
+		{http.MethodPut, "/admin/keys/%s/rate-limit", `{"rps":0,"burst":1}`},
+		{http.MethodPut, "/admin/keys/%s/daily-quota", `{"quota":1}`},
+		{http.MethodPut, "/admin/keys/%s/prune-exempt", `{"exempt":true}`},
+		{http.MethodDelete, "/admin/keys/%s", ``},
+	} {
+		rec := serve(e, tc.method, fmt.Sprintf(tc.path, "root"), writer, tc.body)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s on admin key: status %d, want 403 (%s)", tc.method, tc.path, rec.Code, rec.Body)
+		}
+
+		rec = serve(e, tc.method, fmt.Sprintf(tc.path, "validator"), writer, tc.body)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s %s on verify key: status %d, want 200 (%s)", tc.method, tc.path, rec.Code, rec.Body)
+		}
+	}
+
+	var hash string
+	if err := cfg.SqlClient.QueryRow("SELECT key_hash FROM api_keys WHERE hotkey = ?", "root").Scan(&hash); err != nil {
+		t.Fatalf("admin key was removed: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetAllowedCIDRs handler for restricting the client networks a key may be used from
+func SetAllowedCIDRs(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetAllowedCIDRsRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	cidrs, err := config.NormalizeCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "invalid allowed_cidrs: "+err.Error()))
+	}
+
+	// An empty allowlist is stored as NULL so the key is usable from anywhere
+	var stored any
+	if len(cidrs) > 0 {
+		stored = strings.Join(cidrs, ",")
+	}
+
+	result, err := cc.Cfg.SqlClient.Exec("UPDATE api_keys SET allowed_cidrs = ? WHERE hotkey = ?", stored, req.Hotkey)
+	if err != nil {
+		cc.Log.Errorw("Failed to update allowed CIDRs", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update API key"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		var exists int
+		if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM api_keys WHERE hotkey = ?", req.Hotkey).Scan(&exists); err == nil && exists == 0 {
+			return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+		}
+	}
+
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("Allowed CIDRs updated", "hotkey", req.Hotkey, "allowed_cidrs", cidrs)
+	auditAdmin(cc, auditKeyAllowedCIDRs, req.Hotkey, map[string]any{"allowed_cidrs": cidrs})
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"message":       "Allowed CIDRs updated",
+		"allowed_cidrs": cidrs,
+	})
+}
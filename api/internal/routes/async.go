@@ -0,0 +1,290 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"api/internal/config"
+	"api/internal/errorsink"
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// VerifyAsync handler for enqueueing a verification and returning a job ID immediately
+func VerifyAsync(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	var request shared.VerificationRequest
+	if err := c.Bind(&request); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		metrics.VerifyErrors.WithLabelValues("", "invalid_request").Inc()
+		return c.JSON(http.StatusBadRequest, verifyError(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if errResp, code := admitVerification(cc, &request, true); errResp != nil {
+		return c.JSON(code, errResp)
+	}
+
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		cc.Log.Errorw("Failed to marshal request", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to enqueue verification"))
+	}
+
+	jobID := "job_" + cc.Cfg.NewID()
+	opts := requestOptions(cc, &request)
+	retention := cc.Cfg.Runtime().Retention.Resolve(request.Model, cc.Tier)
+
+	// Persist before acknowledging so the job survives a restart
+	_, err = cc.Cfg.SqlClient.Exec(
+		"INSERT INTO verification_jobs (id, hotkey, request_id, status, request, consensus, skip_dedup, retention) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		jobID, cc.Hotkey, request.RequestID, shared.JobPending, requestBody, opts.Consensus, opts.SkipDedup, retention,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to persist verification job", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to enqueue verification"))
+	}
+
+	if window, ok := cc.Cfg.Routes.Maintenance(request.Model, time.Now()); ok {
+		cc.Log.Infow("Deferring verification job until maintenance ends", "job_id", jobID, "model", request.Model, "until", window.End)
+		deferJob(cc.Cfg, jobID, window.End)
+	} else {
+		select {
+		case cc.Cfg.JobQueue <- jobID:
+		default:
+			cc.Log.Warnw("Async queue full", "job_id", jobID)
+			finishJob(cc.Cfg, cc.Log, jobID, nil, "queue full")
+			errResp := errorResponse(cc, shared.CodeQueueFull, "Verification queue is full, retry later")
+			retryAfter(cc, &errResp, 0)
+			return c.JSON(http.StatusServiceUnavailable, errResp)
+		}
+	}
+	cc.Cfg.Backoff.Reset(cc.Hotkey)
+
+	cc.Log.Infow("Verification job enqueued",
+		"job_id", jobID,
+		"model", request.Model,
+		"request_id", request.RequestID,
+	)
+
+	return c.JSON(http.StatusAccepted, shared.AsyncJobResponse{
+		JobID:     jobID,
+		Status:    shared.JobPending,
+		StatusURL: "/verify/status/" + jobID,
+	})
+}
+
+// VerifyStatus handler for polling the state of an asynchronous verification
+func VerifyStatus(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	if valid, err := validateAPIKey(cc); !valid {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, err.Error()))
+	}
+
+	detail, err := parseDetail(c.QueryParam("detail"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	jobID := c.Param("job_id")
+
+	var status shared.JobStatusResponse
+	var hotkey string
+	var result []byte
+	var errMsg sql.NullString
+	err = cc.Cfg.SqlClient.QueryRow(
+		"SELECT hotkey, status, request_id, result, error, retention, created_at, completed_at FROM verification_jobs WHERE id = ?",
+		jobID,
+	).Scan(&hotkey, &status.Status, &status.RequestID, &result, &errMsg, &status.Retention, &status.CreatedAt, &status.CompletedAt)
+
+	if err == sql.ErrNoRows || (err == nil && hotkey != cc.Hotkey) {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Job not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Database error retrieving job", "error", err.Error(), "job_id", jobID)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve job"))
+	}
+
+	status.JobID = jobID
+	status.Error = errMsg.String
+	if len(result) > 0 {
+		status.Result = trimResponse(result, detail)
+
+		// With no retention the result is only kept until it has been delivered once
+		if status.Retention == config.RetentionNone {
+			if _, err := cc.Cfg.SqlClient.Exec("UPDATE verification_jobs SET result = NULL WHERE id = ?", jobID); err != nil {
+				cc.Log.Warnw("Failed to drop delivered job result", "error", err.Error(), "job_id", jobID)
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// StartAsyncWorkers starts the job workers and re-drives jobs left incomplete
+// by a previous run. Replicas share the jobs table, so a worker only runs a job
+// once it has claimed the job's lease.
+func StartAsyncWorkers(cfg *config.Config, log *zap.SugaredLogger) {
+	host, _ := os.Hostname()
+	owner := host + "/" + cfg.NewID()
+
+	for i := 0; i < cfg.Env.AsyncWorkers; i++ {
+		go func() {
+			for jobID := range cfg.JobQueue {
+				runJobSafely(cfg, log, jobID, owner)
+			}
+		}()
+	}
+
+	rows, err := cfg.SqlClient.Query(
+		"SELECT id FROM verification_jobs WHERE status = ? OR (status = ? AND (lease_until IS NULL OR lease_until < ?)) ORDER BY created_at",
+		shared.JobPending, shared.JobRunning, time.Now(),
+	)
+	if err != nil {
+		log.Errorw("Failed to load incomplete jobs", "error", err.Error())
+		return
+	}
+	defer rows.Close()
+
+	var pending []string
+	for rows.Next() {
+		var jobID string
+		if err := rows.Scan(&jobID); err != nil {
+			log.Errorw("Failed to scan incomplete job", "error", err.Error())
+			return
+		}
+		pending = append(pending, jobID)
+	}
+
+	if len(pending) > 0 {
+		log.Infow("Re-driving incomplete verification jobs", "count", len(pending))
+		go func() {
+			for _, jobID := range pending {
+				cfg.JobQueue <- jobID
+			}
+		}()
+	}
+}
+
+// runJobSafely runs a job, failing that job rather than its worker if it panics
+func runJobSafely(cfg *config.Config, log *zap.SugaredLogger, jobID, owner string) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			err := fmt.Errorf("%v", r)
+			log.Errorw("Verification job panicked", "job_id", jobID, "error", err.Error(), "stack", string(stack))
+			errorsink.Capture(errorsink.Event{
+				Kind:    errorsink.KindPanic,
+				Message: err.Error(),
+				Stack:   string(stack),
+				Tags:    map[string]string{"job_id": jobID},
+			})
+			finishJob(cfg, log.With("job_id", jobID), jobID, nil, "Verification service error: internal error")
+		}
+	}()
+	runJob(cfg, log, jobID, owner)
+}
+
+// runJob executes a single persisted verification job
+func runJob(cfg *config.Config, log *zap.SugaredLogger, jobID, owner string) {
+	jobLog := log.With("job_id", jobID)
+
+	var hotkey string
+	var requestBody []byte
+	var opts verifyOptions
+	err := cfg.SqlClient.QueryRow(
+		"SELECT hotkey, request, consensus, skip_dedup FROM verification_jobs WHERE id = ?",
+		jobID,
+	).Scan(&hotkey, &requestBody, &opts.Consensus, &opts.SkipDedup)
+	if err != nil {
+		jobLog.Errorw("Failed to load job", "error", err.Error())
+		return
+	}
+
+	var request shared.VerificationRequest
+	if err := json.Unmarshal(requestBody, &request); err != nil {
+		finishJob(cfg, jobLog, jobID, nil, "invalid stored request")
+		return
+	}
+
+	// Jobs accepted before a window started, or re-driven during one, wait it out
+	if window, ok := cfg.Routes.Maintenance(request.Model, time.Now()); ok {
+		jobLog.Infow("Deferring verification job until maintenance ends", "model", request.Model, "until", window.End)
+		deferJob(cfg, jobID, window.End)
+		return
+	}
+
+	claimed, err := claimJob(cfg, jobID, owner)
+	if err != nil {
+		jobLog.Errorw("Failed to claim job", "error", err.Error())
+		return
+	}
+	if !claimed {
+		jobLog.Debugw("Job already claimed or finished, skipping")
+		return
+	}
+
+	cc := &shared.Context{Log: jobLog, Reqid: jobID, Cfg: cfg, Hotkey: hotkey}
+	cc.Capture = cfg.Env.Debug && cfg.Captures.Matches(config.CaptureRequestID, request.RequestID)
+	startTime := time.Now()
+
+	response, err := runVerification(cc, &request, opts)
+	if err != nil {
+		jobLog.Errorw("Verification job failed", "error", err.Error(), "request_id", request.RequestID)
+		metrics.VerifyErrors.WithLabelValues(request.Model, "backend").Inc()
+		finishJob(cfg, jobLog, jobID, nil, "Verification service error: "+err.Error())
+		return
+	}
+
+	finishJob(cfg, jobLog, jobID, response, "")
+	jobLog.Infow("Verification job completed",
+		"request_id", request.RequestID,
+		"duration_ms", time.Since(startTime).Milliseconds(),
+	)
+}
+
+// claimJob marks a job running under owner's lease. Only pending jobs and
+// running jobs whose lease has lapsed can be claimed, so it reports false when
+// another worker holds the job or the job has already finished.
+func claimJob(cfg *config.Config, jobID, owner string) (bool, error) {
+	now := time.Now()
+	result, err := cfg.SqlClient.Exec(
+		"UPDATE verification_jobs SET status = ?, owner = ?, lease_until = ? WHERE id = ? AND (status = ? OR (status = ? AND (lease_until IS NULL OR lease_until < ?)))",
+		shared.JobRunning, owner, now.Add(cfg.Env.AsyncJobLease), jobID, shared.JobPending, shared.JobRunning, now,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// finishJob records the terminal state of a job, dropping the request payload
+// unless the job's retention keeps full payloads
+func finishJob(cfg *config.Config, log *zap.SugaredLogger, jobID string, result []byte, errMsg string) {
+	status := shared.JobCompleted
+	var jobErr any
+	if errMsg != "" {
+		status = shared.JobFailed
+		jobErr = errMsg
+	}
+
+	_, err := cfg.SqlClient.Exec(
+		"UPDATE verification_jobs SET status = ?, result = ?, error = ?, completed_at = ?, request = CASE WHEN retention = ? THEN request ELSE '' END WHERE id = ?",
+		status, result, jobErr, time.Now(), config.RetentionFull, jobID,
+	)
+	if err != nil {
+		log.Errorw("Failed to record job result", "error", err.Error())
+	}
+}
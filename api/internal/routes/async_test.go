@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"api/internal/shared"
+)
+
+func TestClaimJobHonoursLeases(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Env.AsyncJobLease = time.Minute
+
+	_, err := cfg.SqlClient.Exec(
+		"INSERT INTO verification_jobs (id, hotkey, status, request) VALUES (?, ?, ?, ?)",
+		"job_1", "validator", shared.JobPending, "{}",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(owner string) bool {
+		t.Helper()
+		claimed, err := claimJob(cfg, "job_1", owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return claimed
+	}
+
+	if !claim("replica-a") {
+		t.Fatal("pending job should be claimable")
+	}
+	if claim("replica-b") {
+		t.Fatal("job under a live lease was claimed by another replica")
+	}
+
+	if _, err := cfg.SqlClient.Exec("UPDATE verification_jobs SET lease_until = ? WHERE id = ?", time.Now().Add(-time.Second), "job_1"); err != nil {
+		t.Fatal(err)
+	}
+	if !claim("replica-b") {
+		t.Fatal("job with a lapsed lease should be claimable")
+	}
+
+	var owner string
+	if err := cfg.SqlClient.QueryRow("SELECT owner FROM verification_jobs WHERE id = ?", "job_1").Scan(&owner); err != nil {
+		t.Fatal(err)
+	}
+	if owner != "replica-b" {
+		t.Fatalf("owner = %q, want replica-b", owner)
+	}
+
+	if _, err := cfg.SqlClient.Exec("UPDATE verification_jobs SET status = ?, lease_until = ? WHERE id = ?", shared.JobCompleted, time.Now().Add(-time.Second), "job_1"); err != nil {
+		t.Fatal(err)
+	}
+	if claim("replica-a") {
+		t.Fatal("finished job was claimed")
+	}
+}
@@ -0,0 +1,132 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Actions recorded in the admin audit
+const (
+	auditKeyAdd          = "key.add"
+	auditKeyRemove       = "key.remove"
+	auditKeyGet          = "key.get"
+	auditKeyRotate       = "key.rotate"
+	auditKeyDisable      = "key.disable"
+	auditKeyEnable       = "key.enable"
+	auditKeyRateLimit    = "key.rate_limit"
+	auditKeyDailyQuota   = "key.daily_quota"
+	auditKeyScopesGrant  = "key.scopes_grant"
+	auditKeyScopesRevoke = "key.scopes_revoke"
+	auditKeyPruneExempt  = "key.prune_exempt"
+	auditKeyAllowedCIDRs = "key.allowed_cidrs"
+	auditMetagraphSync   = "metagraph.sync"
+	auditRetentionClean  = "retention.cleanup"
+	auditBulkPrefix      = "bulk."
+)
+
+// auditAdmin records a completed admin operation. The operation has already
+// been applied, so a failure to record it is logged rather than returned.
+func auditAdmin(cc *shared.Context, action string, target string, detail any) {
+	var details sql.NullString
+	if detail != nil {
+		if body, err := json.Marshal(detail); err == nil {
+			details = sql.NullString{String: string(body), Valid: true}
+		}
+	}
+
+	_, err := cc.Cfg.SqlClient.Exec(
+		"INSERT INTO admin_audit (actor_hotkey, action, target, detail, proxy_request_id, source_ip, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		cc.Hotkey, action, target, details, cc.Reqid, cc.RealIP(), time.Now(),
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to record admin audit", "error", err.Error(), "action", action, "target", target, "actor", cc.Hotkey)
+	}
+}
+
+// ListAdminAudit handler for querying the admin audit trail
+func ListAdminAudit(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	conditions, args, err := parseTimeRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	if actor := c.QueryParam("actor"); actor != "" {
+		conditions = append(conditions, "actor_hotkey = ?")
+		args = append(args, actor)
+	}
+	if action := c.QueryParam("action"); action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, action)
+	}
+	if target := c.QueryParam("target"); target != "" {
+		conditions = append(conditions, "target = ?")
+		args = append(args, target)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM admin_audit"+where, args...).Scan(&total); err != nil {
+		cc.Log.Errorw("Failed to count admin audit entries", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list admin audit"))
+	}
+
+	rows, err := cc.Cfg.SqlClient.Query(
+		"SELECT id, actor_hotkey, action, target, detail, proxy_request_id, source_ip, created_at FROM admin_audit"+where+
+			" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to list admin audit", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list admin audit"))
+	}
+	defer rows.Close()
+
+	entries := []shared.AdminAuditEntry{}
+	for rows.Next() {
+		var e shared.AdminAuditEntry
+		var detail, requestID, sourceIP sql.NullString
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.Target, &detail, &requestID, &sourceIP, &e.CreatedAt); err != nil {
+			cc.Log.Errorw("Failed to scan admin audit entry", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list admin audit"))
+		}
+		if detail.Valid {
+			e.Detail = json.RawMessage(detail.String)
+		}
+		if requestID.String != "" {
+			e.RequestID = "req_" + requestID.String
+		}
+		e.SourceIP = sourceIP.String
+		entries = append(entries, e)
+	}
+
+	return c.JSON(http.StatusOK, shared.AdminAuditResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
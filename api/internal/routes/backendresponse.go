@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"api/internal/shared"
+)
+
+// Backend response schema versions. The proxy announces the newest it reads in
+// the X-Verifier-Schema request header; a backend names the version it answered
+// in with the same response header or a schema_version field, and v1 otherwise.
+const (
+	headerSchema = "X-Verifier-Schema"
+	schemaV1     = 1
+	schemaV2     = 2
+)
+
+// backendResponseV1 is the verdict schema of v1 verifier backends. Token
+// fields hold either a count or the tokens themselves.
+type backendResponseV1 struct {
+	SchemaVersion  int             `json:"schema_version"`
+	RequestID      string          `json:"request_id"`
+	Verified       *bool           `json:"verified"`
+	Error          string          `json:"error"`
+	Cause          string          `json:"cause"`
+	InputTokens    json.RawMessage `json:"input_tokens"`
+	ResponseTokens json.RawMessage `json:"response_tokens"`
+	GPUs           int             `json:"gpus"`
+	Score          *float64        `json:"score"`
+}
+
+// backendResponseV2 adds per-chunk scores, logprob distances and the time the
+// backend spent verifying to v1
+type backendResponseV2 struct {
+	backendResponseV1
+	ChunkScores      []float64 `json:"chunk_scores"`
+	LogprobDistances []float64 `json:"logprob_distances"`
+	VerificationMs   *int64    `json:"verification_ms"`
+}
+
+// schemaVersion detects the schema of a backend response from its header or
+// body. Versions newer than the proxy knows are read as the newest it does.
+func schemaVersion(header string, body int) (int, error) {
+	version := body
+	if header != "" {
+		parsed, err := strconv.Atoi(header)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be an integer", headerSchema)
+		}
+		version = parsed
+	}
+	switch {
+	case version == 0:
+		return schemaV1, nil
+	case version < 0:
+		return 0, fmt.Errorf("schema version must be positive")
+	default:
+		return min(version, schemaV2), nil
+	}
+}
+
+// invalidBackendResponse marks a backend answer that does not follow the
+// response schema
+type invalidBackendResponse struct {
+	error
+}
+
+func (e invalidBackendResponse) Unwrap() error {
+	return e.error
+}
+
+// parseBackendResponse decodes and validates a backend verdict, normalizing
+// token fields to counts. schemaHeader is the response's X-Verifier-Schema.
+// Unknown fields are ignored so that backends can add fields ahead of the proxy.
+func parseBackendResponse(body []byte, schemaHeader string) (shared.VerificationResponse, error) {
+	var raw backendResponseV2
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: %w", err)}
+	}
+	version, err := schemaVersion(schemaHeader, raw.SchemaVersion)
+	if err != nil {
+		return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: %w", err)}
+	}
+	if raw.Verified == nil && raw.Error == "" {
+		return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: missing verified")}
+	}
+	if raw.GPUs < 0 {
+		return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: negative gpus")}
+	}
+
+	response := shared.VerificationResponse{
+		SchemaVersion: version,
+		RequestID:     raw.RequestID,
+		Verified:      raw.Verified != nil && *raw.Verified,
+		Error:         raw.Error,
+		Cause:         raw.Cause,
+		GPUs:          raw.GPUs,
+		Score:         raw.Score,
+	}
+	if version >= schemaV2 {
+		if raw.VerificationMs != nil && *raw.VerificationMs < 0 {
+			return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: negative verification_ms")}
+		}
+		response.ChunkScores = raw.ChunkScores
+		response.LogprobDistances = raw.LogprobDistances
+		response.VerificationMs = raw.VerificationMs
+	}
+	if response.InputTokens, err = normalizeTokens(raw.InputTokens); err != nil {
+		return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: input_tokens %w", err)}
+	}
+	if response.ResponseTokens, err = normalizeTokens(raw.ResponseTokens); err != nil {
+		return shared.VerificationResponse{}, invalidBackendResponse{fmt.Errorf("invalid backend response: response_tokens %w", err)}
+	}
+	return response, nil
+}
+
+// normalizeTokens turns a token field into a count: a number is taken as is
+// and a list of tokens counts its entries. An absent field has no count.
+func normalizeTokens(raw json.RawMessage) (*int64, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	if f, ok := v.(float64); ok && (f < 0 || f != math.Trunc(f)) {
+		return nil, fmt.Errorf("must be a non-negative integer")
+	}
+	n, ok := tokenCount(v)
+	if !ok {
+		return nil, fmt.Errorf("must be a count or a list of tokens")
+	}
+	return &n, nil
+}
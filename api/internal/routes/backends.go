@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListBackends handler for the verifier versions last reported by each backend
+func ListBackends(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"min_version": cc.Cfg.Versions.MinVersion,
+		"enforce":     cc.Cfg.Versions.Enforce,
+		"backends":    cc.Cfg.Versions.All(),
+		"health":      cc.Cfg.Balancer.All(),
+	})
+}
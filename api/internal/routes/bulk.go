@@ -0,0 +1,354 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bulkTokenTTL is how long a dry run's confirmation token can be redeemed
+const bulkTokenTTL = 10 * time.Minute
+
+// bulkBatchSize bounds the IN lists of bulk updates
+const bulkBatchSize = 500
+
+// bulkPlan is the resolved selection of a bulk operation. The confirmation
+// token is derived from it, so a token only confirms the exact selection the
+// dry run showed.
+type bulkPlan struct {
+	Operation string   `json:"operation"`
+	Scope     any      `json:"scope"`
+	Count     int64    `json:"count"`
+	Affected  []string `json:"affected"`
+	describe  string
+	execute   func() (int64, error)
+}
+
+func (p bulkPlan) token() string {
+	body, _ := json.Marshal(p)
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:16])
+}
+
+func bulkPendingKey(token string) string {
+	return "bulk:pending:" + token
+}
+
+func bulkDoneKey(token string) string {
+	return "bulk:done:" + token
+}
+
+// runBulk answers a dry run with the plan and a confirmation token, or runs
+// the plan when given a token matching it. Redeeming a token a second time
+// returns the first result instead of running again.
+func runBulk(cc *shared.Context, req shared.BulkRequest, plan bulkPlan) error {
+	dryRun := req.DryRun || strings.ToLower(cc.QueryParam("dry_run")) == "true"
+	token := plan.token()
+
+	if dryRun {
+		expiresAt := time.Now().Add(bulkTokenTTL)
+		cc.Cfg.Cache.Set(bulkPendingKey(token), []byte(plan.Operation), bulkTokenTTL)
+		return cc.JSON(http.StatusOK, shared.BulkResult{
+			Operation:         plan.Operation,
+			DryRun:            true,
+			Count:             plan.Count,
+			Affected:          plan.Affected,
+			ConfirmationToken: token,
+			ExpiresAt:         &expiresAt,
+			Message:           fmt.Sprintf("Would %s; repeat with confirmation_token to apply", plan.describe),
+		})
+	}
+
+	if req.ConfirmationToken == "" {
+		return cc.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "confirmation_token is required; run with dry_run=true first"))
+	}
+
+	if done, found := cc.Cfg.Cache.Get(bulkDoneKey(req.ConfirmationToken)); found && len(done) > 0 {
+		return cc.JSONBlob(http.StatusOK, done)
+	}
+
+	if pending, found := cc.Cfg.Cache.Get(bulkPendingKey(req.ConfirmationToken)); !found || string(pending) != plan.Operation {
+		return cc.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "Unknown or expired confirmation_token; run with dry_run=true again"))
+	}
+
+	if req.ConfirmationToken != token {
+		return cc.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "The affected set changed since the dry run; run with dry_run=true again"))
+	}
+
+	applied, err := plan.execute()
+	if err != nil {
+		cc.Log.Errorw("Bulk operation failed", "operation", plan.Operation, "error", err.Error(), "applied", applied)
+		return cc.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, fmt.Sprintf("Bulk operation failed after %d of %d", applied, plan.Count)))
+	}
+
+	result := shared.BulkResult{
+		Operation: plan.Operation,
+		Count:     applied,
+		Affected:  plan.Affected,
+		Message:   fmt.Sprintf("Applied: %s", plan.describe),
+	}
+	body, err := json.Marshal(result)
+	if err == nil {
+		cc.Cfg.Cache.Set(bulkDoneKey(token), body, bulkTokenTTL)
+	}
+	cc.Cfg.Cache.Delete(bulkPendingKey(token))
+
+	cc.Log.Infow("Bulk operation applied",
+		"operation", plan.Operation,
+		"count", applied,
+		"by", cc.Hotkey,
+	)
+	auditAdmin(cc, auditBulkPrefix+plan.Operation, "", map[string]any{"count": applied, "scope": plan.Scope})
+
+	return cc.JSON(http.StatusOK, result)
+}
+
+// placeholders returns n comma-separated bind placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// BulkDisableKeys handler for disabling every non-admin key matching a selection
+func BulkDisableKeys(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.BulkDisableKeysRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if len(req.Hotkeys) == 0 && req.Tier == "" && req.AutoProvisioned == nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "at least one of hotkeys, tier or auto_provisioned is required"))
+	}
+
+	conditions := []string{"disabled = FALSE", "is_admin = FALSE"}
+	var args []any
+	if len(req.Hotkeys) > 0 {
+		conditions = append(conditions, "hotkey IN ("+placeholders(len(req.Hotkeys))+")")
+		for _, hk := range req.Hotkeys {
+			args = append(args, hk)
+		}
+	}
+	if req.Tier != "" {
+		conditions = append(conditions, "tier = ?")
+		args = append(args, req.Tier)
+	}
+	if req.AutoProvisioned != nil {
+		conditions = append(conditions, "auto_provisioned = ?")
+		args = append(args, *req.AutoProvisioned)
+	}
+
+	rows, err := cc.Cfg.SqlClient.Query("SELECT hotkey FROM api_keys WHERE "+strings.Join(conditions, " AND ")+" ORDER BY hotkey", args...)
+	if err != nil {
+		cc.Log.Errorw("Failed to select keys", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to select keys"))
+	}
+	var hotkeys []string
+	for rows.Next() {
+		var hk string
+		if err := rows.Scan(&hk); err != nil {
+			rows.Close()
+			cc.Log.Errorw("Failed to scan key", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to select keys"))
+		}
+		hotkeys = append(hotkeys, hk)
+	}
+	rows.Close()
+
+	return runBulk(cc, req.BulkRequest, bulkPlan{
+		Operation: "disable_keys",
+		Scope:     []any{req.Hotkeys, req.Tier, req.AutoProvisioned},
+		Count:     int64(len(hotkeys)),
+		Affected:  hotkeys,
+		describe:  fmt.Sprintf("disable %d API keys", len(hotkeys)),
+		execute: func() (int64, error) {
+			var applied int64
+			for start := 0; start < len(hotkeys); start += bulkBatchSize {
+				batch := hotkeys[start:min(start+bulkBatchSize, len(hotkeys))]
+				batchArgs := make([]any, len(batch))
+				for i, hk := range batch {
+					batchArgs[i] = hk
+				}
+				// Disabled by hand, so metagraph sync must not re-enable them
+				_, err := cc.Cfg.SqlClient.Exec(
+					"UPDATE api_keys SET disabled = TRUE, auto_provisioned = FALSE WHERE hotkey IN ("+placeholders(len(batch))+")",
+					batchArgs...,
+				)
+				if err != nil {
+					return applied, err
+				}
+				for _, hk := range batch {
+					cc.Cfg.Keys.InvalidateHotkey(hk)
+				}
+				applied += int64(len(batch))
+			}
+			return applied, nil
+		},
+	})
+}
+
+// BulkInvalidateCache handler for dropping cached results by request ID
+func BulkInvalidateCache(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.BulkInvalidateCacheRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if len(req.RequestIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "request_ids is required"))
+	}
+
+	var cached []string
+	for _, id := range req.RequestIDs {
+		if _, found := cc.Cfg.Cache.Get(resultCacheKey(id)); found && id != "" {
+			cached = append(cached, id)
+		}
+	}
+
+	return runBulk(cc, req.BulkRequest, bulkPlan{
+		Operation: "invalidate_cache",
+		Scope:     req.RequestIDs,
+		Count:     int64(len(cached)),
+		Affected:  cached,
+		describe:  fmt.Sprintf("drop %d cached results", len(cached)),
+		execute: func() (int64, error) {
+			for _, id := range cached {
+				cc.Cfg.Cache.Delete(resultCacheKey(id))
+				cc.Cfg.Cache.Delete(payloadCacheKey(id))
+			}
+			return int64(len(cached)), nil
+		},
+	})
+}
+
+// purgeTables maps the tables that can be purged to the condition limiting a
+// purge to rows that are safe to delete
+var purgeTables = map[string]string{
+	"verification_logs": "1 = 1",
+	"verification_jobs": "status IN ('" + shared.JobCompleted + "', '" + shared.JobFailed + "')",
+}
+
+// BulkPurge handler for deleting stored rows created before a point in time
+func BulkPurge(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.BulkPurgeRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	safe, ok := purgeTables[req.Table]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "table must be verification_logs or verification_jobs"))
+	}
+	if req.Before.IsZero() {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "before is required"))
+	}
+
+	conditions := []string{safe, "created_at < ?"}
+	args := []any{req.Before}
+	if req.Model != "" {
+		if req.Table != "verification_logs" {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "model can only be used with verification_logs"))
+		}
+		conditions = append(conditions, "model = ?")
+		args = append(args, req.Model)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	// Rows created after the dry run are left alone: logs are bounded by the
+	// highest matching id and jobs by the exact ids listed
+	var count int64
+	var affected []string
+	var through any
+	switch req.Table {
+	case "verification_logs":
+		var maxID int64
+		err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*), COALESCE(MAX(id), 0) FROM verification_logs WHERE "+where, args...).Scan(&count, &maxID)
+		if err != nil {
+			cc.Log.Errorw("Failed to count rows to purge", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to select rows"))
+		}
+		through = maxID
+	default:
+		rows, err := cc.Cfg.SqlClient.Query("SELECT id FROM verification_jobs WHERE "+where+" ORDER BY id", args...)
+		if err != nil {
+			cc.Log.Errorw("Failed to select rows to purge", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to select rows"))
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to select rows"))
+			}
+			affected = append(affected, id)
+		}
+		rows.Close()
+		count = int64(len(affected))
+	}
+
+	return runBulk(cc, req.BulkRequest, bulkPlan{
+		Operation: "purge_" + req.Table,
+		Scope:     []any{req.Table, req.Before, req.Model, through},
+		Count:     count,
+		Affected:  affected,
+		describe:  fmt.Sprintf("delete %d rows from %s created before %s", count, req.Table, req.Before.Format(time.RFC3339)),
+		execute: func() (int64, error) {
+			if req.Table == "verification_logs" {
+				result, err := cc.Cfg.SqlClient.Exec("DELETE FROM verification_logs WHERE "+where+" AND id <= ?", append(args, through)...)
+				if err != nil {
+					return 0, err
+				}
+				return result.RowsAffected()
+			}
+
+			var applied int64
+			for start := 0; start < len(affected); start += bulkBatchSize {
+				batch := affected[start:min(start+bulkBatchSize, len(affected))]
+				batchArgs := make([]any, len(batch))
+				for i, id := range batch {
+					batchArgs[i] = id
+				}
+				result, err := cc.Cfg.SqlClient.Exec("DELETE FROM verification_jobs WHERE id IN ("+placeholders(len(batch))+")", batchArgs...)
+				if err != nil {
+					return applied, err
+				}
+				n, _ := result.RowsAffected()
+				applied += n
+			}
+			return applied, nil
+		},
+	})
+}
@@ -0,0 +1,77 @@
+package routes
+
+import (
+	"encoding/json"
+
+	"api/internal/config"
+	"api/internal/shared"
+)
+
+// cacheKeys returns the result cache keys for a request, most specific first.
+// In content mode identical payloads share a result regardless of request_id;
+// canary probes are kept out of it so they always reach the backend.
+func cacheKeys(cc *shared.Context, req *shared.VerificationRequest) []string {
+	var keys []string
+	if req.RequestID != "" {
+		keys = append(keys, resultCacheKey(req.RequestID))
+	}
+	if cc.Cfg.Env.CacheKeyMode == config.CacheKeyContent && !cc.Canary {
+		keys = append(keys, "content:"+payloadHash(req))
+	}
+	return keys
+}
+
+// lookupCache returns the first cached result among keys and the key it was found under
+func lookupCache(cc *shared.Context, keys []string) ([]byte, string, bool) {
+	for _, key := range keys {
+		if response, found := cc.Cfg.Cache.Get(key); found {
+			return response, key, true
+		}
+	}
+	return nil, "", false
+}
+
+// resultCacheKey is the cache key holding the result verified under a
+// request_id. Client IDs are namespaced so they cannot collide with the
+// proxy's own keys, such as another payload's content key.
+func resultCacheKey(requestID string) string {
+	return "req:" + requestID
+}
+
+// payloadCacheKey is the cache key holding the payload hash a request_id was verified with
+func payloadCacheKey(requestID string) string {
+	return "payload:" + requestID
+}
+
+// requestIDConflict reports whether the request's ID already has a cached
+// result for a different payload
+func requestIDConflict(cc *shared.Context, req *shared.VerificationRequest) bool {
+	if req.RequestID == "" {
+		return false
+	}
+	hash, found := cc.Cfg.Cache.Get(payloadCacheKey(req.RequestID))
+	return found && string(hash) != payloadHash(req)
+}
+
+// storeCache caches a result under every key of the request, for as long as
+// its outcome allows
+func storeCache(cc *shared.Context, req *shared.VerificationRequest, response []byte) {
+	var result shared.VerificationResponse
+	if err := json.Unmarshal(response, &result); err != nil {
+		cc.Log.Warnw("Not caching unreadable response", "error", err.Error(), "request_id", req.RequestID)
+		return
+	}
+
+	ttl, ok := cc.Cfg.Env.ResultCache.For(result.Verified, result.Error)
+	if !ok {
+		cc.Log.Infow("Skipped caching response", "request_id", req.RequestID, "verified", result.Verified)
+		return
+	}
+	for _, key := range cacheKeys(cc, req) {
+		cc.Cfg.Cache.Set(key, response, ttl)
+	}
+	if req.RequestID != "" {
+		cc.Cfg.Cache.Set(payloadCacheKey(req.RequestID), []byte(payloadHash(req)), ttl)
+	}
+	cc.Log.Infow("Cached response", "request_id", req.RequestID, "ttl", ttl)
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+func newCacheContext(t *testing.T) *shared.Context {
+	t.Helper()
+	cfg := newTestConfig(t)
+	cfg.Env.CacheKeyMode = config.CacheKeyContent
+	cfg.Env.ResultCache = config.ResultCacheSettings{TTL: time.Minute, NegativeTTL: time.Minute}
+	return &shared.Context{Log: zap.NewNop().Sugar(), Cfg: cfg, Hotkey: "validator"}
+}
+
+func TestRequestIDCannotPoisonContentKey(t *testing.T) {
+	cc := newCacheContext(t)
+
+	honest := &shared.VerificationRequest{Model: "m", RequestID: "p1", RequestParams: map[string]interface{}{"prompt": "P"}}
+	forged := &shared.VerificationRequest{Model: "m", RequestID: "content:" + payloadHash(honest), RequestParams: map[string]interface{}{"prompt": "Q"}}
+	storeCache(cc, forged, []byte(`{"verified":false}`))
+
+	resubmitted := &shared.VerificationRequest{Model: "m", RequestID: "p2", RequestParams: honest.RequestParams}
+	if _, key, found := lookupCache(cc, cacheKeys(cc, resubmitted)); found {
+		t.Fatalf("payload P was served the verdict cached by another payload under %q", key)
+	}
+}
+
+func TestRequestIDCannotOverwritePayloadHash(t *testing.T) {
+	cc := newCacheContext(t)
+
+	honest := &shared.VerificationRequest{Model: "m", RequestID: "R", RequestParams: map[string]interface{}{"prompt": "P"}}
+	storeCache(cc, honest, []byte(`{"verified":true}`))
+
+	forged := &shared.VerificationRequest{Model: "m", RequestID: payloadCacheKey(honest.RequestID), RequestParams: map[string]interface{}{"prompt": "Q"}}
+	storeCache(cc, forged, []byte(`{"verified":false}`))
+
+	if hash, _ := cc.Cfg.Cache.Get(payloadCacheKey(honest.RequestID)); string(hash) != payloadHash(honest) {
+		t.Fatalf("payload hash of %q was overwritten with %q", honest.RequestID, hash)
+	}
+	if requestIDConflict(cc, honest) {
+		t.Fatalf("resubmitting %q with its original payload was reported as a conflict", honest.RequestID)
+	}
+}
@@ -0,0 +1,119 @@
+package routes
+
+import (
+	"encoding/json"
+	"time"
+
+	"api/internal/config"
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+// canaryCase is a single probe with the verdict it is expected to produce
+type canaryCase struct {
+	name           string
+	request        shared.VerificationRequest
+	expectVerified bool
+}
+
+// StartCanaryRoutine periodically runs known requests through the verification
+// pipeline and alerts when one gets an unexpected verdict or exceeds its
+// latency budget. Cases come from the synthetic payload library, falling back
+// to the configured known-good request when the library is empty.
+func StartCanaryRoutine(cfg *config.Config, log *zap.SugaredLogger) {
+	var fallback []canaryCase
+	if cfg.Env.CanaryRequest != "" {
+		var request shared.VerificationRequest
+		if err := json.Unmarshal([]byte(cfg.Env.CanaryRequest), &request); err != nil {
+			log.Errorw("Invalid canary request, ignoring it", "error", err.Error())
+		} else {
+			fallback = append(fallback, canaryCase{name: "configured", request: request, expectVerified: true})
+		}
+	}
+
+	canaryLog := log.With("phase", "canary")
+	ticker := time.NewTicker(cfg.Env.CanaryInterval)
+	go func() {
+		for range ticker.C {
+			cases := canaryCases(cfg)
+			if len(cases) == 0 {
+				cases = fallback
+			}
+			if len(cases) == 0 {
+				continue
+			}
+
+			healthy := true
+			for _, c := range cases {
+				if !runCanary(cfg, canaryLog.With("model", c.request.Model, "case", c.name), c) {
+					healthy = false
+				}
+			}
+			if healthy {
+				metrics.CanaryHealthy.Set(1)
+			} else {
+				metrics.CanaryHealthy.Set(0)
+			}
+		}
+	}()
+}
+
+// canaryCases builds probes from the synthetic payload library
+func canaryCases(cfg *config.Config) []canaryCase {
+	var cases []canaryCase
+	for _, payload := range cfg.Payloads.All() {
+		var request shared.VerificationRequest
+		if err := json.Unmarshal(payload.Request, &request); err != nil {
+			continue
+		}
+		cases = append(cases, canaryCase{name: payload.Name, request: request, expectVerified: payload.ExpectVerified})
+	}
+	return cases
+}
+
+// runCanary runs a single probe and reports whether it behaved as expected
+func runCanary(cfg *config.Config, log *zap.SugaredLogger, c canaryCase) bool {
+	cc := &shared.Context{Log: log, Reqid: "canary", Cfg: cfg, Canary: true}
+	request := c.request
+	// Without a request ID the canary is never served from the result cache
+	request.RequestID = ""
+	request.Tags = nil
+	startTime := time.Now()
+
+	body, err := runVerification(cc, &request, verifyOptions{SkipDedup: true})
+	duration := time.Since(startTime)
+	metrics.CanaryLatency.Set(duration.Seconds())
+
+	if err != nil {
+		metrics.CanaryRuns.WithLabelValues("error").Inc()
+		log.Errorw("Canary verification failed", "error", err.Error(), "duration_ms", duration.Milliseconds())
+		return false
+	}
+
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Verified != c.expectVerified {
+		metrics.CanaryRuns.WithLabelValues("unexpected").Inc()
+		log.Errorw("Canary verification returned unexpected verdict",
+			"expected", c.expectVerified,
+			"verified", response.Verified,
+			"cause", response.Cause,
+			"error", response.Error,
+			"duration_ms", duration.Milliseconds(),
+		)
+		return false
+	}
+
+	if duration > cfg.Env.CanaryMaxLatency {
+		metrics.CanaryRuns.WithLabelValues("slow").Inc()
+		log.Errorw("Canary verification exceeded latency budget",
+			"duration_ms", duration.Milliseconds(),
+			"max_ms", cfg.Env.CanaryMaxLatency.Milliseconds(),
+		)
+		return false
+	}
+
+	metrics.CanaryRuns.WithLabelValues("ok").Inc()
+	return true
+}
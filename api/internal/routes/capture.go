@@ -0,0 +1,197 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// maxCaptureTTL bounds how long a capture can stay registered
+const maxCaptureTTL = 24 * time.Hour
+
+// CaptureBodies logs redacted request and response bodies of requests matching
+// a registered capture target. Captures are only honoured when DEBUG is set.
+func CaptureBodies(cfg *config.Config) echo.MiddlewareFunc {
+	return middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+		Skipper: func(c echo.Context) bool {
+			if !cfg.Env.Debug || cfg.Captures.Empty() {
+				return true
+			}
+			cc := c.(*shared.Context)
+			if cfg.Captures.Matches(config.CaptureRoute, c.Path()) {
+				cc.Capture = true
+				return false
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return true
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			var peek struct {
+				RequestID string `json:"request_id"`
+			}
+			_ = json.Unmarshal(body, &peek)
+			cc.Capture = cfg.Captures.Matches(config.CaptureRequestID, peek.RequestID)
+			return !cc.Capture
+		},
+		Handler: func(c echo.Context, reqBody, resBody []byte) {
+			cc := c.(*shared.Context)
+			cc.Log.Infow("Captured request",
+				"route", c.Path(),
+				"status", c.Response().Status,
+				"request_body", redactBody(cfg.Env, reqBody),
+				"response_body", redactBody(cfg.Env, resBody),
+			)
+		},
+	})
+}
+
+// redactBody masks configured fields in a JSON body and truncates it to the
+// capture size limit
+func redactBody(env config.Environment, body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err == nil {
+		if redacted, err := json.Marshal(redactValue(env.CaptureRedact, v)); err == nil {
+			body = redacted
+		}
+	}
+
+	if len(body) > env.CaptureMaxBytes {
+		return string(body[:env.CaptureMaxBytes]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+func redactValue(fields []string, v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			if isRedacted(fields, key) {
+				t[key] = "[REDACTED]"
+				continue
+			}
+			t[key] = redactValue(fields, value)
+		}
+	case []any:
+		for i, value := range t {
+			t[i] = redactValue(fields, value)
+		}
+	}
+	return v
+}
+
+func isRedacted(fields []string, key string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListCaptures handler for listing active body captures on this replica
+func ListCaptures(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"enabled":  cc.Cfg.Env.Debug,
+		"captures": cc.Cfg.Captures.All(),
+	})
+}
+
+// AddCapture handler for registering a body capture for a route or request ID
+func AddCapture(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	if !cc.Cfg.Env.Debug {
+		return c.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "Body capture requires DEBUG to be enabled"))
+	}
+
+	kind, value, req, errResp := parseCaptureRequest(cc)
+	if errResp != nil {
+		return c.JSON(http.StatusBadRequest, errResp)
+	}
+
+	ttl := 15 * time.Minute
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 || parsed > maxCaptureTTL {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "ttl must be a positive duration of at most 24h"))
+		}
+		ttl = parsed
+	}
+
+	target := cc.Cfg.Captures.Add(kind, value, ttl)
+	cc.Log.Infow("Body capture registered", "kind", kind, "value", value, "expires_at", target.ExpiresAt)
+
+	return c.JSON(http.StatusOK, target)
+}
+
+// RemoveCapture handler for removing a body capture
+func RemoveCapture(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	kind, value, _, errResp := parseCaptureRequest(cc)
+	if errResp != nil {
+		return c.JSON(http.StatusBadRequest, errResp)
+	}
+
+	if !cc.Cfg.Captures.Remove(kind, value) {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Capture not found"))
+	}
+
+	cc.Log.Infow("Body capture removed", "kind", kind, "value", value)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Capture removed successfully",
+	})
+}
+
+// parseCaptureRequest binds a capture request naming exactly one target
+func parseCaptureRequest(cc *shared.Context) (string, string, shared.CaptureRequest, *shared.ErrorResponse) {
+	var req shared.CaptureRequest
+	if err := cc.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		errResp := errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format")
+		return "", "", req, &errResp
+	}
+
+	switch {
+	case req.Route != "" && req.RequestID == "":
+		return config.CaptureRoute, req.Route, req, nil
+	case req.RequestID != "" && req.Route == "":
+		return config.CaptureRequestID, req.RequestID, req, nil
+	default:
+		errResp := errorResponse(cc, shared.CodeMissingField, "exactly one of route or request_id is required")
+		return "", "", req, &errResp
+	}
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// DecompressBody inflates gzip-encoded request bodies, rejecting bodies that
+// are not gzip as bad requests. It runs before the body limit, so the limit
+// applies to the inflated size.
+func DecompressBody() echo.MiddlewareFunc {
+	decompress := middleware.Decompress()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := decompress(next)
+		return func(c echo.Context) error {
+			err := handler(c)
+			if errors.Is(err, gzip.ErrHeader) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return echo.NewHTTPError(http.StatusBadRequest, "Request body is not valid gzip")
+			}
+			return err
+		}
+	}
+}
+
+// CompressResponses gzips responses of at least minLength bytes for clients
+// that accept it. Metrics and profiles are left alone, as they compress
+// themselves.
+func CompressResponses(minLength int) echo.MiddlewareFunc {
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		MinLength: minLength,
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/metrics" || strings.HasPrefix(c.Path(), "/admin/debug/pprof")
+		},
+	})
+}
+
+// gzipBody compresses a backend request body. Responses need no counterpart:
+// the transport asks for gzip and inflates replies itself.
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"api/internal/config"
+	"api/internal/shared"
+)
+
+// useConsensus reports whether the request should be verified by multiple
+// backends. Fanning out multiplies backend load, so the X-Verify-Consensus
+// header is only honoured for keys holding the consensus scope.
+func useConsensus(cc *shared.Context, req *shared.VerificationRequest) bool {
+	if len(cc.Cfg.Env.ConsensusBackends) == 0 {
+		return false
+	}
+
+	if strings.ToLower(cc.Request().Header.Get("X-Verify-Consensus")) == "true" && cc.Key.HasScope(config.ScopeConsensus) {
+		return true
+	}
+
+	return slices.Contains(cc.Cfg.Env.ConsensusModels, req.Model)
+}
+
+// forwardConsensus sends the request to every consensus backend and combines their verdicts
+func forwardConsensus(cc *shared.Context, req *shared.VerificationRequest) ([]byte, error) {
+	backends := cc.Cfg.Env.ConsensusBackends
+	responses := make([]*shared.VerificationResponse, len(backends))
+	verdicts := make([]shared.BackendVerdict, len(backends))
+
+	var wg sync.WaitGroup
+	for i, backend := range backends {
+		wg.Add(1)
+		go func(i int, backend string) {
+			defer wg.Done()
+			verdicts[i].Backend = backend
+
+			body, err := forwardToBackend(cc, req, backend+"/verify", req.Model)
+			if err != nil {
+				verdicts[i].Error = err.Error()
+				return
+			}
+
+			var response shared.VerificationResponse
+			if err := json.Unmarshal(body, &response); err != nil {
+				verdicts[i].Error = "invalid backend response: " + err.Error()
+				return
+			}
+
+			responses[i] = &response
+			verdicts[i].Verified = response.Verified
+			verdicts[i].Cause = response.Cause
+			verdicts[i].Error = response.Error
+		}(i, backend)
+	}
+	wg.Wait()
+
+	var verifiedCount, answered int
+	var base *shared.VerificationResponse
+	for i, response := range responses {
+		if response == nil {
+			continue
+		}
+		answered++
+		if response.Verified {
+			verifiedCount++
+		}
+		// Prefer a dissenting response as the base so its cause is surfaced
+		if base == nil || (!response.Verified && base.Verified) {
+			base = responses[i]
+		}
+	}
+
+	if answered == 0 {
+		return nil, backendFailure{fmt.Errorf("no consensus backend returned a verdict")}
+	}
+
+	var verified bool
+	switch cc.Cfg.Env.ConsensusMode {
+	case "unanimous":
+		verified = verifiedCount == len(backends)
+	default:
+		verified = verifiedCount*2 > len(backends)
+	}
+
+	result := *base
+	result.Verified = verified
+	result.Consensus = &shared.Consensus{
+		Mode:     cc.Cfg.Env.ConsensusMode,
+		Verdicts: verdicts,
+	}
+	if verified {
+		result.Error = ""
+		result.Cause = ""
+	} else if result.Cause == "" {
+		result.Cause = fmt.Sprintf("consensus not reached: %d of %d backends verified", verifiedCount, len(backends))
+	}
+
+	for _, v := range verdicts {
+		_, err := cc.Cfg.SqlClient.Exec(
+			"INSERT INTO consensus_verdicts (request_id, model, backend, verified, cause, error) VALUES (?, ?, ?, ?, ?, ?)",
+			req.RequestID, req.Model, v.Backend, v.Verified, v.Cause, v.Error,
+		)
+		if err != nil {
+			cc.Log.Warnw("Failed to record consensus verdict", "error", err.Error(), "request_id", req.RequestID, "backend", v.Backend)
+		}
+	}
+
+	cc.Log.Infow("Consensus verdict",
+		"request_id", req.RequestID,
+		"model", req.Model,
+		"mode", cc.Cfg.Env.ConsensusMode,
+		"verified", verified,
+		"verified_count", verifiedCount,
+		"backends", len(backends),
+	)
+
+	return json.Marshal(result)
+}
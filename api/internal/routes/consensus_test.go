@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestConsensusHeaderRequiresScope(t *testing.T) {
+	cfg := &config.Config{Env: config.Environment{ConsensusBackends: []string{"http://a", "http://b"}, ConsensusModels: []string{"listed"}}}
+
+	for _, tc := range []struct {
+		model  string
+		scopes []string
+		header bool
+		want   bool
+	}{
+		{"other", []string{config.ScopeVerify}, true, false},
+		{"other", []string{config.ScopeVerify, config.ScopeConsensus}, true, true},
+		{"other", []string{config.ScopeAll}, true, true},
+		{"other", []string{config.ScopeVerify, config.ScopeConsensus}, false, false},
+		{"listed", []string{config.ScopeVerify}, false, true},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/verify", nil)
+		if tc.header {
+			req.Header.Set("X-Verify-Consensus", "true")
+		}
+		cc := &shared.Context{
+			Context: echo.New().NewContext(req, httptest.NewRecorder()),
+			Cfg:     cfg,
+			Key:     config.KeyInfo{Scopes: tc.scopes},
+		}
+		if got := useConsensus(cc, &shared.VerificationRequest{Model: tc.model}); got != tc.want {
+			t.Errorf("model %s with scopes %v and header %v: consensus = %v, want %v", tc.model, tc.scopes, tc.header, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"api/internal/metagraph"
+	"api/internal/shared"
+)
+
+// parseDeadline reads the X-Deadline header, either an RFC 3339 time or the
+// block height by which the validator needs its result. Block heights are
+// converted with the current chain height, so they need SUBTENSOR_URL.
+func parseDeadline(cc *shared.Context, header string) (time.Time, error) {
+	if header == "" {
+		return time.Time{}, nil
+	}
+
+	if deadline, err := time.Parse(time.RFC3339, header); err == nil {
+		return deadline, nil
+	}
+
+	block, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || block <= 0 {
+		return time.Time{}, fmt.Errorf("X-Deadline must be an RFC 3339 time or a block height")
+	}
+	current := cc.Cfg.Chain.Block()
+	if !cc.Cfg.Chain.Enabled() || current == 0 {
+		return time.Time{}, fmt.Errorf("X-Deadline block heights are not supported without a chain connection")
+	}
+	return time.Now().Add(time.Duration(block-current) * metagraph.BlockTime), nil
+}
@@ -0,0 +1,92 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+var startedAt = time.Now()
+
+// MountPprof serves the net/http/pprof profiles on group, which must carry the
+// admin authorization
+func MountPprof(group *echo.Group) {
+	group.GET("/", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	group.GET("/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	group.GET("/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	group.GET("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	group.POST("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	group.GET("/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	// Named profiles such as heap, goroutine and allocs
+	group.GET("/:name", func(c echo.Context) error {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Response(), c.Request())
+		return nil
+	})
+}
+
+// DebugStats handler for a snapshot of goroutines, heap, cache, database pool
+// and backend load
+func DebugStats(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := shared.DebugStatsResponse{
+		Goroutines: runtime.NumGoroutine(),
+		Heap: shared.HeapStats{
+			AllocBytes:   mem.HeapAlloc,
+			InUseBytes:   mem.HeapInuse,
+			SysBytes:     mem.HeapSys,
+			Objects:      mem.HeapObjects,
+			NumGC:        mem.NumGC,
+			PauseTotalMs: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+			NextGCBytes:  mem.NextGC,
+		},
+		Backend: shared.BackendStats{
+			Inflight: cc.Cfg.Workers.Inflight(),
+			Queued:   cc.Cfg.Workers.Waiting(),
+		},
+		Jobs: shared.JobQueueStats{
+			Queued:   len(cc.Cfg.JobQueue),
+			Capacity: cap(cc.Cfg.JobQueue),
+		},
+		Uptime: time.Since(startedAt).Round(time.Second).String(),
+	}
+	if mem.LastGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC))
+		stats.Heap.LastGC = &lastGC
+	}
+
+	switch cache := cc.Cfg.Cache.(type) {
+	case *config.VerificationCache:
+		entries := cache.Len()
+		stats.Cache = shared.CacheStats{Backend: "memory", Entries: &entries}
+	case *config.RedisCache:
+		stats.Cache = shared.CacheStats{Backend: "redis"}
+	}
+
+	db := cc.Cfg.SqlClient.Stats()
+	stats.Database = shared.DatabaseStats{
+		OpenConnections: db.OpenConnections,
+		InUse:           db.InUse,
+		Idle:            db.Idle,
+		MaxOpen:         db.MaxOpenConnections,
+		WaitCount:       db.WaitCount,
+		WaitDuration:    db.WaitDuration.String(),
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"encoding/json"
+
+	"api/internal/shared"
+)
+
+// dedupEntry is the cached result of a verification, keyed by hotkey and payload
+type dedupEntry struct {
+	RequestID string          `json:"request_id"`
+	Response  json.RawMessage `json:"response"`
+}
+
+func dedupKey(cc *shared.Context, req *shared.VerificationRequest) string {
+	return "dedup:" + cc.Hotkey + ":" + payloadHash(req)
+}
+
+// lookupDedup returns a prior result for a content-identical request from the same hotkey
+func lookupDedup(cc *shared.Context, req *shared.VerificationRequest, opts verifyOptions) ([]byte, bool) {
+	if cc.Cfg.Runtime().DedupWindow <= 0 || opts.SkipDedup {
+		return nil, false
+	}
+
+	cached, found := cc.Cfg.Cache.Get(dedupKey(cc, req))
+	if !found {
+		return nil, false
+	}
+
+	var entry dedupEntry
+	if err := json.Unmarshal(cached, &entry); err != nil {
+		cc.Log.Warnw("Failed to unmarshal dedup entry", "error", err.Error(), "request_id", req.RequestID)
+		return nil, false
+	}
+
+	if entry.RequestID == req.RequestID {
+		return nil, false
+	}
+
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(entry.Response, &response); err != nil {
+		cc.Log.Warnw("Failed to unmarshal deduplicated response", "error", err.Error(), "request_id", req.RequestID)
+		return nil, false
+	}
+
+	// The cached result belongs to the original request_id
+	response.RequestID = req.RequestID
+	response.Deduplicated = true
+	response.DedupOf = entry.RequestID
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, false
+	}
+
+	cc.Log.Infow("Serving deduplicated result",
+		"request_id", req.RequestID,
+		"dedup_of", entry.RequestID,
+		"hotkey", cc.Hotkey,
+	)
+
+	return body, true
+}
+
+// storeDedup remembers a result so content-identical resubmissions can reuse
+// it, for no longer than the result cache would keep the same outcome
+func storeDedup(cc *shared.Context, req *shared.VerificationRequest, response []byte) {
+	window := cc.Cfg.Runtime().DedupWindow
+	if window <= 0 {
+		return
+	}
+
+	var result shared.VerificationResponse
+	if err := json.Unmarshal(response, &result); err != nil {
+		cc.Log.Warnw("Not deduplicating unreadable response", "error", err.Error(), "request_id", req.RequestID)
+		return
+	}
+	ttl, ok := cc.Cfg.Env.ResultCache.For(result.Verified, result.Error)
+	if !ok {
+		return
+	}
+
+	entry, err := json.Marshal(dedupEntry{RequestID: req.RequestID, Response: response})
+	if err != nil {
+		cc.Log.Warnw("Failed to marshal dedup entry", "error", err.Error(), "request_id", req.RequestID)
+		return
+	}
+
+	cc.Cfg.Cache.Set(dedupKey(cc, req), entry, min(window, ttl))
+}
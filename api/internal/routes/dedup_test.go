@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+func TestDedupServesCallerRequestID(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.SetRuntime(&config.Runtime{DedupWindow: time.Minute})
+	cfg.Env.ResultCache = config.ResultCacheSettings{TTL: time.Minute}
+	cc := &shared.Context{Log: zap.NewNop().Sugar(), Cfg: cfg, Hotkey: "validator"}
+
+	original := &shared.VerificationRequest{Model: "m", RequestID: "r1"}
+	storeDedup(cc, original, []byte(`{"request_id":"r1","verified":true}`))
+
+	resubmitted := &shared.VerificationRequest{Model: "m", RequestID: "r2"}
+	body, found := lookupDedup(cc, resubmitted, verifyOptions{})
+	if !found {
+		t.Fatal("expected a deduplicated result")
+	}
+
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.RequestID != "r2" {
+		t.Errorf("request_id = %q, want r2", response.RequestID)
+	}
+	if !response.Deduplicated || response.DedupOf != "r1" {
+		t.Errorf("deduplicated = %v, dedup_of = %q, want true, r1", response.Deduplicated, response.DedupOf)
+	}
+	if !response.Verified {
+		t.Error("cached verdict was not preserved")
+	}
+}
+
+func TestDedupFollowsResultCachePolicy(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.SetRuntime(&config.Runtime{DedupWindow: time.Minute})
+	cfg.Env.ResultCache = config.ResultCacheSettings{TTL: time.Minute, ErrorTTL: time.Minute, SkipErrors: true}
+	cc := &shared.Context{Log: zap.NewNop().Sugar(), Cfg: cfg, Hotkey: "validator"}
+
+	for name, response := range map[string]string{
+		"backend error":    `{"request_id":"r1","verified":false,"error":"backend unavailable"}`,
+		"negative verdict": `{"request_id":"r1","verified":false}`,
+	} {
+		original := &shared.VerificationRequest{Model: name, RequestID: "r1"}
+		storeDedup(cc, original, []byte(response))
+
+		resubmitted := &shared.VerificationRequest{Model: name, RequestID: "r2"}
+		if _, found := lookupDedup(cc, resubmitted, verifyOptions{}); found {
+			t.Errorf("%s was replayed although the result cache would not keep it", name)
+		}
+	}
+}
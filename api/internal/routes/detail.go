@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Response detail levels for verification results
+const (
+	DetailMinimal  = "minimal"
+	DetailStandard = "standard"
+	DetailFull     = "full"
+)
+
+// minimalFields are the only fields returned at the minimal detail level
+var minimalFields = map[string]bool{
+	"request_id":   true,
+	"verified":     true,
+	"error":        true,
+	"cause":        true,
+	"deduplicated": true,
+	"dedup_of":     true,
+}
+
+// parseDetail validates the detail query parameter, defaulting to full
+func parseDetail(detail string) (string, error) {
+	switch detail {
+	case "":
+		return DetailFull, nil
+	case DetailMinimal, DetailStandard, DetailFull:
+		return detail, nil
+	default:
+		return "", fmt.Errorf("detail must be minimal, standard or full")
+	}
+}
+
+// trimResponse reduces a verification result to the requested detail level.
+// Standard leaves out the per-backend verdicts of a consensus result; minimal
+// keeps only the verdict. The untrimmed result is what gets cached and persisted.
+func trimResponse(body []byte, detail string) []byte {
+	if detail == DetailFull {
+		return body
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+
+	for key, value := range fields {
+		switch {
+		case detail == DetailMinimal && !minimalFields[key]:
+			delete(fields, key)
+		case detail == DetailStandard && key == "consensus":
+			var consensus map[string]json.RawMessage
+			if json.Unmarshal(value, &consensus) == nil && consensus != nil {
+				delete(consensus, "verdicts")
+				fields[key], _ = json.Marshal(consensus)
+			}
+		}
+	}
+
+	trimmed, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return trimmed
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTrimResponseChunkDetail(t *testing.T) {
+	body := []byte(`{"verified":true,"chunk_scores":[0.9,0.8],"logprob_distances":[0.1,0.2]}`)
+
+	for detail, keep := range map[string]bool{
+		DetailFull:     true,
+		DetailStandard: true,
+		DetailMinimal:  false,
+	} {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimResponse(body, detail), &fields); err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range []string{"chunk_scores", "logprob_distances"} {
+			if _, ok := fields[key]; ok != keep {
+				t.Errorf("%s response has %s = %v, want %v", detail, key, ok, keep)
+			}
+		}
+	}
+}
+
+func TestTrimResponseConsensusVerdicts(t *testing.T) {
+	body := []byte(`{"verified":true,"consensus":{"mode":"majority","verdicts":[{"backend":"a","verified":true}]}}`)
+
+	for detail, keep := range map[string]bool{
+		DetailFull:     true,
+		DetailStandard: false,
+	} {
+		var response struct {
+			Consensus map[string]json.RawMessage `json:"consensus"`
+		}
+		if err := json.Unmarshal(trimResponse(body, detail), &response); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := response.Consensus["mode"]; !ok {
+			t.Errorf("%s response dropped the consensus mode", detail)
+		}
+		if _, ok := response.Consensus["verdicts"]; ok != keep {
+			t.Errorf("%s response has consensus verdicts = %v, want %v", detail, ok, keep)
+		}
+	}
+}
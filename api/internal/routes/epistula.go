@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api/internal/config"
+	"api/internal/hotkey"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CaptureSignedBody keeps a copy of the body of Epistula-signed requests, since
+// the signature covers the raw bytes and binding consumes them
+func CaptureSignedBody(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		cc := c.(*shared.Context)
+		if !cc.Cfg.Env.EpistulaAuth || c.Request().Header.Get(hotkey.HeaderEpistulaSignature) == "" {
+			return next(c)
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Failed to read request body"))
+		}
+		c.Request().Body = io.NopCloser(bytes.NewReader(body))
+		cc.RawBody = body
+
+		return next(c)
+	}
+}
+
+// epistulaKey authenticates a request signed with the sender's hotkey
+func epistulaKey(cc *shared.Context) (config.KeyInfo, error) {
+	headers := cc.Request().Header
+	signedBy := headers.Get(hotkey.HeaderEpistulaSignedBy)
+	signedFor := headers.Get(hotkey.HeaderEpistulaSignedFor)
+	uuid := headers.Get(hotkey.HeaderEpistulaUuid)
+	timestamp := headers.Get(hotkey.HeaderEpistulaTimestamp)
+
+	if !cc.Cfg.Env.EpistulaAuth {
+		return config.KeyInfo{}, fmt.Errorf("signature authentication is not enabled")
+	}
+
+	if headers.Get(hotkey.HeaderEpistulaVersion) != hotkey.EpistulaVersion {
+		return config.KeyInfo{}, fmt.Errorf("unsupported Epistula version, expected %s", hotkey.EpistulaVersion)
+	}
+
+	if uuid == "" || timestamp == "" {
+		return config.KeyInfo{}, fmt.Errorf("missing Epistula headers")
+	}
+
+	if signedFor != cc.Cfg.Env.EpistulaReceiver {
+		return config.KeyInfo{}, fmt.Errorf("request is not signed for this service")
+	}
+
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return config.KeyInfo{}, fmt.Errorf("invalid Epistula timestamp")
+	}
+	skew := time.Since(time.UnixMilli(ms))
+	if skew > cc.Cfg.Env.EpistulaMaxSkew || skew < -cc.Cfg.Env.EpistulaMaxSkew {
+		return config.KeyInfo{}, fmt.Errorf("request timestamp outside the allowed window")
+	}
+
+	message := hotkey.EpistulaMessage(cc.RawBody, uuid, timestamp, signedFor)
+	if err := hotkey.Verify(signedBy, message, headers.Get(hotkey.HeaderEpistulaSignature)); err != nil {
+		return config.KeyInfo{}, fmt.Errorf("invalid request signature")
+	}
+
+	// Only checked once the signature is valid, so others cannot burn a sender's nonces
+	if !cc.Cfg.Nonces.Use(signedBy+"|"+uuid, 2*cc.Cfg.Env.EpistulaMaxSkew) {
+		return config.KeyInfo{}, fmt.Errorf("replayed request")
+	}
+
+	key, err := cc.Cfg.Keys.LookupSigner(signedBy)
+	if err != nil {
+		return config.KeyInfo{}, fmt.Errorf("hotkey not registered")
+	}
+
+	return key, nil
+}
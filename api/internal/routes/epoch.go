@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"api/internal/metagraph"
+	"api/internal/metrics"
+	"api/internal/shared"
+)
+
+// checkEpochQuota enforces the per-hotkey request quota for the current subnet epoch
+func checkEpochQuota(cc *shared.Context, model string) (*shared.VerifyErrorResponse, int) {
+	quota := cc.Cfg.Runtime().EpochQuota
+	if quota <= 0 || !cc.Cfg.Chain.Enabled() {
+		return nil, 0
+	}
+
+	block := cc.Cfg.Chain.Block()
+	if block == 0 {
+		// No block height yet, so there is no epoch to count against
+		return nil, 0
+	}
+
+	epoch, remaining := cc.Cfg.Chain.Epoch(block)
+	if allowed, used := cc.Cfg.Epochs.Take(cc.Hotkey, epoch, quota); !allowed {
+		cc.Log.Warnw("Epoch quota exceeded", "hotkey", cc.Hotkey, "epoch", epoch, "used", used)
+		metrics.VerifyErrors.WithLabelValues(model, "epoch_quota").Inc()
+		errResp := verifyError(cc, shared.CodeQuotaExceeded, "Epoch quota exceeded")
+		errResp.EpochQuotaError = &shared.EpochQuotaError{Epoch: epoch, Quota: quota, ResetsInBlocks: remaining}
+		retryAfter(cc, &errResp.ErrorResponse, metagraph.BlockTime*time.Duration(remaining))
+		return errResp, http.StatusTooManyRequests
+	}
+
+	return nil, 0
+}
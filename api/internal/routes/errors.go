@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errorResponse builds the error body for a request, applying the operator's
+// message template for the code
+func errorResponse(cc *shared.Context, code shared.ErrorCode, message string) shared.ErrorResponse {
+	resp := shared.ErrorResponse{Code: code, Message: message}
+	if cc.Reqid != "" {
+		resp.RequestID = "req_" + cc.Reqid
+	}
+
+	var acceptLanguage string
+	if cc.Context != nil {
+		acceptLanguage = cc.Request().Header.Get("Accept-Language")
+	}
+	if t, ok := cc.Cfg.Env.Messages.Lookup(string(code), acceptLanguage); ok {
+		resp.Message = t.Render(string(code), message, resp.RequestID)
+		resp.HelpURL = t.HelpURL
+	}
+	return resp
+}
+
+// verifyError builds the error body for a verification request
+func verifyError(cc *shared.Context, code shared.ErrorCode, message string) *shared.VerifyErrorResponse {
+	return &shared.VerifyErrorResponse{ErrorResponse: errorResponse(cc, code, message)}
+}
+
+// retryAfter tells a throttled client when to retry, in the Retry-After header
+// and the body's retry_in_ms. The wait grows with each consecutive rejection
+// of the hotkey and is never shorter than floor.
+func retryAfter(cc *shared.Context, resp *shared.ErrorResponse, floor time.Duration) {
+	wait := cc.Cfg.Backoff.Next(cc.Hotkey, floor)
+	cc.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	resp.RetryInMs = wait.Milliseconds()
+}
+
+// backendFailure marks an error as caused by the verifier backend rather than
+// by the proxy itself
+type backendFailure struct {
+	error
+}
+
+func (f backendFailure) Unwrap() error {
+	return f.error
+}
+
+// verificationErrorStatus maps a failed verification to its status and code:
+// shed load is 503, backend timeouts and missed deadlines are 504, other
+// backend failures and malformed backend answers 502 and anything else is a proxy fault and stays 500
+func verificationErrorStatus(err error) (int, shared.ErrorCode) {
+	if errors.Is(err, config.ErrBackendSaturated) {
+		return http.StatusServiceUnavailable, shared.CodeQueueFull
+	}
+	if errors.Is(err, config.ErrDeadlinePassed) {
+		return http.StatusGatewayTimeout, shared.CodeDeadlineExceeded
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return http.StatusGatewayTimeout, shared.CodeBackendTimeout
+	}
+	if errors.As(err, &invalidBackendResponse{}) {
+		return http.StatusBadGateway, shared.CodeInvalidBackendResp
+	}
+	if errors.As(err, &backendFailure{}) {
+		return http.StatusBadGateway, shared.CodeBackendUnavailable
+	}
+	return http.StatusInternalServerError, shared.CodeInternal
+}
+
+// statusErrorCode returns the generic error code for an HTTP status, for
+// helpers that only report a status
+func statusErrorCode(status int) shared.ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return shared.CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return shared.CodeUnauthorized
+	case http.StatusForbidden:
+		return shared.CodeForbidden
+	case http.StatusNotFound:
+		return shared.CodeNotFound
+	case http.StatusConflict:
+		return shared.CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return shared.CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return shared.CodeRateLimited
+	case http.StatusBadGateway:
+		return shared.CodeBackendUnavailable
+	case http.StatusGatewayTimeout:
+		return shared.CodeBackendTimeout
+	default:
+		return shared.CodeInternal
+	}
+}
+
+// HTTPErrorHandler renders errors raised outside of the handlers, such as
+// unknown routes or oversized bodies, in the same envelope as handler errors
+func HTTPErrorHandler(cfg *config.Config) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status := http.StatusInternalServerError
+		message := http.StatusText(status)
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			status = he.Code
+			if m, ok := he.Message.(string); ok {
+				message = m
+			} else {
+				message = http.StatusText(status)
+			}
+		}
+
+		// Errors from before the request context was set up still get templates
+		cc, ok := c.(*shared.Context)
+		if !ok {
+			cc = &shared.Context{Context: c, Cfg: cfg}
+		}
+		resp := errorResponse(cc, statusErrorCode(status), message)
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = c.JSON(status, resp)
+		}
+		if err != nil {
+			c.Logger().Error(err)
+		}
+	}
+}
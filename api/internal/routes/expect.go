@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerifyExpect handler for announcing request IDs ahead of their verification,
+// so the proxy holds backend wait queue places for them through a burst
+func VerifyExpect(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	if valid, err := validateAPIKey(cc); !valid {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, err.Error()))
+	}
+
+	var req shared.ExpectRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if len(req.RequestIDs) == 0 {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "request_ids is required"))
+	}
+	for _, id := range req.RequestIDs {
+		if id == "" {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "request_ids must not be empty"))
+		}
+	}
+
+	deadline, err := parseDeadline(cc, req.Deadline)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	// An announced request is expected until its deadline, but no longer than the TTL
+	expiresAt := time.Now().Add(cc.Cfg.Env.ExpectTTL)
+	if !deadline.IsZero() {
+		if !deadline.After(time.Now()) {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "deadline has already passed"))
+		}
+		if deadline.Before(expiresAt) {
+			expiresAt = deadline
+		}
+	}
+
+	expectation := config.Expectation{Model: req.Model, Deadline: deadline, ExpiresAt: expiresAt}
+	if !cc.Cfg.Expected.Add(cc.Hotkey, req.RequestIDs, expectation) {
+		errResp := errorResponse(cc, shared.CodeQueueFull, "Too many expected requests, retry later")
+		retryAfter(cc, &errResp, 0)
+		return c.JSON(http.StatusServiceUnavailable, errResp)
+	}
+
+	cc.Log.Infow("Requests announced", "hotkey", cc.Hotkey, "count", len(req.RequestIDs), "model", req.Model, "expires_at", expiresAt)
+
+	return c.JSON(http.StatusOK, shared.ExpectResponse{
+		Expected:  len(req.RequestIDs),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// claimExpectation correlates a verification with its announcement, if any.
+// The announced deadline applies when the request carries no X-Deadline.
+func claimExpectation(cc *shared.Context, request *shared.VerificationRequest) {
+	if request.RequestID == "" {
+		return
+	}
+	expectation, ok := cc.Cfg.Expected.Claim(cc.Hotkey, request.RequestID)
+	if !ok || (expectation.Model != "" && expectation.Model != request.Model) {
+		return
+	}
+
+	cc.Expected = true
+	if cc.Deadline.IsZero() {
+		cc.Deadline = expectation.Deadline
+	}
+}
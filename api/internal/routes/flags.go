@@ -0,0 +1,171 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// payloadHash hashes the parts of a request that determine its verdict
+func payloadHash(req *shared.VerificationRequest) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	_ = enc.Encode(req.Model)
+	_ = enc.Encode(req.RequestType)
+	_ = enc.Encode(req.RequestParams)
+	_ = enc.Encode(req.RawChunks)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// tokenCount interprets a decoded JSON token field, a number or a list of tokens, as a count
+func tokenCount(v interface{}) (int64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return int64(t), true
+	case []interface{}:
+		return int64(len(t)), true
+	default:
+		return 0, false
+	}
+}
+
+// raiseFlag records a suspicious-pattern flag for a hotkey
+func raiseFlag(cc *shared.Context, reason, details string) {
+	cc.Log.Warnw("Hotkey flagged", "hotkey", cc.Hotkey, "reason", reason, "details", details)
+
+	_, err := cc.Cfg.SqlClient.Exec(
+		"INSERT INTO key_flags (hotkey, reason, details) VALUES (?, ?, ?)",
+		cc.Hotkey, reason, details,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to record key flag", "error", err.Error(), "hotkey", cc.Hotkey)
+	}
+
+	cc.Cfg.Abuse.Flag(cc.Hotkey)
+}
+
+// detectResubmission flags hotkeys that submit the same payload under many request_ids
+func detectResubmission(cc *shared.Context, req *shared.VerificationRequest) {
+	settings := cc.Cfg.Abuse.Settings
+	if req.RequestID == "" || settings.ResubmitThreshold <= 0 {
+		return
+	}
+
+	count := cc.Cfg.Abuse.RecordPayload(cc.Hotkey, payloadHash(req), req.RequestID)
+	if count == settings.ResubmitThreshold {
+		raiseFlag(cc, "payload_resubmission",
+			fmt.Sprintf("identical payload submitted under %d request_ids within %s", count, settings.Window))
+	}
+}
+
+// detectAnomalies inspects a verification result for failure spikes and impossible token counts
+func detectAnomalies(cc *shared.Context, req *shared.VerificationRequest, body []byte) {
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return
+	}
+
+	settings := cc.Cfg.Abuse.Settings
+	rate, samples := cc.Cfg.Abuse.RecordOutcome(cc.Hotkey, response.Verified)
+	if samples >= settings.MinSamples && rate >= settings.FailureRate && cc.Cfg.Abuse.ClaimSpikeFlag(cc.Hotkey) {
+		raiseFlag(cc, "failure_spike",
+			fmt.Sprintf("%.0f%% of %d verifications failed within %s", rate*100, samples, settings.Window))
+	}
+
+	// Negative counts are rejected when the backend response is parsed
+	if response.ResponseTokens == nil {
+		return
+	}
+	responseTokens := *response.ResponseTokens
+	if maxTokens, ok := tokenCount(req.RequestParams["max_tokens"]); ok && maxTokens > 0 && responseTokens > maxTokens {
+		raiseFlag(cc, "impossible_token_count",
+			fmt.Sprintf("request %s reported %d response tokens with max_tokens %d", req.RequestID, responseTokens, maxTokens))
+	}
+}
+
+// ListFlags handler for listing hotkey flags awaiting review
+func ListFlags(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	query := "SELECT id, hotkey, reason, details, created_at, resolved_at FROM key_flags"
+	var args []any
+	if hotkey := c.QueryParam("hotkey"); hotkey != "" {
+		query += " WHERE hotkey = ?"
+		args = append(args, hotkey)
+	} else if include, _ := strconv.ParseBool(c.QueryParam("include_resolved")); !include {
+		query += " WHERE resolved_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC LIMIT 500"
+
+	rows, err := cc.Cfg.SqlClient.Query(query, args...)
+	if err != nil {
+		cc.Log.Errorw("Failed to query key flags", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve flags"))
+	}
+	defer rows.Close()
+
+	flags := []shared.KeyFlag{}
+	for rows.Next() {
+		var flag shared.KeyFlag
+		if err := rows.Scan(&flag.Id, &flag.Hotkey, &flag.Reason, &flag.Details, &flag.CreatedAt, &flag.ResolvedAt); err != nil {
+			cc.Log.Errorw("Failed to scan key flag", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve flags"))
+		}
+		flags = append(flags, flag)
+	}
+
+	return c.JSON(http.StatusOK, flags)
+}
+
+// ResolveFlag handler for clearing a flag after review
+func ResolveFlag(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.ResolveFlagRequest
+	if err := c.Bind(&req); err != nil || req.Id == 0 {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "id is required"))
+	}
+
+	var hotkey string
+	err := cc.Cfg.SqlClient.QueryRow("SELECT hotkey FROM key_flags WHERE id = ? AND resolved_at IS NULL", req.Id).Scan(&hotkey)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Unresolved flag not found"))
+	}
+
+	if _, err := cc.Cfg.SqlClient.Exec("UPDATE key_flags SET resolved_at = ? WHERE id = ?", time.Now(), req.Id); err != nil {
+		cc.Log.Errorw("Failed to resolve key flag", "error", err.Error(), "id", req.Id)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to resolve flag"))
+	}
+
+	var remaining int
+	err = cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM key_flags WHERE hotkey = ? AND resolved_at IS NULL", hotkey).Scan(&remaining)
+	if err == nil && remaining == 0 {
+		cc.Cfg.Abuse.Unflag(hotkey)
+	}
+
+	cc.Log.Infow("Key flag resolved", "id", req.Id, "hotkey", hotkey)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Flag resolved",
+	})
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"testing"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+func TestFailureSpikeFlaggedOncePastMinSamples(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Abuse = config.NewAbuseDetector(config.AbuseSettings{
+		Window:      time.Minute,
+		MinSamples:  3,
+		FailureRate: 0.5,
+	})
+	cc := &shared.Context{Log: zap.NewNop().Sugar(), Cfg: cfg, Hotkey: "miner"}
+	req := &shared.VerificationRequest{RequestID: "r1"}
+
+	// The failure rate only crosses the threshold well after MinSamples
+	for i := 0; i < 3; i++ {
+		detectAnomalies(cc, req, []byte(`{"verified":true}`))
+	}
+	for i := 0; i < 6; i++ {
+		detectAnomalies(cc, req, []byte(`{"verified":false}`))
+	}
+
+	var count int
+	if err := cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM key_flags WHERE hotkey = ? AND reason = ?", "miner", "failure_spike").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("failure_spike flags = %d, want 1", count)
+	}
+}
@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"api/internal/config"
+	"api/internal/shared"
+	"api/internal/validation"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Seeds live in testdata as plain JSON so request and response bodies captured
+// with /admin/captures can be dropped in as they are.
+func addSeeds(f *testing.F, dir string) {
+	files, err := filepath.Glob(filepath.Join("testdata", dir, "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		body, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(body)
+	}
+}
+
+var (
+	fuzzQuarantine = config.QuarantineSettings{MaxChunks: 1000, MaxFieldBytes: 1 << 20, MaxDepth: 32}
+	fuzzSanitize   = config.SanitizeSettings{ControlChars: true, InvalidUTF8: true, MaxFieldBytes: 64}
+)
+
+// FuzzVerificationRequest drives attacker-controlled bodies through the request
+// binder and every check run on a request before it is forwarded
+func FuzzVerificationRequest(f *testing.F) {
+	addSeeds(f, "requests")
+	f.Add([]byte(`{"raw_chunks":[{"x":"\xff\xfe"}]}`))
+	f.Add([]byte(`{"request_params":null,"raw_chunks":[null]}`))
+
+	e := echo.New()
+	f.Fuzz(func(t *testing.T, body []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/verify", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var request shared.VerificationRequest
+		if err := c.Bind(&request); err != nil {
+			return
+		}
+
+		_ = validateTags(request.Tags)
+		_ = payloadHash(&request)
+		if reason := inspectPayload(fuzzQuarantine, &request); reason != "" {
+			return
+		}
+		_ = validation.Request(&request, fuzzQuarantine.MaxChunks)
+
+		sanitizeChunks(fuzzSanitize, &request)
+		for _, chunk := range request.RawChunks {
+			for _, value := range chunk {
+				checkSanitized(t, value)
+			}
+		}
+		if again := sanitizeChunks(fuzzSanitize, &request); len(again) != 0 {
+			t.Fatalf("sanitization is not idempotent: %v", again)
+		}
+
+		if _, err := json.Marshal(request); err != nil {
+			t.Fatalf("sanitized request does not marshal: %v", err)
+		}
+	})
+}
+
+func checkSanitized(t *testing.T, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, value := range v {
+			checkSanitized(t, value)
+		}
+	case []any:
+		for _, value := range v {
+			checkSanitized(t, value)
+		}
+	case string:
+		if !utf8.ValidString(v) {
+			t.Fatalf("invalid UTF-8 survived sanitization: %q", v)
+		}
+		if strings.IndexFunc(v, isStrayControl) >= 0 {
+			t.Fatalf("control character survived sanitization: %q", v)
+		}
+		if int64(len(v)) > fuzzSanitize.MaxFieldBytes {
+			t.Fatalf("field of %d bytes survived truncation", len(v))
+		}
+	}
+}
+
+// FuzzBackendResponse drives backend response bodies through the parsing done
+// before a verdict is returned to the caller
+func FuzzBackendResponse(f *testing.F) {
+	addSeeds(f, "responses")
+	f.Add([]byte(`{"verified":"yes","input_tokens":{"a":1}}`))
+	f.Add([]byte(`[]`))
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		recordVerdict("fuzz", body)
+
+		if response, err := parseBackendResponse(body, ""); err == nil {
+			_ = config.ClassifyCause(nil, response.Cause)
+			if (response.InputTokens != nil && *response.InputTokens < 0) || (response.ResponseTokens != nil && *response.ResponseTokens < 0) {
+				t.Fatalf("negative token count from %s", body)
+			}
+		}
+
+		var object map[string]json.RawMessage
+		isObject := json.Unmarshal(body, &object) == nil && object != nil
+		for _, detail := range []string{DetailMinimal, DetailStandard, DetailFull} {
+			trimmed := trimResponse(body, detail)
+			if !isObject {
+				continue
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(trimmed, &fields); err != nil {
+				t.Fatalf("%s response is not a JSON object: %v", detail, err)
+			}
+			if detail == DetailMinimal {
+				for key := range fields {
+					if !minimalFields[key] {
+						t.Fatalf("minimal response kept %q", key)
+					}
+				}
+			}
+		}
+	})
+}
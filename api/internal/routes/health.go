@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Healthz handler for liveness, which only reports that the process is serving
+func Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+// Readyz handler for readiness, which checks the database and the verifier backend
+func Readyz(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), 3*time.Second)
+	defer cancel()
+
+	checks, ready := CheckDependencies(ctx, cc.Cfg)
+	for dependency, result := range checks {
+		if result != "ok" {
+			cc.Log.Warnw("Readiness check failed", "dependency", dependency, "error", result)
+		}
+	}
+
+	code := http.StatusOK
+	status := "ready"
+	if !cc.Cfg.Warm.Load() {
+		code = http.StatusServiceUnavailable
+		status = "warming up"
+	} else if !ready && checks["backend"] == "ok" && cc.Cfg.Database.Enabled {
+		// Verifications are still served from memory while the database is down
+		status = "degraded"
+	} else if !ready {
+		code = http.StatusServiceUnavailable
+		status = "not ready"
+	}
+
+	return c.JSON(code, map[string]any{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// CheckDependencies pings the database and the verifier backend, returning
+// "ok" or the failure for each and whether all of them passed
+func CheckDependencies(ctx context.Context, cfg *config.Config) (map[string]string, bool) {
+	checks := map[string]string{
+		"database": "ok",
+		"backend":  "ok",
+	}
+	ready := true
+
+	if err := cfg.SqlClient.PingContext(ctx); err != nil {
+		checks["database"] = err.Error()
+		ready = false
+	}
+
+	if cfg.Env.Mock.Enabled {
+		checks["backend"] = "mock"
+	} else if err := pingBackend(ctx, cfg, cfg.Env.HaproxyURL); err != nil {
+		checks["backend"] = err.Error()
+		ready = false
+	}
+
+	return checks, ready
+}
+
+// pingBackend reports whether the backend answers at all. Any response below
+// 500 counts, since the backend has no dedicated health route.
+func pingBackend(ctx context.Context, cfg *config.Config, backendURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, backendURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Draining the body lets the connection go back to the pool
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("backend returned %s", resp.Status)
+	}
+	return nil
+}
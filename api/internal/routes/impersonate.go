@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"database/sql"
+	"fmt"
+
+	"api/internal/config"
+	"api/internal/shared"
+)
+
+// impersonate lets an admin key act as another hotkey, subject to that hotkey's
+// own limits, recording the action in the impersonation audit
+func impersonate(cc *shared.Context, admin config.KeyInfo, target string) (bool, error) {
+	if !admin.IsAdmin {
+		cc.Log.Warnw("Non-admin key attempted impersonation", "hotkey", admin.Hotkey, "target", target)
+		return false, fmt.Errorf("X-On-Behalf-Of requires an admin API key")
+	}
+
+	key, err := cc.Cfg.Keys.LookupHotkey(target)
+	if err == sql.ErrNoRows {
+		cc.Log.Warnw("Impersonation target not found", "hotkey", admin.Hotkey, "target", target)
+		return false, fmt.Errorf("X-On-Behalf-Of hotkey not found")
+	} else if err != nil {
+		cc.Log.Errorw("Database error looking up impersonation target", "error", err.Error(), "target", target)
+		return false, fmt.Errorf("failed to look up X-On-Behalf-Of hotkey")
+	}
+
+	req := cc.Request()
+	_, err = cc.Cfg.SqlClient.Exec(
+		"INSERT INTO impersonation_audit (admin_hotkey, target_hotkey, method, path, proxy_request_id, source_ip) VALUES (?, ?, ?, ?, ?, ?)",
+		admin.Hotkey, target, req.Method, req.URL.Path, cc.Reqid, cc.RealIP(),
+	)
+	if err != nil {
+		// Impersonation is only allowed when it can be audited
+		cc.Log.Errorw("Failed to record impersonation", "error", err.Error(), "hotkey", admin.Hotkey, "target", target)
+		return false, fmt.Errorf("failed to audit impersonation")
+	}
+
+	// Reproduce the target's own key state as well as its limits
+	if err := checkKeyState(key); err != nil {
+		return false, err
+	}
+
+	cc.Log.Infow("Admin impersonating hotkey",
+		"admin_hotkey", admin.Hotkey,
+		"target_hotkey", target,
+		"path", req.URL.Path,
+	)
+
+	cc.Actor = admin.Hotkey
+	cc.Hotkey = key.Hotkey
+	cc.Tier = key.Tier
+	cc.Key = key
+
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+
+	"api/internal/apikey"
+	"api/internal/hotkey"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ActivateKey handler for activating a key by signing its challenge with the hotkey
+func ActivateKey(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	var req shared.ActivateKeyRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" || req.Signature == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey and signature are required"))
+	}
+
+	var active, autoProvisioned bool
+	var challenge sql.NullString
+	err := cc.Cfg.SqlClient.QueryRow(
+		"SELECT active, challenge, auto_provisioned FROM api_keys WHERE hotkey = ?",
+		req.Hotkey,
+	).Scan(&active, &challenge, &autoProvisioned)
+
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Database error retrieving challenge", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve challenge"))
+	}
+
+	if active {
+		return c.JSON(http.StatusOK, map[string]string{
+			"message": "API key already active",
+		})
+	}
+
+	if !challenge.Valid {
+		return c.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "No pending challenge for this hotkey"))
+	}
+
+	if err := hotkey.Verify(req.Hotkey, []byte(challenge.String), req.Signature); err != nil {
+		cc.Log.Warnw("Challenge signature rejected", "hotkey", req.Hotkey, "error", err.Error())
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, "Signature does not match hotkey: "+err.Error()))
+	}
+
+	// Keys provisioned from the metagraph were never revealed to anyone, so the
+	// signer is issued a fresh key; admin-created keys were handed out already
+	var keyValue string
+	query := "UPDATE api_keys SET active = TRUE, challenge = NULL WHERE hotkey = ?"
+	args := []any{req.Hotkey}
+	if autoProvisioned {
+		keyValue, err = apikey.Generate()
+		if err != nil {
+			cc.Log.Errorw("Failed to generate API key", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to generate API key"))
+		}
+		query = "UPDATE api_keys SET active = TRUE, challenge = NULL, key_hash = ?, key_hint = ? WHERE hotkey = ?"
+		args = []any{apikey.Hash(keyValue), apikey.Hint(keyValue), req.Hotkey}
+	}
+
+	_, err = cc.Cfg.SqlClient.Exec(query, args...)
+	if err != nil {
+		cc.Log.Errorw("Failed to activate API key", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to activate API key"))
+	}
+
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("API key activated", "hotkey", req.Hotkey)
+
+	response := map[string]string{
+		"message": "API key activated",
+		"hotkey":  req.Hotkey,
+	}
+	if keyValue != "" {
+		response["key_value"] = keyValue
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetChallenge handler for fetching the pending activation challenge of a hotkey
+func GetChallenge(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	hk := c.Param("hotkey")
+
+	var challenge sql.NullString
+	err := cc.Cfg.SqlClient.QueryRow(
+		"SELECT challenge FROM api_keys WHERE hotkey = ? AND active = FALSE",
+		hk,
+	).Scan(&challenge)
+
+	if err == sql.ErrNoRows || (err == nil && !challenge.Valid) {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "No pending challenge for this hotkey"))
+	} else if err != nil {
+		cc.Log.Errorw("Database error retrieving challenge", "error", err.Error(), "hotkey", hk)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve challenge"))
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"hotkey":    hk,
+		"challenge": challenge.String,
+	})
+}
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"net/http"
+
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Legacy marks a route as a deprecated alias of successor. Responses carry
+// Deprecation and Link headers, and calls are counted per key once the handler
+// has authenticated the caller.
+func Legacy(successor string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cc := c.(*shared.Context)
+
+			h := c.Response().Header()
+			h.Set("Deprecation", "true")
+			h.Set("Link", "<"+successor+">; rel=\"successor-version\"")
+			if sunset := cc.Cfg.Env.LegacySunset; sunset != "" {
+				h.Set("Sunset", sunset)
+			}
+
+			err := next(c)
+
+			metrics.LegacyRequests.WithLabelValues(c.Path()).Inc()
+			if cc.Hotkey != "" {
+				if err := cc.Cfg.Legacy.Record(c.Path(), successor, cc.Hotkey); err != nil {
+					cc.Log.Warnw("Failed to record legacy usage", "error", err.Error(), "route", c.Path())
+				}
+			}
+			return err
+		}
+	}
+}
+
+// ListLegacyUsage handler for listing which keys still call deprecated endpoints
+func ListLegacyUsage(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	usage, err := cc.Cfg.Legacy.All()
+	if err != nil {
+		cc.Log.Errorw("Failed to query legacy usage", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve legacy usage"))
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"usage": usage,
+	})
+}
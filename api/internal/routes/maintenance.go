@@ -0,0 +1,159 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api/internal/config"
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// checkMaintenance rejects requests for a model in a scheduled maintenance
+// window. Async requests during a queue-mode window are admitted; their jobs
+// are deferred until the window ends.
+func checkMaintenance(cc *shared.Context, model string, async bool) (*shared.VerifyErrorResponse, int) {
+	window, ok := cc.Cfg.Routes.Maintenance(model, time.Now())
+	if !ok || (async && window.Mode == config.MaintenanceQueue) {
+		return nil, 0
+	}
+
+	cc.Response().Header().Set("Retry-After", strconv.Itoa(int(time.Until(window.End).Seconds())+1))
+	metrics.VerifyErrors.WithLabelValues(model, "maintenance").Inc()
+
+	message := window.Message
+	if message == "" {
+		message = fmt.Sprintf("Model %s is under scheduled maintenance until %s", model, window.End.UTC().Format(time.RFC3339))
+	}
+	errResp := verifyError(cc, shared.CodeModelMaintenance, message)
+	errResp.MaintenanceError = &shared.MaintenanceError{MaintenanceUntil: window.End}
+	return errResp, http.StatusServiceUnavailable
+}
+
+// deferJob queues a job once a maintenance window has ended. The job stays
+// pending meanwhile, so a restart before then re-drives it as well.
+func deferJob(cfg *config.Config, jobID string, until time.Time) {
+	time.AfterFunc(time.Until(until), func() {
+		cfg.JobQueue <- jobID
+	})
+}
+
+// AddMaintenance handler for scheduling a maintenance window for a model
+func AddMaintenance(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.AddMaintenanceRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Model == "" || req.End.IsZero() {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "model and end are required"))
+	}
+
+	now := time.Now()
+	window := config.MaintenanceWindow{
+		Start:   now,
+		End:     req.End,
+		Mode:    req.Mode,
+		Message: req.Message,
+	}
+	if req.Start != nil {
+		window.Start = *req.Start
+	}
+	if window.Mode == "" {
+		window.Mode = config.MaintenanceReject
+	}
+	if err := window.Validate(now); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	if err := cc.Cfg.Routes.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Errorw("Failed to reload model routes", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to schedule maintenance"))
+	}
+	route, ok := cc.Cfg.Routes.Lookup(req.Model)
+	if !ok {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Route not found"))
+	}
+
+	// Windows that are over are dropped as new ones are scheduled
+	windows := []config.MaintenanceWindow{window}
+	for _, w := range route.Maintenance {
+		if w.End.After(now) {
+			windows = append(windows, w)
+		}
+	}
+	body, _ := json.Marshal(windows)
+
+	_, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE model_routes SET maintenance = ?, updated_at = ? WHERE model = ?",
+		body, now, req.Model,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to store maintenance window", "error", err.Error(), "model", req.Model)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to schedule maintenance"))
+	}
+
+	if err := cc.Cfg.Routes.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Warnw("Failed to reload model routes", "error", err.Error())
+	}
+
+	cc.Log.Infow("Maintenance scheduled",
+		"model", req.Model,
+		"start", window.Start,
+		"end", window.End,
+		"mode", window.Mode,
+	)
+
+	route, _ = cc.Cfg.Routes.Lookup(req.Model)
+	return c.JSON(http.StatusOK, route)
+}
+
+// ClearMaintenance handler for cancelling every maintenance window of a model
+func ClearMaintenance(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	model := c.Param("model")
+
+	result, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE model_routes SET maintenance = NULL, updated_at = ? WHERE model = ?",
+		time.Now(), model,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to clear maintenance windows", "error", err.Error(), "model", model)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to clear maintenance"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Route not found"))
+	}
+
+	if err := cc.Cfg.Routes.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Warnw("Failed to reload model routes", "error", err.Error())
+	}
+
+	cc.Log.Infow("Maintenance cleared", "model", model)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Maintenance cleared",
+	})
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"net/http"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MetagraphStatus handler for reporting the metagraph sync settings and the
+// outcome of the latest sync
+func MetagraphStatus(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	store := cc.Cfg.Metagraph
+	return c.JSON(http.StatusOK, shared.MetagraphStatusResponse{
+		Status:        store.Status(),
+		Netuid:        store.Netuid,
+		MinStake:      store.MinStake,
+		RequirePermit: store.Permit,
+		Interval:      cc.Cfg.Env.MetagraphInterval.String(),
+	})
+}
+
+// SyncMetagraph handler for running a metagraph sync ahead of its schedule
+func SyncMetagraph(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	if !cc.Cfg.Metagraph.RequestSync() {
+		return c.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "Metagraph sync is not configured"))
+	}
+
+	cc.Log.Infow("Metagraph sync requested")
+	auditAdmin(cc, auditMetagraphSync, "", nil)
+
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "Metagraph sync requested",
+	})
+}
@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"api/internal/apikey"
+	"api/internal/config"
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+// mirrorEvent is the copy of a verification sent to the analytics endpoint
+type mirrorEvent struct {
+	RequestID   string          `json:"request_id"`
+	Hotkey      string          `json:"hotkey"`
+	Model       string          `json:"model"`
+	RequestType string          `json:"request_type"`
+	Tags        []string        `json:"tags,omitempty"`
+	Source      string          `json:"source"`
+	LatencyMs   int64           `json:"latency_ms"`
+	At          time.Time       `json:"at"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	Response    json.RawMessage `json:"response"`
+}
+
+// mirrorToAnalytics queues a redacted copy of a finished verification for the
+// analytics endpoint, dropping it when the queue is full
+func mirrorToAnalytics(cc *shared.Context, req *shared.VerificationRequest, response []byte, source string, startTime time.Time) {
+	settings := cc.Cfg.Env.AnalyticsMirror
+	if !settings.Enabled() {
+		return
+	}
+
+	event := mirrorEvent{
+		RequestID:   req.RequestID,
+		Hotkey:      cc.Hotkey,
+		Model:       req.Model,
+		RequestType: req.RequestType,
+		Tags:        req.Tags,
+		Source:      source,
+		LatencyMs:   time.Since(startTime).Milliseconds(),
+		At:          time.Now(),
+		Response:    redactJSON(settings.Redact, response),
+	}
+	if settings.HashHotkeys && event.Hotkey != "" {
+		event.Hotkey = apikey.Hash(event.Hotkey)
+	}
+	if settings.IncludePayload {
+		if payload, err := json.Marshal(req); err == nil {
+			event.Request = redactJSON(settings.Redact, payload)
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	select {
+	case cc.Cfg.Mirror <- body:
+	default:
+		metrics.AnalyticsMirror.WithLabelValues("dropped").Inc()
+	}
+}
+
+// redactJSON masks the given fields anywhere in a JSON document
+func redactJSON(fields []string, body []byte) json.RawMessage {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactValue(fields, v))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// StartAnalyticsMirror starts the workers that post queued verification copies
+// to the analytics endpoint. Failed posts are counted and dropped.
+func StartAnalyticsMirror(cfg *config.Config, log *zap.SugaredLogger) {
+	settings := cfg.Env.AnalyticsMirror
+	if !settings.Enabled() {
+		return
+	}
+
+	client := &http.Client{Timeout: settings.Timeout, Transport: cfg.Env.Outbound.Transport()}
+	mirrorLog := log.With("phase", "analytics_mirror")
+	for i := 0; i < settings.Workers; i++ {
+		go func() {
+			for body := range cfg.Mirror {
+				if err := postMirrorEvent(client, settings.URL, body); err != nil {
+					metrics.AnalyticsMirror.WithLabelValues("error").Inc()
+					mirrorLog.Warnw("Failed to mirror verification", "error", err.Error())
+					continue
+				}
+				metrics.AnalyticsMirror.WithLabelValues("sent").Inc()
+			}
+		}()
+	}
+}
+
+// postMirrorEvent posts a single event, treating any non-2xx status as a failure
+func postMirrorEvent(client *http.Client, url string, body []byte) error {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("analytics endpoint returned %s", resp.Status)
+	}
+	return nil
+}
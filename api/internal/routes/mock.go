@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"api/internal/shared"
+)
+
+// mockVerify answers a verification from the scripted MOCK_BACKEND_RESPONSES
+// instead of the verifier backend
+func mockVerify(cc *shared.Context, req *shared.VerificationRequest) ([]byte, error) {
+	scripted := cc.Cfg.Env.Mock.Response(req.Model)
+
+	if scripted.Latency > 0 {
+		select {
+		case <-time.After(time.Duration(scripted.Latency)):
+		case <-cc.Ctx().Done():
+			return nil, cc.Ctx().Err()
+		}
+	}
+
+	if scripted.Status >= 500 {
+		return nil, backendFailure{fmt.Errorf("backend returned status %d", scripted.Status)}
+	}
+
+	inputTokens, responseTokens := int64(0), int64(len(req.RawChunks))
+	response := shared.VerificationResponse{
+		RequestID:      req.RequestID,
+		Verified:       scripted.Verified,
+		Cause:          scripted.Cause,
+		Error:          scripted.Error,
+		Score:          scripted.Score,
+		InputTokens:    &inputTokens,
+		ResponseTokens: &responseTokens,
+		GPUs:           1,
+	}
+
+	if cc.Cfg.Env.Debug {
+		cc.Log.Debugw("Mock backend answered verification",
+			"request_id", req.RequestID,
+			"model", req.Model,
+			"verified", scripted.Verified,
+		)
+	}
+
+	return json.Marshal(response)
+}
@@ -0,0 +1,138 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListRoutes handler for listing registered model routes
+func ListRoutes(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	if err := cc.Cfg.Routes.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Errorw("Failed to reload model routes", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve routes"))
+	}
+
+	return c.JSON(http.StatusOK, cc.Cfg.Routes.All())
+}
+
+// SetRoute handler for registering or updating a model route
+func SetRoute(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetRouteRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Model == "" || req.BackendURL == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "model and backend_url are required"))
+	}
+
+	// backend_url may list several backends to balance between
+	var backends []string
+	for _, backend := range strings.Split(req.BackendURL, ",") {
+		backend = strings.TrimSpace(backend)
+		if u, err := url.Parse(backend); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "backend_url must be a comma-separated list of absolute http(s) URLs"))
+		}
+		backends = append(backends, strings.TrimSuffix(backend, "/"))
+	}
+	req.BackendURL = strings.Join(backends, ",")
+
+	if req.Path == "" {
+		req.Path = "/verify"
+	} else if !strings.HasPrefix(req.Path, "/") {
+		req.Path = "/" + req.Path
+	}
+	if req.BackendServer == "" {
+		req.BackendServer = req.Model
+	}
+
+	var causeRules []byte
+	if len(req.CauseRules) > 0 {
+		if _, err := config.CompileCauseRules(req.CauseRules); err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+		}
+		causeRules, _ = json.Marshal(req.CauseRules)
+	}
+
+	_, err := cc.Cfg.SqlClient.Exec(
+		"INSERT INTO model_routes (model, backend_url, path, backend_server, cause_rules, updated_at) VALUES (?, ?, ?, ?, ?, ?) "+
+			cc.Cfg.SqlClient.Dialect.Upsert("model_routes", []string{"model"}, []string{"backend_url", "path", "backend_server", "cause_rules", "updated_at"}, nil),
+		req.Model, req.BackendURL, req.Path, req.BackendServer, causeRules, time.Now(),
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to store model route", "error", err.Error(), "model", req.Model)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to store route"))
+	}
+
+	if err := cc.Cfg.Routes.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Warnw("Failed to reload model routes", "error", err.Error())
+	}
+
+	cc.Log.Infow("Model route set",
+		"model", req.Model,
+		"backend_url", req.BackendURL,
+		"path", req.Path,
+		"backend_server", req.BackendServer,
+	)
+
+	route, _ := cc.Cfg.Routes.Lookup(req.Model)
+	return c.JSON(http.StatusOK, route)
+}
+
+// DeleteRoute handler for removing a model route
+func DeleteRoute(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	model := c.Param("model")
+
+	result, err := cc.Cfg.SqlClient.Exec("DELETE FROM model_routes WHERE model = ?", model)
+	if err != nil {
+		cc.Log.Errorw("Failed to delete model route", "error", err.Error(), "model", model)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to delete route"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Route not found"))
+	}
+
+	if err := cc.Cfg.Routes.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Warnw("Failed to reload model routes", "error", err.Error())
+	}
+
+	cc.Log.Infow("Model route removed", "model", model)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Route removed successfully",
+	})
+}
@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// modelsCacheKey holds the last capability probe, so listing models does not
+// query every backend on each call
+const (
+	modelsCacheKey = "models:probe"
+	modelsCacheTTL = 30 * time.Second
+)
+
+// ListModels handler for listing the models the proxy can verify with their status
+func ListModels(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	if valid, err := validateAPIKey(cc); !valid {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, err.Error()))
+	}
+
+	if !cc.Cfg.Routes.Empty() {
+		return c.JSON(http.StatusOK, shared.ModelsResponse{Source: "routes", Models: routedModels(cc)})
+	}
+
+	if cached, found := cc.Cfg.Cache.Get(modelsCacheKey); found {
+		var models []shared.ModelInfo
+		if err := json.Unmarshal(cached, &models); err == nil {
+			return c.JSON(http.StatusOK, shared.ModelsResponse{Source: "backends", Models: models})
+		}
+	}
+
+	models := probeModels(cc)
+	if body, err := json.Marshal(models); err == nil {
+		cc.Cfg.Cache.Set(modelsCacheKey, body, modelsCacheTTL)
+	}
+	return c.JSON(http.StatusOK, shared.ModelsResponse{Source: "backends", Models: models})
+}
+
+// routedModels reports every model of the routing table. A model is degraded
+// while some of its backends are ejected or have an open circuit, and
+// unavailable while all of them are.
+func routedModels(cc *shared.Context) []shared.ModelInfo {
+	now := time.Now()
+	routes := cc.Cfg.Routes.All()
+	models := make([]shared.ModelInfo, 0, len(routes))
+	for _, route := range routes {
+		backends := route.Backends()
+		info := shared.ModelInfo{Model: route.Model, Backends: len(backends)}
+		for _, backend := range backends {
+			if cc.Cfg.Balancer.Healthy(backend) && !cc.Cfg.Breaker.Open(backend+route.Path+"|"+route.Model) {
+				info.HealthyBackends++
+			}
+		}
+		info.Status = modelStatus(info)
+
+		if window, ok := route.ActiveMaintenance(now); ok {
+			info.Status = shared.ModelMaintenance
+			info.MaintenanceUntil = &window.End
+		}
+		models = append(models, info)
+	}
+	return models
+}
+
+// probeModels asks each backend for the models it serves. Without routes every
+// backend is expected to serve every model, so a model missing from a backend
+// that answered, or any backend that did not answer, degrades it.
+func probeModels(cc *shared.Context) []shared.ModelInfo {
+	if cc.Cfg.Env.Mock.Enabled {
+		var models []shared.ModelInfo
+		for model := range cc.Cfg.Env.Mock.Responses {
+			if model != "*" {
+				models = append(models, shared.ModelInfo{Model: model, Status: shared.ModelHealthy, Backends: 1, HealthyBackends: 1})
+			}
+		}
+		sort.Slice(models, func(i, j int) bool { return models[i].Model < models[j].Model })
+		return models
+	}
+
+	backends := []string{cc.Cfg.Env.HaproxyURL}
+	backends = append(backends, cc.Cfg.Env.ConsensusBackends...)
+
+	served := make([][]string, len(backends))
+	var wg sync.WaitGroup
+	for i, backend := range backends {
+		wg.Add(1)
+		go func(i int, backend string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(cc.Ctx(), 5*time.Second)
+			defer cancel()
+			models, err := fetchBackendModels(ctx, cc, backend)
+			if err != nil {
+				cc.Log.Warnw("Failed to query backend models", "url", backend, "error", err.Error())
+				return
+			}
+			served[i] = models
+		}(i, backend)
+	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	for _, models := range served {
+		for _, model := range models {
+			counts[model]++
+		}
+	}
+
+	models := make([]shared.ModelInfo, 0, len(counts))
+	for model, count := range counts {
+		info := shared.ModelInfo{Model: model, Backends: len(backends), HealthyBackends: count}
+		info.Status = modelStatus(info)
+		models = append(models, info)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Model < models[j].Model })
+	return models
+}
+
+// modelStatus derives a model's status from how many of its backends are healthy
+func modelStatus(info shared.ModelInfo) string {
+	switch {
+	case info.HealthyBackends == 0 && info.Backends > 0:
+		return shared.ModelUnavailable
+	case info.HealthyBackends < info.Backends:
+		return shared.ModelDegraded
+	default:
+		return shared.ModelHealthy
+	}
+}
+
+// fetchBackendModels queries a backend's capability list, which Valis serves
+// at GET /models as {"models": ["..."]}
+func fetchBackendModels(ctx context.Context, cc *shared.Context, backendURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, backendURL+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	SetRequestIDHeaders(cc, req.Header)
+
+	resp, err := cc.Cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("backend returned %s", resp.Status)
+	}
+
+	var capabilities struct {
+		Models []string `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&capabilities); err != nil {
+		return nil, fmt.Errorf("invalid capability list: %w", err)
+	}
+	return capabilities.Models, nil
+}
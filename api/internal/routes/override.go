@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OverrideVerdict handler for replacing the verdict of a past verification,
+// e.g. to settle a dispute over a known false negative. The override is
+// audited first and then applied to the result cache, async job results, tag
+// stats and the verification log.
+func OverrideVerdict(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.OverrideVerdictRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.RequestID == "" || req.Hotkey == "" || req.Verified == nil || req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "request_id, hotkey, verified and reason are required"))
+	}
+
+	// The latest logged outcome is the verdict being replaced
+	var response shared.VerificationResponse
+	var model string
+	var cause, causeCode, errMsg, tags sql.NullString
+	var inputTokens, responseTokens sql.NullInt64
+	err := cc.Cfg.SqlClient.QueryRow(
+		`SELECT model, verified, cause, cause_code, error, input_tokens, response_tokens, gpus, tags FROM verification_logs
+		WHERE request_id = ? AND hotkey = ? ORDER BY id DESC LIMIT 1`,
+		req.RequestID, req.Hotkey,
+	).Scan(&model, &response.Verified, &cause, &causeCode, &errMsg, &inputTokens, &responseTokens, &response.GPUs, &tags)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Verification not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Database error retrieving verification", "error", err.Error(), "request_id", req.RequestID)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve verification"))
+	}
+	previous := response.Verified
+
+	// Prefer the cached response so fields that are not logged survive
+	if cached, found := cc.Cfg.Cache.Get(resultCacheKey(req.RequestID)); found {
+		var cachedResponse shared.VerificationResponse
+		if err := json.Unmarshal(cached, &cachedResponse); err == nil {
+			response = cachedResponse
+		}
+	} else {
+		response.RequestID = req.RequestID
+		response.Cause = cause.String
+		response.CauseCode = causeCode.String
+		response.Error = errMsg.String
+		if inputTokens.Valid {
+			response.InputTokens = &inputTokens.Int64
+		}
+		if responseTokens.Valid {
+			response.ResponseTokens = &responseTokens.Int64
+		}
+	}
+
+	// Overrides are only allowed when they can be audited
+	_, err = cc.Cfg.SqlClient.Exec(
+		"INSERT INTO verdict_overrides (request_id, hotkey, model, previous_verified, verified, reason, admin_hotkey, proxy_request_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		req.RequestID, req.Hotkey, model, previous, *req.Verified, req.Reason, cc.Hotkey, cc.Reqid,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to record verdict override", "error", err.Error(), "request_id", req.RequestID)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to audit override"))
+	}
+
+	response.Verified = *req.Verified
+	if response.Verified {
+		response.Cause = ""
+		response.CauseCode = ""
+		response.Error = ""
+	} else {
+		response.Cause = req.Reason
+		response.CauseCode = config.CauseOverridden
+	}
+	response.Override = &shared.Override{Reason: req.Reason, At: time.Now()}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to apply override"))
+	}
+
+	if ttl := cc.Cfg.Env.ResultCache.TTL; ttl > 0 {
+		cc.Cfg.Cache.Set(resultCacheKey(req.RequestID), body, ttl)
+	}
+
+	_, err = cc.Cfg.SqlClient.Exec(
+		"INSERT INTO verification_logs (request_id, hotkey, model, verified, cause, cause_code, error, input_tokens, response_tokens, gpus, latency_ms, source, tags) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?)",
+		req.RequestID, req.Hotkey, model, response.Verified, response.Cause, response.CauseCode, response.Error,
+		inputTokens, responseTokens, response.GPUs, SourceOverride, tags,
+	)
+	if err != nil {
+		cc.Log.Warnw("Failed to record verification log", "error", err.Error(), "request_id", req.RequestID)
+	}
+
+	if _, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE verification_tags SET verified = ? WHERE request_id = ? AND hotkey = ?",
+		response.Verified, req.RequestID, req.Hotkey,
+	); err != nil {
+		cc.Log.Warnw("Failed to update verification tags", "error", err.Error(), "request_id", req.RequestID)
+	}
+
+	if _, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE verification_jobs SET result = ? WHERE request_id = ? AND hotkey = ? AND result IS NOT NULL",
+		body, req.RequestID, req.Hotkey,
+	); err != nil {
+		cc.Log.Warnw("Failed to update job results", "error", err.Error(), "request_id", req.RequestID)
+	}
+
+	cc.Log.Infow("Verdict overridden",
+		"request_id", req.RequestID,
+		"hotkey", req.Hotkey,
+		"model", model,
+		"previous_verified", previous,
+		"verified", response.Verified,
+		"reason", req.Reason,
+		"admin_hotkey", cc.Hotkey,
+	)
+
+	return c.JSON(http.StatusOK, response)
+}
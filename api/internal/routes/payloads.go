@@ -0,0 +1,151 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ListPayloads handler for listing the synthetic payload library
+func ListPayloads(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	if err := cc.Cfg.Payloads.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Errorw("Failed to reload synthetic payloads", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve payloads"))
+	}
+
+	if model := c.QueryParam("model"); model != "" {
+		return c.JSON(http.StatusOK, cc.Cfg.Payloads.ForModel(model))
+	}
+	return c.JSON(http.StatusOK, cc.Cfg.Payloads.All())
+}
+
+// AddPayload handler for adding a reference case to the synthetic payload
+// library. Cases taken from real traffic are stripped of anything that ties
+// them back to the caller before they are stored.
+func AddPayload(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.AddPayloadRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Name == "" || (len(req.Request) == 0) == (req.JobID == "") {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "name and exactly one of request or job_id are required"))
+	}
+
+	source := "manual"
+	raw := []byte(req.Request)
+	if req.JobID != "" {
+		err := cc.Cfg.SqlClient.QueryRow(
+			"SELECT request FROM verification_jobs WHERE id = ?",
+			req.JobID,
+		).Scan(&raw)
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Job not found"))
+		} else if err != nil {
+			cc.Log.Errorw("Database error retrieving job", "error", err.Error(), "job_id", req.JobID)
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve job"))
+		}
+		if len(raw) == 0 {
+			return c.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "Job payload was not retained"))
+		}
+		source = req.JobID
+	}
+
+	var request shared.VerificationRequest
+	if err := json.Unmarshal(raw, &request); err != nil || request.Model == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "request must be a verification request with a model"))
+	}
+	request.RequestID = ""
+	request.Tags = nil
+	stored, _ := json.Marshal(request)
+
+	expectVerified := true
+	if req.ExpectVerified != nil {
+		expectVerified = *req.ExpectVerified
+	}
+
+	id, err := cc.Cfg.SqlClient.InsertID(
+		"INSERT INTO synthetic_payloads (model, name, request, expect_verified, source) VALUES (?, ?, ?, ?, ?) "+
+			cc.Cfg.SqlClient.Dialect.Upsert("synthetic_payloads", []string{"model", "name"}, []string{"request", "expect_verified", "source"}, nil),
+		request.Model, req.Name, stored, expectVerified, source,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to store synthetic payload", "error", err.Error(), "model", request.Model)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to store payload"))
+	}
+
+	if err := cc.Cfg.Payloads.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Warnw("Failed to reload synthetic payloads", "error", err.Error())
+	}
+
+	cc.Log.Infow("Synthetic payload added",
+		"id", id,
+		"model", request.Model,
+		"name", req.Name,
+		"source", source,
+	)
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"message": "Payload stored successfully",
+		"model":   request.Model,
+		"name":    req.Name,
+	})
+}
+
+// DeletePayload handler for removing a case from the synthetic payload library
+func DeletePayload(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid payload id"))
+	}
+
+	result, err := cc.Cfg.SqlClient.Exec("DELETE FROM synthetic_payloads WHERE id = ?", id)
+	if err != nil {
+		cc.Log.Errorw("Failed to delete synthetic payload", "error", err.Error(), "id", id)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to delete payload"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Payload not found"))
+	}
+
+	if err := cc.Cfg.Payloads.Reload(cc.Cfg.SqlClient); err != nil {
+		cc.Log.Warnw("Failed to reload synthetic payloads", "error", err.Error())
+	}
+
+	cc.Log.Infow("Synthetic payload removed", "id", id)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Payload removed successfully",
+	})
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+)
+
+// applyPolicy adjusts the backend verdict using the model's verification policy, if any
+func applyPolicy(cc *shared.Context, req *shared.VerificationRequest, body []byte) []byte {
+	policy, ok := cc.Cfg.Runtime().Policies[req.Model]
+	if !ok {
+		return body
+	}
+
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		cc.Log.Warnw("Failed to parse backend response for policy", "error", err.Error(), "request_id", req.RequestID)
+		return body
+	}
+
+	if response.Score == nil {
+		return body
+	}
+
+	decision := policy.Evaluate(*response.Score, response.Verified, time.Now())
+
+	cc.Log.Infow("Policy decision",
+		"request_id", req.RequestID,
+		"model", req.Model,
+		"score", *response.Score,
+		"threshold", decision.Threshold,
+		"in_grace", decision.InGrace,
+		"backend_verified", response.Verified,
+		"verified", decision.Verified,
+	)
+
+	cc.Cfg.Decisions.Record(config.PolicyRecord{
+		RequestID:       req.RequestID,
+		Model:           req.Model,
+		Score:           *response.Score,
+		Decision:        decision,
+		BackendVerified: response.Verified,
+		At:              time.Now(),
+	})
+
+	if !decision.Overrode {
+		return body
+	}
+
+	response.Verified = decision.Verified
+	response.PolicyApplied = true
+	if decision.Verified {
+		response.Error = ""
+		response.Cause = fmt.Sprintf("accepted by policy: score %.4f >= %.4f", *response.Score, decision.Threshold)
+	} else {
+		response.Cause = fmt.Sprintf("rejected by policy: score %.4f < %.4f", *response.Score, decision.Threshold)
+	}
+
+	adjusted, err := json.Marshal(response)
+	if err != nil {
+		cc.Log.Errorw("Failed to marshal policy-adjusted response", "error", err.Error(), "request_id", req.RequestID)
+		return body
+	}
+
+	return adjusted
+}
+
+// classifyCause adds the stable cause code for the response's raw cause
+func classifyCause(cc *shared.Context, req *shared.VerificationRequest, body []byte) []byte {
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil || response.Cause == "" {
+		return body
+	}
+
+	route, _ := cc.Cfg.Routes.Lookup(req.Model)
+	response.CauseCode = config.ClassifyCause(route.CauseRules, response.Cause)
+
+	classified, err := json.Marshal(response)
+	if err != nil {
+		cc.Log.Errorw("Failed to marshal classified response", "error", err.Error(), "request_id", req.RequestID)
+		return body
+	}
+
+	return classified
+}
@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"api/internal/config"
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// pruneEvent is posted to KEY_PRUNE_WEBHOOK when keys are flagged or disabled
+type pruneEvent struct {
+	Event     string     `json:"event"`
+	Hotkeys   []string   `json:"hotkeys"`
+	DisableAt *time.Time `json:"disable_at,omitempty"`
+}
+
+// StartKeyPruning runs the unused key policy on every interval
+func StartKeyPruning(cfg *config.Config, log *zap.SugaredLogger) {
+	settings := cfg.Env.KeyPrune
+	if !settings.Enabled() {
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second, Transport: cfg.Env.Outbound.Transport()}
+	pruneLog := log.With("phase", "key_prune")
+	go func() {
+		ticker := time.NewTicker(settings.Interval)
+		defer ticker.Stop()
+		for {
+			if err := pruneUnusedKeys(cfg, client, pruneLog); err != nil {
+				pruneLog.Errorw("Unused key pruning failed", "error", err.Error())
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// pruneUnusedKeys clears the flag of keys used again, flags keys unused for
+// longer than the policy allows and, when enabled, disables flagged keys whose
+// notice period passed without use. Admin, exempt and metagraph-provisioned
+// keys are never flagged. With a webhook configured, keys are only flagged
+// once it has been notified, so no key is disabled without notice.
+func pruneUnusedKeys(cfg *config.Config, client *http.Client, log *zap.SugaredLogger) error {
+	settings := cfg.Env.KeyPrune
+	now := time.Now()
+
+	result, err := cfg.SqlClient.Exec(
+		"UPDATE api_keys SET prune_flagged_at = NULL WHERE prune_flagged_at IS NOT NULL AND disabled = FALSE AND (prune_exempt = TRUE OR last_used_at >= prune_flagged_at)",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear flags of used keys: %w", err)
+	}
+	if cleared, err := result.RowsAffected(); err == nil && cleared > 0 {
+		metrics.KeysPruned.WithLabelValues("cleared").Add(float64(cleared))
+		log.Infow("Cleared unused flag of keys in use again", "count", cleared)
+	}
+
+	cutoff := now.Add(-settings.After)
+	unused, err := queryHotkeys(cfg,
+		"SELECT hotkey FROM api_keys WHERE prune_flagged_at IS NULL AND prune_exempt = FALSE AND disabled = FALSE AND auto_provisioned = FALSE AND is_admin = FALSE"+
+			" AND created_at < ? AND (last_used_at IS NULL OR last_used_at < ?)",
+		cutoff, cutoff,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to find unused keys: %w", err)
+	}
+	if len(unused) > 0 {
+		event := pruneEvent{Event: "keys_flagged_unused", Hotkeys: unused}
+		if settings.Disable {
+			disableAt := now.Add(settings.Notice)
+			event.DisableAt = &disableAt
+		}
+		if err := notifyPrune(client, settings.WebhookURL, event); err != nil {
+			return fmt.Errorf("failed to notify flagged keys, not flagging them: %w", err)
+		}
+		if err := updateHotkeys(cfg, "UPDATE api_keys SET prune_flagged_at = ? WHERE prune_flagged_at IS NULL AND hotkey IN ", []any{now}, unused); err != nil {
+			return fmt.Errorf("failed to flag unused keys: %w", err)
+		}
+		metrics.KeysPruned.WithLabelValues("flagged").Add(float64(len(unused)))
+		log.Infow("Flagged unused keys", "count", len(unused), "unused_for", settings.After.String())
+	}
+
+	if !settings.Disable {
+		return nil
+	}
+
+	expired, err := queryHotkeys(cfg,
+		"SELECT hotkey FROM api_keys WHERE prune_flagged_at IS NOT NULL AND prune_flagged_at < ? AND prune_exempt = FALSE AND disabled = FALSE AND is_admin = FALSE"+
+			" AND (last_used_at IS NULL OR last_used_at < prune_flagged_at)",
+		now.Add(-settings.Notice),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to find keys past their notice: %w", err)
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	if err := updateHotkeys(cfg, "UPDATE api_keys SET disabled = TRUE WHERE hotkey IN ", nil, expired); err != nil {
+		return fmt.Errorf("failed to disable unused keys: %w", err)
+	}
+	for _, hk := range expired {
+		cfg.Keys.InvalidateHotkey(hk)
+	}
+	metrics.KeysPruned.WithLabelValues("disabled").Add(float64(len(expired)))
+	log.Infow("Disabled unused keys", "count", len(expired), "hotkeys", expired)
+
+	if err := notifyPrune(client, settings.WebhookURL, pruneEvent{Event: "keys_disabled_unused", Hotkeys: expired}); err != nil {
+		log.Warnw("Failed to notify disabled keys", "error", err.Error())
+	}
+	return nil
+}
+
+// queryHotkeys returns the hotkeys selected by query
+func queryHotkeys(cfg *config.Config, query string, args ...any) ([]string, error) {
+	rows, err := cfg.SqlClient.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hotkeys []string
+	for rows.Next() {
+		var hk string
+		if err := rows.Scan(&hk); err != nil {
+			return nil, err
+		}
+		hotkeys = append(hotkeys, hk)
+	}
+	return hotkeys, rows.Err()
+}
+
+// updateHotkeys runs an update ending in "hotkey IN " over the hotkeys in batches
+func updateHotkeys(cfg *config.Config, query string, args []any, hotkeys []string) error {
+	for start := 0; start < len(hotkeys); start += bulkBatchSize {
+		batch := hotkeys[start:min(start+bulkBatchSize, len(hotkeys))]
+		batchArgs := append([]any{}, args...)
+		for _, hk := range batch {
+			batchArgs = append(batchArgs, hk)
+		}
+		if _, err := cfg.SqlClient.Exec(query+"("+placeholders(len(batch))+")", batchArgs...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyPrune posts an event to the prune webhook, if one is configured
+func notifyPrune(client *http.Client, url string, event pruneEvent) error {
+	if url == "" {
+		return nil
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("prune webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SetPruneExempt handler for exempting a key from, or returning it to, the unused key policy
+func SetPruneExempt(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetPruneExemptRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	// Exempting a key also withdraws a pending flag
+	result, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE api_keys SET prune_exempt = ?, prune_flagged_at = CASE WHEN ? THEN NULL ELSE prune_flagged_at END WHERE hotkey = ?",
+		req.Exempt, req.Exempt, req.Hotkey,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to update prune exemption", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update API key"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+	}
+
+	cc.Log.Infow("Prune exemption updated", "hotkey", req.Hotkey, "exempt", req.Exempt)
+	auditAdmin(cc, auditKeyPruneExempt, req.Hotkey, map[string]any{"exempt": req.Exempt})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Prune exemption updated",
+	})
+}
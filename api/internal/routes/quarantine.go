@@ -0,0 +1,243 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// inspectPayload looks for structure no honest client produces, returning the
+// reason the payload should be quarantined or "" when it looks legitimate
+func inspectPayload(settings config.QuarantineSettings, req *shared.VerificationRequest) string {
+	if settings.MaxChunks > 0 && len(req.RawChunks) > settings.MaxChunks {
+		return fmt.Sprintf("raw_chunks has %d chunks, limit is %d", len(req.RawChunks), settings.MaxChunks)
+	}
+
+	for i, chunk := range req.RawChunks {
+		if chunk == nil {
+			return fmt.Sprintf("raw_chunks[%d] is null", i)
+		}
+		if reason := inspectValue(settings, chunk, 1); reason != "" {
+			return fmt.Sprintf("raw_chunks[%d]: %s", i, reason)
+		}
+	}
+
+	if reason := inspectValue(settings, req.RequestParams, 1); reason != "" {
+		return "request_params: " + reason
+	}
+
+	return ""
+}
+
+// inspectValue walks a decoded JSON value checking nesting depth, field sizes
+// and object keys carrying control characters
+func inspectValue(settings config.QuarantineSettings, v any, depth int) string {
+	if settings.MaxDepth > 0 && depth > settings.MaxDepth {
+		return fmt.Sprintf("nesting deeper than %d levels", settings.MaxDepth)
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			if strings.IndexFunc(key, unicode.IsControl) >= 0 {
+				return fmt.Sprintf("key %q contains control characters", key)
+			}
+			if reason := inspectValue(settings, value, depth+1); reason != "" {
+				return reason
+			}
+		}
+	case []any:
+		for _, value := range t {
+			if reason := inspectValue(settings, value, depth+1); reason != "" {
+				return reason
+			}
+		}
+	case string:
+		if settings.MaxFieldBytes > 0 && int64(len(t)) > settings.MaxFieldBytes {
+			return fmt.Sprintf("field of %d bytes exceeds limit of %d", len(t), settings.MaxFieldBytes)
+		}
+	}
+
+	return ""
+}
+
+// quarantinePayload persists a rejected payload for later review
+func quarantinePayload(cc *shared.Context, req *shared.VerificationRequest, reason string) (int64, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	id, err := cc.Cfg.SqlClient.InsertID(
+		"INSERT INTO payload_quarantine (hotkey, request_id, model, reason, payload, source_ip) VALUES (?, ?, ?, ?, ?, ?)",
+		cc.Hotkey, req.RequestID, req.Model, reason, payload, cc.RealIP(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store quarantined payload: %w", err)
+	}
+
+	cc.Log.Warnw("Payload quarantined",
+		"quarantine_id", id,
+		"hotkey", cc.Hotkey,
+		"request_id", req.RequestID,
+		"model", req.Model,
+		"reason", reason,
+	)
+	return id, nil
+}
+
+// ListQuarantine handler for listing quarantined payloads, pending review by default
+func ListQuarantine(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	conditions, args, err := parseTimeRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	switch c.QueryParam("status") {
+	case "", "pending":
+		conditions = append(conditions, "reviewed_at IS NULL")
+	case "reviewed":
+		conditions = append(conditions, "reviewed_at IS NOT NULL")
+	case "all":
+	default:
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "status must be pending, reviewed or all"))
+	}
+	if hotkey := c.QueryParam("hotkey"); hotkey != "" {
+		conditions = append(conditions, "hotkey = ?")
+		args = append(args, hotkey)
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM payload_quarantine"+where, args...).Scan(&total); err != nil {
+		cc.Log.Errorw("Failed to count quarantined payloads", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list quarantined payloads"))
+	}
+
+	rows, err := cc.Cfg.SqlClient.Query(
+		"SELECT id, hotkey, COALESCE(request_id, ''), model, reason, COALESCE(source_ip, ''), reviewed_at, COALESCE(reviewed_by, ''), COALESCE(review_note, ''), created_at FROM payload_quarantine"+where+
+			" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to list quarantined payloads", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list quarantined payloads"))
+	}
+	defer rows.Close()
+
+	payloads := []shared.QuarantinedPayload{}
+	for rows.Next() {
+		var p shared.QuarantinedPayload
+		if err := rows.Scan(&p.Id, &p.Hotkey, &p.RequestID, &p.Model, &p.Reason, &p.SourceIP,
+			&p.ReviewedAt, &p.ReviewedBy, &p.ReviewNote, &p.CreatedAt); err != nil {
+			cc.Log.Errorw("Failed to scan quarantined payload", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list quarantined payloads"))
+		}
+		payloads = append(payloads, p)
+	}
+
+	return c.JSON(http.StatusOK, shared.QuarantineListResponse{
+		Payloads: payloads,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// GetQuarantined handler for retrieving a quarantined payload in full
+func GetQuarantined(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid quarantine id"))
+	}
+
+	var p shared.QuarantinedPayload
+	var payload []byte
+	err = cc.Cfg.SqlClient.QueryRow(
+		"SELECT id, hotkey, COALESCE(request_id, ''), model, reason, payload, COALESCE(source_ip, ''), reviewed_at, COALESCE(reviewed_by, ''), COALESCE(review_note, ''), created_at FROM payload_quarantine WHERE id = ?",
+		id,
+	).Scan(&p.Id, &p.Hotkey, &p.RequestID, &p.Model, &p.Reason, &payload, &p.SourceIP,
+		&p.ReviewedAt, &p.ReviewedBy, &p.ReviewNote, &p.CreatedAt)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Quarantined payload not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Database error retrieving quarantined payload", "error", err.Error(), "id", id)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve quarantined payload"))
+	}
+	p.Payload = payload
+
+	return c.JSON(http.StatusOK, p)
+}
+
+// ReviewQuarantined handler for marking a quarantined payload as reviewed
+func ReviewQuarantined(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid quarantine id"))
+	}
+
+	var req shared.ReviewQuarantineRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	result, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE payload_quarantine SET reviewed_at = ?, reviewed_by = ?, review_note = ? WHERE id = ? AND reviewed_at IS NULL",
+		time.Now(), cc.Hotkey, req.Note, id,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to review quarantined payload", "error", err.Error(), "id", id)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to review quarantined payload"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "No pending quarantined payload with this id"))
+	}
+
+	cc.Log.Infow("Quarantined payload reviewed", "id", id, "reviewed_by", cc.Hotkey)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Quarantined payload reviewed",
+	})
+}
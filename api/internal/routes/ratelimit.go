@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"net/http"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// keyRateLimit returns the hotkey's rate limit: the key's own override if set,
+// otherwise the global limit, scaled by stake when metagraph data is available
+func keyRateLimit(cc *shared.Context) (float64, int) {
+	rps := cc.Cfg.Env.RateLimitRPS
+	burst := cc.Cfg.Env.RateLimitBurst
+	if cc.Key.RateLimitRPS != nil {
+		rps = *cc.Key.RateLimitRPS
+	}
+	if cc.Key.RateLimitBurst != nil {
+		burst = *cc.Key.RateLimitBurst
+	}
+	if cc.Key.RateLimitRPS != nil || cc.Key.RateLimitBurst != nil {
+		return rps, burst
+	}
+
+	if !cc.Cfg.Metagraph.Enabled() {
+		return rps, burst
+	}
+
+	stake, _ := cc.Cfg.Metagraph.Stake(cc.Hotkey)
+	weight, err := cc.Cfg.Weights.Weight(stake, cc.Cfg.Metagraph.MinStake)
+	if err != nil {
+		cc.Log.Warnw("Failed to evaluate stake weight", "error", err.Error(), "hotkey", cc.Hotkey)
+		return rps, burst
+	}
+
+	scaledBurst := int(float64(burst) * weight)
+	if scaledBurst < 1 {
+		scaledBurst = 1
+	}
+	return rps * weight, scaledBurst
+}
+
+// SetRateLimit handler for setting or clearing a key's rate limit override
+func SetRateLimit(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetRateLimitRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	if (req.RPS != nil && *req.RPS < 0) || (req.Burst != nil && *req.Burst < 1) {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "rps must be non-negative and burst must be positive"))
+	}
+
+	result, err := cc.Cfg.SqlClient.Exec(
+		"UPDATE api_keys SET rate_limit_rps = ?, rate_limit_burst = ? WHERE hotkey = ?",
+		req.RPS, req.Burst, req.Hotkey,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to update rate limit", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update rate limit"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		var exists int
+		if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM api_keys WHERE hotkey = ?", req.Hotkey).Scan(&exists); err == nil && exists == 0 {
+			return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+		}
+	}
+
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("Rate limit updated", "hotkey", req.Hotkey, "rps", req.RPS, "burst", req.Burst)
+	auditAdmin(cc, auditKeyRateLimit, req.Hotkey, map[string]any{"rps": req.RPS, "burst": req.Burst})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Rate limit updated",
+	})
+}
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"errors"
+
+	"api/internal/errorsink"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errorTags identifies the request an error report came from
+func errorTags(cc *shared.Context, model string) map[string]string {
+	tags := map[string]string{}
+	if cc.Reqid != "" {
+		tags["request_id"] = "req_" + cc.Reqid
+	}
+	if cc.ClientReqid != "" {
+		tags["client_request_id"] = cc.ClientReqid
+	}
+	if cc.Hotkey != "" {
+		tags["hotkey"] = cc.Hotkey
+	}
+	if model != "" {
+		tags["model"] = model
+	}
+	return tags
+}
+
+// PanicEvent builds the error report for a panic recovered while handling c
+func PanicEvent(c echo.Context, err error, stack []byte) errorsink.Event {
+	event := errorsink.Event{Kind: errorsink.KindPanic, Message: err.Error(), Stack: string(stack)}
+	if cc, ok := c.(*shared.Context); ok {
+		event.Tags = errorTags(cc, cc.Outcome.Model)
+	} else {
+		event.Tags = map[string]string{}
+	}
+	event.Tags["path"] = c.Path()
+	return event
+}
+
+// reportBackendError reports a failed backend call; shed and cancelled calls
+// are not the backend's fault and are left out
+func reportBackendError(cc *shared.Context, req *shared.VerificationRequest, backend string, err error) {
+	if err == nil || !errors.As(err, &backendFailure{}) {
+		return
+	}
+
+	tags := errorTags(cc, req.Model)
+	tags["backend"] = backend
+	if req.RequestID != "" {
+		tags["verify_request_id"] = req.RequestID
+	}
+	errorsink.Capture(errorsink.Event{Kind: errorsink.KindBackend, Message: err.Error(), Tags: tags})
+}
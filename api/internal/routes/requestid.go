@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"net/http"
+
+	"api/internal/shared"
+)
+
+// Headers carrying request IDs between callers, the proxy and backends
+const (
+	HeaderRequestID      = "X-Request-ID"
+	HeaderProxyRequestID = "X-Proxy-Request-ID"
+)
+
+// maxClientRequestID bounds the caller's request ID kept for logs and headers
+const maxClientRequestID = 128
+
+// ClientRequestID returns the caller's X-Request-ID when it is safe to log and
+// forward, which is at most 128 printable ASCII characters
+func ClientRequestID(header string) string {
+	if len(header) > maxClientRequestID {
+		return ""
+	}
+	for i := 0; i < len(header); i++ {
+		if header[i] < 0x21 || header[i] > 0x7e {
+			return ""
+		}
+	}
+	return header
+}
+
+// SetRequestIDHeaders adds the proxy's request ID and the caller's, if it sent
+// one, to h
+func SetRequestIDHeaders(cc *shared.Context, h http.Header) {
+	if cc.Reqid != "" {
+		h.Set(HeaderProxyRequestID, "req_"+cc.Reqid)
+	}
+	if cc.ClientReqid != "" {
+		h.Set(HeaderRequestID, cc.ClientReqid)
+	}
+}
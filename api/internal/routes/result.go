@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VerifyResult handler for recovering the verdict of an earlier verification
+// by request_id without resubmitting its payload. Only the key that requested
+// the verification can read it back.
+func VerifyResult(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	if valid, err := validateAPIKey(cc); !valid {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, err.Error()))
+	}
+
+	detail, err := parseDetail(c.QueryParam("detail"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	requestID := c.Param("request_id")
+
+	// The log establishes that the caller owns the request_id, since cache
+	// entries are shared by every key
+	var logged shared.VerificationResponse
+	var cause, causeCode, errMsg sql.NullString
+	var inputTokens, responseTokens sql.NullInt64
+	err = cc.Cfg.SqlClient.QueryRow(
+		"SELECT verified, cause, cause_code, error, input_tokens, response_tokens, gpus FROM verification_logs WHERE hotkey = ? AND request_id = ? ORDER BY id DESC LIMIT 1",
+		cc.Hotkey, requestID,
+	).Scan(&logged.Verified, &cause, &causeCode, &errMsg, &inputTokens, &responseTokens, &logged.GPUs)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "Verification result not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Database error retrieving verification result", "error", err.Error(), "request_id", requestID)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve verification result"))
+	}
+
+	// The cached response carries every field the backend returned
+	if cached, found := cc.Cfg.Cache.Get(resultCacheKey(requestID)); found {
+		var response shared.VerificationResponse
+		if err := json.Unmarshal(cached, &response); err == nil && response.Verified == logged.Verified {
+			c.Response().Header().Set("X-Result-Source", SourceCache)
+			return c.JSONBlob(http.StatusOK, trimResponse(cached, detail))
+		}
+	}
+
+	logged.RequestID = requestID
+	logged.Cause = cause.String
+	logged.CauseCode = causeCode.String
+	logged.Error = errMsg.String
+	if inputTokens.Valid {
+		logged.InputTokens = &inputTokens.Int64
+	}
+	if responseTokens.Valid {
+		logged.ResponseTokens = &responseTokens.Int64
+	}
+
+	body, err := json.Marshal(logged)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve verification result"))
+	}
+	c.Response().Header().Set("X-Result-Source", "log")
+	return c.JSONBlob(http.StatusOK, trimResponse(body, detail))
+}
@@ -0,0 +1,138 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"api/internal/config"
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// retentionTables are the per-verification tables the log retention applies to
+var retentionTables = []string{"verification_logs", "verification_tags"}
+
+// retentionMutex keeps a manual cleanup from running alongside the janitor
+var retentionMutex sync.Mutex
+
+// StartLogRetention deletes verification logs older than the retention on every interval
+func StartLogRetention(cfg *config.Config, log *zap.SugaredLogger) {
+	settings := cfg.Env.LogRetention
+	if !settings.Enabled() {
+		return
+	}
+
+	retentionLog := log.With("phase", "log_retention")
+	go func() {
+		ticker := time.NewTicker(settings.Interval)
+		defer ticker.Stop()
+		for {
+			retentionMutex.Lock()
+			cutoff := time.Now().Add(-settings.Age)
+			deleted, err := expireRows(cfg, cutoff)
+			retentionMutex.Unlock()
+			if err != nil {
+				retentionLog.Errorw("Verification log retention failed", "error", err.Error())
+			} else {
+				retentionLog.Infow("Expired old verification logs", "cutoff", cutoff, "deleted", deleted)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// expireRows deletes rows created before cutoff from the retention tables in
+// batches, returning how many were deleted from each. The caller holds
+// retentionMutex.
+func expireRows(cfg *config.Config, cutoff time.Time) (map[string]int64, error) {
+	batchSize := cfg.Env.LogRetention.BatchSize
+	deleted := make(map[string]int64, len(retentionTables))
+
+	for _, table := range retentionTables {
+		deleted[table] = 0
+		for {
+			// Deleting up to the highest id of a batch keeps each statement
+			// short without relying on DELETE ... LIMIT, which not every driver has
+			var through *int64
+			err := cfg.SqlClient.QueryRow(
+				"SELECT MAX(id) FROM (SELECT id FROM "+table+" WHERE created_at < ? ORDER BY id LIMIT ?) batch",
+				cutoff, batchSize,
+			).Scan(&through)
+			if err != nil {
+				return deleted, fmt.Errorf("failed to select expired rows of %s: %w", table, err)
+			}
+			if through == nil {
+				break
+			}
+
+			result, err := cfg.SqlClient.Exec("DELETE FROM "+table+" WHERE created_at < ? AND id <= ?", cutoff, *through)
+			if err != nil {
+				return deleted, fmt.Errorf("failed to delete expired rows of %s: %w", table, err)
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return deleted, fmt.Errorf("failed to delete expired rows of %s: %w", table, err)
+			}
+			deleted[table] += n
+			metrics.RowsExpired.WithLabelValues(table).Add(float64(n))
+			if n == 0 {
+				break
+			}
+		}
+	}
+
+	return deleted, nil
+}
+
+// RetentionCleanup handler for running the verification log retention now
+func RetentionCleanup(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.RetentionCleanupRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	age := cc.Cfg.Env.LogRetention.Age
+	if req.OlderThan != "" {
+		parsed, err := time.ParseDuration(req.OlderThan)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "older_than must be a positive duration such as 720h"))
+		}
+		age = parsed
+	}
+	if age <= 0 {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "older_than is required when no retention is configured"))
+	}
+
+	if !retentionMutex.TryLock() {
+		return c.JSON(http.StatusConflict, errorResponse(cc, shared.CodeConflict, "A retention cleanup is already running"))
+	}
+	cutoff := time.Now().Add(-age)
+	deleted, err := expireRows(cc.Cfg, cutoff)
+	retentionMutex.Unlock()
+	if err != nil {
+		cc.Log.Errorw("Retention cleanup failed", "error", err.Error(), "deleted", deleted)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Retention cleanup failed"))
+	}
+
+	cc.Log.Infow("Retention cleanup completed", "cutoff", cutoff, "deleted", deleted)
+	auditAdmin(cc, auditRetentionClean, "", map[string]any{"cutoff": cutoff, "deleted": deleted})
+
+	return c.JSON(http.StatusOK, shared.RetentionCleanupResponse{
+		Cutoff:  cutoff,
+		Deleted: deleted,
+	})
+}
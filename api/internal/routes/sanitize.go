@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"api/internal/config"
+	"api/internal/metrics"
+	"api/internal/shared"
+)
+
+// Sanitization kinds, as reported in logs, metrics and the X-Sanitized header
+const (
+	sanitizeControlChars = "control_chars"
+	sanitizeInvalidUTF8  = "invalid_utf8"
+	sanitizeTruncated    = "truncated"
+)
+
+// sanitizeChunks scrubs the string values of raw_chunks in place and returns
+// how many fields each sanitization touched
+func sanitizeChunks(settings config.SanitizeSettings, req *shared.VerificationRequest) map[string]int {
+	applied := make(map[string]int)
+	if !settings.Enabled() {
+		return applied
+	}
+
+	for _, chunk := range req.RawChunks {
+		for key, value := range chunk {
+			chunk[key] = sanitizeValue(settings, value, applied)
+		}
+	}
+	return applied
+}
+
+func sanitizeValue(settings config.SanitizeSettings, v any, applied map[string]int) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			t[key] = sanitizeValue(settings, value, applied)
+		}
+		return t
+	case []any:
+		for i, value := range t {
+			t[i] = sanitizeValue(settings, value, applied)
+		}
+		return t
+	case string:
+		return sanitizeString(settings, t, applied)
+	default:
+		return v
+	}
+}
+
+func sanitizeString(settings config.SanitizeSettings, s string, applied map[string]int) string {
+	if settings.InvalidUTF8 && !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "\uFFFD")
+		applied[sanitizeInvalidUTF8]++
+	}
+
+	if settings.ControlChars && strings.IndexFunc(s, isStrayControl) >= 0 {
+		s = strings.Map(func(r rune) rune {
+			if isStrayControl(r) {
+				return -1
+			}
+			return r
+		}, s)
+		applied[sanitizeControlChars]++
+	}
+
+	if settings.MaxFieldBytes > 0 && int64(len(s)) > settings.MaxFieldBytes {
+		cut := int(settings.MaxFieldBytes)
+		// Back off to a rune boundary so truncation never produces invalid UTF-8
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+		applied[sanitizeTruncated]++
+	}
+
+	return s
+}
+
+// isStrayControl reports whether r is a control character other than the
+// whitespace that legitimately appears in generated text
+func isStrayControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t'
+}
+
+// recordSanitization logs and counts the sanitizations applied to a request
+// and reports them to the caller in the X-Sanitized header
+func recordSanitization(cc *shared.Context, req *shared.VerificationRequest, applied map[string]int) {
+	if len(applied) == 0 {
+		return
+	}
+
+	kinds := make([]string, 0, len(applied))
+	for kind := range applied {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		metrics.SanitizedFields.WithLabelValues(req.Model, kind).Add(float64(applied[kind]))
+		parts = append(parts, fmt.Sprintf("%s=%d", kind, applied[kind]))
+	}
+	summary := strings.Join(parts, ",")
+
+	cc.Response().Header().Set("X-Sanitized", summary)
+	cc.Log.Infow("Sanitized raw chunks",
+		"request_id", req.RequestID,
+		"model", req.Model,
+		"sanitized", summary,
+	)
+}
@@ -0,0 +1,160 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireScope only lets requests through whose API key or admin session holds
+// scope. The handlers' own admin check then accepts the narrower key.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cc := c.(*shared.Context)
+			if ok, code, errMsg := authorizeScope(c, scope); !ok {
+				return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+			}
+			cc.Scope = scope
+			return next(c)
+		}
+	}
+}
+
+// checkTargetScope refuses changes to a key holding every scope unless the
+// caller holds every scope too, so keys:write cannot take over an admin key.
+// Unknown hotkeys pass and are reported as not found by the handler.
+func checkTargetScope(cc *shared.Context, hotkey string) (bool, int, string) {
+	if cc.Key.HasScope(config.ScopeAll) {
+		return true, 0, ""
+	}
+
+	var scopes string
+	err := cc.Cfg.SqlClient.QueryRow("SELECT scopes FROM api_keys WHERE hotkey = ?", hotkey).Scan(&scopes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, 0, ""
+	} else if err != nil {
+		cc.Log.Errorw("Database error checking target key", "error", err.Error(), "hotkey", hotkey)
+		return false, http.StatusInternalServerError, "Internal server error"
+	}
+
+	if slices.Contains(config.ParseScopes(scopes), config.ScopeAll) {
+		cc.Log.Warnw("Refused change to an admin key by a key without every scope", "hotkey", hotkey, "actor", cc.Hotkey)
+		return false, http.StatusForbidden, missingScopeMessage(config.ScopeAll)
+	}
+	return true, 0, ""
+}
+
+// missingScopeReason keeps reporting keys without ScopeAll as non-admin keys
+func missingScopeReason(scope string) string {
+	if scope == config.ScopeAll {
+		return authNotAdmin
+	}
+	return authMissingScope
+}
+
+// missingScopeMessage describes the scope a key is missing
+func missingScopeMessage(scope string) string {
+	if scope == config.ScopeAll {
+		return "Administrator privileges required"
+	}
+	return "API key lacks the " + scope + " scope"
+}
+
+// GrantScopes handler for adding scopes to an API key
+func GrantScopes(c echo.Context) error {
+	return updateScopes(c, true)
+}
+
+// RevokeScopes handler for removing scopes from an API key
+func RevokeScopes(c echo.Context) error {
+	return updateScopes(c, false)
+}
+
+// updateScopes grants or revokes the requested scopes. Revoking a scope from a
+// key holding ScopeAll leaves it in effect until ScopeAll is revoked as well.
+func updateScopes(c echo.Context, grant bool) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetScopesRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+	if len(req.Scopes) == 0 {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "scopes is required"))
+	}
+	if err := config.ValidateScopes(req.Scopes); err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	// An admin demoting itself could leave the proxy without anyone to undo it
+	if !grant && req.Hotkey == cc.Hotkey && slices.Contains(req.Scopes, config.ScopeAll) {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Cannot revoke * from the key making the request"))
+	}
+
+	var stored string
+	err := cc.Cfg.SqlClient.QueryRow("SELECT scopes FROM api_keys WHERE hotkey = ?", req.Hotkey).Scan(&stored)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Failed to read key scopes", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update scopes"))
+	}
+
+	scopes := config.ParseScopes(stored)
+	if grant {
+		scopes = append(scopes, req.Scopes...)
+	} else {
+		scopes = slices.DeleteFunc(scopes, func(scope string) bool {
+			return slices.Contains(req.Scopes, scope)
+		})
+	}
+	formatted := config.FormatScopes(scopes)
+
+	// is_admin mirrors ScopeAll for the tools that still read it
+	_, err = cc.Cfg.SqlClient.Exec(
+		"UPDATE api_keys SET scopes = ?, is_admin = ? WHERE hotkey = ?",
+		formatted, slices.Contains(scopes, config.ScopeAll), req.Hotkey,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to update key scopes", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update scopes"))
+	}
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+
+	cc.Log.Infow("API key scopes updated",
+		"hotkey", req.Hotkey,
+		"granted", grant,
+		"changed", req.Scopes,
+		"scopes", formatted,
+		"admin_hotkey", cc.Hotkey,
+	)
+
+	action := auditKeyScopesRevoke
+	if grant {
+		action = auditKeyScopesGrant
+	}
+	auditAdmin(cc, action, req.Hotkey, map[string]any{"changed": req.Scopes, "scopes": formatted})
+
+	return c.JSON(http.StatusOK, shared.KeyScopesResponse{
+		Hotkey: req.Hotkey,
+		Scopes: config.ParseScopes(formatted),
+	})
+}
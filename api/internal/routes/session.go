@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"api/internal/apikey"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sessionCookie carries admin sessions minted by CreateSession
+const sessionCookie = "proxy_admin_session"
+
+func sessionCacheKey(token string) string {
+	return "session:" + apikey.Hash(token)
+}
+
+// sessionHotkey resolves the admin session cookie of a request to its hotkey.
+// Sessions are only honoured for reads, so a cross-site form cannot use the
+// cookie to change state.
+func sessionHotkey(c echo.Context) (string, bool) {
+	if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead {
+		return "", false
+	}
+
+	cookie, err := c.Cookie(sessionCookie)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+
+	cc := c.(*shared.Context)
+	hotkey, found := cc.Cfg.Cache.Get(sessionCacheKey(cookie.Value))
+	if !found || len(hotkey) == 0 {
+		return "", false
+	}
+	return string(hotkey), true
+}
+
+// CreateSession handler for exchanging an admin key for a short-lived session cookie
+func CreateSession(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Sessions can only be minted with the admin key itself
+	if c.Request().Header.Get("Authorization") == "" {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, "Authorization required"))
+	}
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	token, err := apikey.Generate()
+	if err != nil {
+		cc.Log.Errorw("Failed to generate session token", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to create session"))
+	}
+
+	ttl := cc.Cfg.Env.AdminSessionTTL
+	expiresAt := time.Now().Add(ttl)
+	cc.Cfg.Cache.Set(sessionCacheKey(token), []byte(cc.Hotkey), ttl)
+
+	c.SetCookie(&http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		MaxAge:   int(ttl.Seconds()),
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	cc.Log.Infow("Admin session created", "hotkey", cc.Hotkey, "expires_at", expiresAt)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message":    "Session created",
+		"expires_at": expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// EndSession handler for revoking the caller's session cookie
+func EndSession(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	if cookie, err := c.Cookie(sessionCookie); err == nil && cookie.Value != "" {
+		cc.Cfg.Cache.Delete(sessionCacheKey(cookie.Value))
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   c.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Session ended",
+	})
+}
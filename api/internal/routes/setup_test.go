@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"api/internal/apikey"
+	"api/internal/config"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// newTestDB opens a migrated database for one test. With TEST_POSTGRES_DSN set
+// to a postgres:// URL it is a fresh schema on that server, dropped after the
+// test; otherwise it is SQLite in a temporary directory.
+func newTestDB(t *testing.T) *config.DB {
+	t.Helper()
+
+	var db *config.DB
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		db = openPostgresSchema(t, dsn)
+	} else {
+		var err error
+		db, err = config.OpenDB(config.DialectSQLite, filepath.Join(t.TempDir(), "proxy.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+	}
+
+	if _, err := config.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// openPostgresSchema creates a schema for the test and connects with it as the search path
+func openPostgresSchema(t *testing.T, dsn string) *config.DB {
+	t.Helper()
+
+	admin, err := config.OpenDB(config.DialectPostgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	db, err := config.OpenDB(config.DialectPostgres, u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+	return db
+}
+
+// newTestConfig returns a config backed by a migrated test database, with key
+// lookups uncached
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	db := newTestDB(t)
+	return &config.Config{
+		SqlClient: db,
+		Cache:     config.NewVerificationCache(),
+		Keys:      config.NewKeyCache(config.NewSQLKeyStore(db), 0, 0),
+	}
+}
+
+// newTestServer wraps every request in a shared.Context the way the server does
+func newTestServer(cfg *config.Config) *echo.Echo {
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return next(&shared.Context{Context: c, Log: zap.NewNop().Sugar(), Reqid: "test", Cfg: cfg})
+		}
+	})
+	return e
+}
+
+// insertKey stores an active key for hotkey with the given scopes and returns its value
+func insertKey(t *testing.T, cfg *config.Config, hotkey, scopes string) string {
+	t.Helper()
+
+	keyValue, err := apikey.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.SqlClient.Exec(
+		"INSERT INTO api_keys (hotkey, key_hash, key_hint, is_admin, scopes) VALUES (?, ?, ?, ?, ?)",
+		hotkey, apikey.Hash(keyValue), apikey.Hint(keyValue), scopes == config.ScopeAll, scopes,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyValue
+}
+
+// serve sends a request with a bearer key through e and returns the recorder
+func serve(e *echo.Echo, method, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
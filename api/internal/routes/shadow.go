@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"api/internal/metrics"
+	"api/internal/shared"
+)
+
+// shadowSlots bounds the number of shadow requests in flight so a slow shadow
+// backend cannot pile up goroutines
+var shadowSlots = make(chan struct{}, 64)
+
+// mirrorToShadow duplicates a sampled share of verifications to the shadow
+// backend and compares its verdict with the primary one. It never blocks or
+// alters the primary response.
+func mirrorToShadow(cc *shared.Context, req *shared.VerificationRequest, primary []byte) {
+	env := cc.Cfg.Env
+	percent := cc.Cfg.Runtime().ShadowPercent
+	if env.ShadowBackendURL == "" || percent <= 0 || rand.Float64()*100 >= percent {
+		return
+	}
+
+	select {
+	case shadowSlots <- struct{}{}:
+	default:
+		metrics.ShadowComparisons.WithLabelValues(req.Model, "dropped").Inc()
+		return
+	}
+
+	shadowReq := *req
+	shadowReq.Tags = nil
+	log := cc.Log
+	client := &http.Client{Timeout: env.BackendTimeout(req.Model), Transport: cc.Cfg.Transport}
+	headers := http.Header{}
+	SetRequestIDHeaders(cc, headers)
+	go func() {
+		defer func() { <-shadowSlots }()
+
+		startTime := time.Now()
+		body, err := sendToShadow(client, env.ShadowBackendURL+"/verify", &shadowReq, headers)
+		if err != nil {
+			metrics.ShadowComparisons.WithLabelValues(shadowReq.Model, "error").Inc()
+			log.Warnw("Shadow verification failed", "error", err.Error(), "request_id", shadowReq.RequestID)
+			return
+		}
+
+		var primaryResp shared.VerificationResponse
+		if err := json.Unmarshal(primary, &primaryResp); err != nil {
+			return
+		}
+		shadowResp, err := parseBackendResponse(body, "")
+		if err != nil {
+			metrics.ShadowComparisons.WithLabelValues(shadowReq.Model, "error").Inc()
+			log.Warnw("Shadow backend returned invalid response", "error", err.Error(), "request_id", shadowReq.RequestID)
+			return
+		}
+
+		if primaryResp.Verified == shadowResp.Verified {
+			metrics.ShadowComparisons.WithLabelValues(shadowReq.Model, "match").Inc()
+			return
+		}
+
+		metrics.ShadowComparisons.WithLabelValues(shadowReq.Model, "mismatch").Inc()
+		log.Warnw("Shadow verdict differs from primary",
+			"request_id", shadowReq.RequestID,
+			"model", shadowReq.Model,
+			"primary_verified", primaryResp.Verified,
+			"primary_cause", primaryResp.Cause,
+			"shadow_verified", shadowResp.Verified,
+			"shadow_cause", shadowResp.Cause,
+			"shadow_ms", time.Since(startTime).Milliseconds(),
+		)
+	}()
+}
+
+// sendToShadow posts a verification to the shadow backend without retries
+func sendToShadow(client *http.Client, url string, req *shared.VerificationRequest, headers http.Header) ([]byte, error) {
+	requestBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range headers {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("x-backend-server", req.Model)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(headerSchema, strconv.Itoa(schemaV2))
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to shadow backend: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if httpResp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("shadow backend returned status %d", httpResp.StatusCode)
+	}
+
+	return body, nil
+}
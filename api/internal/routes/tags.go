@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	maxTags      = 16
+	maxTagLength = 64
+)
+
+// validateTags checks the optional request tags
+func validateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return fmt.Errorf("at most %d tags are allowed", maxTags)
+	}
+	for _, tag := range tags {
+		if tag == "" || len(tag) > maxTagLength {
+			return fmt.Errorf("tags must be between 1 and %d characters", maxTagLength)
+		}
+	}
+	return nil
+}
+
+// recordTags stores the request's tags along with its verdict
+func recordTags(cc *shared.Context, req *shared.VerificationRequest, verified bool) {
+	for _, tag := range req.Tags {
+		_, err := cc.Cfg.SqlClient.Exec(
+			"INSERT INTO verification_tags (hotkey, request_id, model, tag, verified) VALUES (?, ?, ?, ?, ?)",
+			cc.Hotkey, req.RequestID, req.Model, tag, verified,
+		)
+		if err != nil {
+			cc.Log.Warnw("Failed to record verification tag", "error", err.Error(), "request_id", req.RequestID, "tag", tag)
+		}
+	}
+}
+
+// TagStats handler for a key holder's verification outcomes grouped by tag
+func TagStats(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	if valid, err := validateAPIKey(cc); !valid {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, err.Error()))
+	}
+
+	conditions, args, err := parseTimeRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	conditions = append(conditions, "hotkey = ?")
+	args = append(args, cc.Hotkey)
+
+	if model := c.QueryParam("model"); model != "" {
+		conditions = append(conditions, "model = ?")
+		args = append(args, model)
+	}
+	if tag := c.QueryParam("tag"); tag != "" {
+		conditions = append(conditions, "tag = ?")
+		args = append(args, tag)
+	} else if prefix := c.QueryParam("prefix"); prefix != "" {
+		// Compared with SUBSTR rather than LIKE, so wildcards in the prefix
+		// match literally and the query reads the same in every dialect
+		conditions = append(conditions, "SUBSTR(tag, 1, ?) = ?")
+		args = append(args, utf8.RuneCountInString(prefix), prefix)
+	}
+	rows, err := cc.Cfg.SqlClient.Query(
+		"SELECT tag, COUNT(*), SUM(CASE WHEN verified THEN 1 ELSE 0 END) FROM verification_tags WHERE "+strings.Join(conditions, " AND ")+
+			" GROUP BY tag ORDER BY tag LIMIT 1000",
+		args...,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to query tag stats", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve tag stats"))
+	}
+	defer rows.Close()
+
+	stats := []shared.TagStats{}
+	for rows.Next() {
+		var s shared.TagStats
+		if err := rows.Scan(&s.Tag, &s.Total, &s.Verified); err != nil {
+			cc.Log.Errorw("Failed to scan tag stats", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve tag stats"))
+		}
+		s.Unverified = s.Total - s.Verified
+		stats = append(stats, s)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
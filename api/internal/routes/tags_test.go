@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+func TestTagStatsByPrefix(t *testing.T) {
+	cfg := newTestConfig(t)
+	key := insertKey(t, cfg, "validator", "verify")
+	e := newTestServer(cfg)
+	e.GET("/stats/tags", TagStats)
+
+	for i, row := range []struct {
+		tag      string
+		verified bool
+	}{
+		{"exp_a", true}, {"exp_a", false}, {"exp_a", true}, {"exp%b", true}, {"expxa", true}, {"other", false},
+	} {
+		_, err := cfg.SqlClient.Exec(
+			"INSERT INTO verification_tags (hotkey, request_id, model, tag, verified) VALUES (?, ?, ?, ?, ?)",
+			"validator", fmt.Sprintf("r%d", i), "m", row.tag, row.verified,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := serve(e, http.MethodGet, "/stats/tags?prefix=exp_", key, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	var stats []shared.TagStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Tag != "exp_a" || stats[0].Total != 3 || stats[0].Verified != 2 || stats[0].Unverified != 1 {
+		t.Fatalf("stats = %+v, want exp_a with 3 total and 2 verified", stats)
+	}
+}
+
+// Tags are counted for every served result, not only fresh backend verdicts
+func TestLogVerificationRecordsTags(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.Usage = config.NewUsageTracker(cfg.SqlClient)
+	cc := &shared.Context{Log: zap.NewNop().Sugar(), Cfg: cfg, Hotkey: "validator"}
+	req := &shared.VerificationRequest{Model: "m", RequestID: "r1", Tags: []string{"exp_a", "exp_b"}}
+
+	for _, source := range []string{SourceBackend, SourceCache, SourceDedup, SourceCoalesced} {
+		logVerification(cc, req, []byte(`{"verified":true}`), source, time.Now())
+	}
+
+	var count int
+	if err := cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM verification_tags WHERE tag = ?", "exp_a").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("exp_a recorded %d times, want 4", count)
+	}
+
+	var tags string
+	if err := cfg.SqlClient.QueryRow("SELECT tags FROM verification_logs WHERE source = ?", SourceCache).Scan(&tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != `["exp_a","exp_b"]` {
+		t.Errorf("logged tags = %s, want both request tags", tags)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"api/internal/config"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CountTraffic tracks requests in flight and served, for the shutdown report
+func CountTraffic(traffic *config.Traffic) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			traffic.Begin()
+			err := next(c)
+			if err != nil {
+				// Render the error now so the failure is counted by its status
+				c.Error(err)
+			}
+			traffic.End(c.Response().Status)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"api/internal/config"
+	"api/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+// backendURLs returns every verifier backend the proxy may call
+func backendURLs(cfg *config.Config) []string {
+	backends := map[string]bool{cfg.Env.HaproxyURL: true}
+	for _, route := range cfg.Routes.All() {
+		for _, backend := range route.Backends() {
+			backends[backend] = true
+		}
+	}
+	for _, backend := range cfg.Env.ConsensusBackends {
+		backends[backend] = true
+	}
+	if cfg.Env.ShadowBackendURL != "" {
+		backends[cfg.Env.ShadowBackendURL] = true
+	}
+
+	urls := make([]string, 0, len(backends))
+	for backend := range backends {
+		urls = append(urls, backend)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// prewarmBackend opens up to BACKEND_PREWARM_CONNS pooled connections to a
+// backend by pinging it concurrently, returning the first failure
+func prewarmBackend(cfg *config.Config, backendURL string) error {
+	conns := max(cfg.Env.BackendPrewarm, 1)
+	errs := make([]error, conns)
+
+	var wg sync.WaitGroup
+	for i := 0; i < conns; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			errs[i] = pingBackend(ctx, cfg, backendURL)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartBackendRefresh periodically re-resolves backend hostnames and, when one
+// moves, rotates its pooled connections and pre-warms new ones, so failovers
+// and DNS-based backend swaps do not surface as errors on stale connections
+func StartBackendRefresh(cfg *config.Config, log *zap.SugaredLogger) {
+	if cfg.Env.Mock.Enabled || cfg.Env.BackendDNSRefresh <= 0 {
+		return
+	}
+
+	refreshLog := log.With("phase", "backend_refresh")
+	refresh := func() {
+		urls := backendURLs(cfg)
+		hostURLs := make(map[string][]string)
+		var hosts []string
+		for _, backend := range urls {
+			parsed, err := url.Parse(backend)
+			if err != nil || parsed.Hostname() == "" {
+				continue
+			}
+			if _, ok := hostURLs[parsed.Hostname()]; !ok {
+				hosts = append(hosts, parsed.Hostname())
+			}
+			hostURLs[parsed.Hostname()] = append(hostURLs[parsed.Hostname()], backend)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		// In-flight calls may hold stale connections for up to the backend timeout
+		changed := cfg.Transport.Refresh(ctx, hosts, cfg.Env.BackendDefaultTimeout)
+
+		for _, host := range changed {
+			metrics.BackendDNSChanges.WithLabelValues(host).Inc()
+			refreshLog.Infow("Backend address changed, rotating connections", "host", host)
+			for _, backend := range hostURLs[host] {
+				if err := prewarmBackend(cfg, backend); err != nil {
+					refreshLog.Warnw("Failed to pre-warm backend after address change", "url", backend, "error", err.Error())
+				}
+			}
+		}
+	}
+
+	go func() {
+		refresh()
+		ticker := time.NewTicker(cfg.Env.BackendDNSRefresh)
+		for range ticker.C {
+			refresh()
+		}
+	}()
+}
+
+// StartBackendHealthChecks probes every backend of a multi-backend route on
+// each interval, ejecting backends that keep failing from load balancing and
+// restoring them once they answer again
+func StartBackendHealthChecks(cfg *config.Config, log *zap.SugaredLogger) {
+	if cfg.Env.Mock.Enabled || cfg.Env.BackendHealthTick <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Env.BackendHealthTick)
+	go func() {
+		for range ticker.C {
+			probed := make(map[string]bool)
+			for _, route := range cfg.Routes.All() {
+				backends := route.Backends()
+				if len(backends) < 2 {
+					continue
+				}
+				for _, backend := range backends {
+					if probed[backend] {
+						continue
+					}
+					probed[backend] = true
+					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+					err := pingBackend(ctx, cfg, backend)
+					cancel()
+					if err != nil {
+						log.Warnw("Backend health probe failed", "model", route.Model, "url", backend, "error", err.Error())
+					}
+					cfg.Balancer.Report(backend, err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,303 @@
+package routes
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/metrics"
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// keyDailyQuota returns the hotkey's daily quota: the key's own override if
+// set, otherwise the global quota. Zero means unlimited.
+func keyDailyQuota(cc *shared.Context) int {
+	if cc.Key.DailyQuota != nil {
+		return *cc.Key.DailyQuota
+	}
+	return cc.Cfg.Runtime().DailyQuota
+}
+
+// checkDailyQuota enforces the per-hotkey verification quota for the current UTC day
+func checkDailyQuota(cc *shared.Context, model string) (*shared.VerifyErrorResponse, int) {
+	quota := keyDailyQuota(cc)
+	if quota <= 0 {
+		return nil, 0
+	}
+
+	used, err := cc.Cfg.Usage.Today(cc.Hotkey)
+	if err != nil {
+		// Usage is unknown while the database is unreachable; fail open
+		cc.Log.Warnw("Failed to read daily usage", "error", err.Error(), "hotkey", cc.Hotkey)
+		return nil, 0
+	}
+
+	if used < int64(quota) {
+		return nil, 0
+	}
+
+	now := time.Now().UTC()
+	resetsAt := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	cc.Log.Warnw("Daily quota exceeded", "hotkey", cc.Hotkey, "used", used, "quota", quota)
+	metrics.VerifyErrors.WithLabelValues(model, "daily_quota").Inc()
+
+	errResp := verifyError(cc, shared.CodeQuotaExceeded, "Daily quota exceeded")
+	errResp.DailyQuotaError = &shared.DailyQuotaError{DailyQuota: quota, Used: used, ResetsAt: resetsAt}
+	retryAfter(cc, &errResp.ErrorResponse, resetsAt.Sub(now))
+	return errResp, http.StatusTooManyRequests
+}
+
+// parseDayRange reads the from and to query parameters as inclusive UTC days
+func parseDayRange(c echo.Context) ([]string, []any, error) {
+	var conditions []string
+	var args []any
+
+	for _, param := range []string{"from", "to"} {
+		v := c.QueryParam(param)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, v); err != nil {
+			return nil, nil, fmt.Errorf("%s must be a date such as 2024-01-31", param)
+		}
+		if param == "from" {
+			conditions = append(conditions, "day >= ?")
+		} else {
+			conditions = append(conditions, "day <= ?")
+		}
+		args = append(args, v)
+	}
+
+	return conditions, args, nil
+}
+
+// queryUsage reads usage records matching the conditions, newest day first
+func queryUsage(cc *shared.Context, conditions []string, args []any, limit, offset int) ([]shared.UsageRecord, int, error) {
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM key_usage"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := cc.Cfg.SqlClient.Query(
+		"SELECT hotkey, day, model, verifications, verified, input_tokens, response_tokens FROM key_usage"+where+
+			" ORDER BY day DESC, hotkey, model LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	records := []shared.UsageRecord{}
+	for rows.Next() {
+		var r shared.UsageRecord
+		var day time.Time
+		if err := rows.Scan(&r.Hotkey, &day, &r.Model, &r.Verifications, &r.Verified, &r.InputTokens, &r.ResponseTokens); err != nil {
+			return nil, 0, err
+		}
+		r.Day = day.Format(time.DateOnly)
+		records = append(records, r)
+	}
+	return records, total, rows.Err()
+}
+
+// ListUsage handler for listing per-hotkey usage
+func ListUsage(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	conditions, args, err := parseDayRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+	if hotkey := c.QueryParam("hotkey"); hotkey != "" {
+		conditions = append(conditions, "hotkey = ?")
+		args = append(args, hotkey)
+	}
+	if model := c.QueryParam("model"); model != "" {
+		conditions = append(conditions, "model = ?")
+		args = append(args, model)
+	}
+
+	records, total, err := queryUsage(cc, conditions, args, limit, offset)
+	if err != nil {
+		cc.Log.Errorw("Failed to list usage", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve usage"))
+	}
+
+	return c.JSON(http.StatusOK, shared.UsageResponse{
+		Usage:  records,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// SelfUsage handler for key holders to read their own usage and quota
+func SelfUsage(c echo.Context) error {
+	cc := c.(*shared.Context)
+
+	if valid, err := validateAPIKey(cc); !valid {
+		return c.JSON(http.StatusUnauthorized, errorResponse(cc, shared.CodeUnauthorized, err.Error()))
+	}
+
+	conditions, args, err := parseDayRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+	conditions = append(conditions, "hotkey = ?")
+	args = append(args, cc.Hotkey)
+
+	records, _, err := queryUsage(cc, conditions, args, 500, 0)
+	if err != nil {
+		cc.Log.Errorw("Failed to read usage", "error", err.Error(), "hotkey", cc.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve usage"))
+	}
+
+	usedToday, err := cc.Cfg.Usage.Today(cc.Hotkey)
+	if err != nil {
+		cc.Log.Warnw("Failed to read daily usage", "error", err.Error(), "hotkey", cc.Hotkey)
+	}
+
+	return c.JSON(http.StatusOK, shared.SelfUsageResponse{
+		Hotkey:     cc.Hotkey,
+		DailyQuota: keyDailyQuota(cc),
+		UsedToday:  usedToday,
+		Usage:      records,
+	})
+}
+
+// SetDailyQuota handler for setting or clearing a key's daily quota override
+func SetDailyQuota(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	var req shared.SetDailyQuotaRequest
+	if err := c.Bind(&req); err != nil {
+		cc.Log.Errorw("Failed to parse request", "error", err.Error())
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "Invalid request format"))
+	}
+
+	if req.Hotkey == "" {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeMissingField, "hotkey is required"))
+	}
+
+	if ok, code, errMsg := checkTargetScope(cc, req.Hotkey); !ok {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	if req.Quota != nil && *req.Quota < 0 {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "quota must be non-negative"))
+	}
+
+	result, err := cc.Cfg.SqlClient.Exec("UPDATE api_keys SET daily_quota = ? WHERE hotkey = ?", req.Quota, req.Hotkey)
+	if err != nil {
+		cc.Log.Errorw("Failed to update daily quota", "error", err.Error(), "hotkey", req.Hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to update daily quota"))
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected == 0 {
+		var exists int
+		if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM api_keys WHERE hotkey = ?", req.Hotkey).Scan(&exists); err == nil && exists == 0 {
+			return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+		}
+	}
+
+	cc.Cfg.Keys.InvalidateHotkey(req.Hotkey)
+	cc.Log.Infow("Daily quota updated", "hotkey", req.Hotkey, "quota", req.Quota)
+	auditAdmin(cc, auditKeyDailyQuota, req.Hotkey, map[string]any{"quota": req.Quota})
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Daily quota updated",
+	})
+}
+
+// maxHeatmapDays bounds how far back a usage heatmap reaches
+const maxHeatmapDays = 90
+
+// KeyHeatmap handler for a key's verification counts per UTC hour over the last days
+func KeyHeatmap(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	hotkey := c.Param("hotkey")
+
+	days := 7
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxHeatmapDays {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, fmt.Sprintf("days must be between 1 and %d", maxHeatmapDays)))
+		}
+		days = n
+	}
+
+	var lastUsed *time.Time
+	err := cc.Cfg.SqlClient.QueryRow("SELECT last_used_at FROM api_keys WHERE hotkey = ?", hotkey).Scan(&lastUsed)
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, errorResponse(cc, shared.CodeNotFound, "API key not found"))
+	} else if err != nil {
+		cc.Log.Errorw("Failed to look up API key", "error", err.Error(), "hotkey", hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve heatmap"))
+	}
+
+	// The window ends with today, so the last row is the partial current day
+	now := time.Now().UTC()
+	first := time.Date(now.Year(), now.Month(), now.Day()-days+1, 0, 0, 0, 0, time.UTC)
+
+	hours, err := cc.Cfg.Usage.Hourly(hotkey, first)
+	if err != nil {
+		cc.Log.Errorw("Failed to read hourly usage", "error", err.Error(), "hotkey", hotkey)
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to retrieve heatmap"))
+	}
+
+	resp := shared.HeatmapResponse{
+		Hotkey:   hotkey,
+		Days:     make([]shared.HeatmapDay, days),
+		LastUsed: lastUsed,
+	}
+	for i := range resp.Days {
+		resp.Days[i].Day = first.AddDate(0, 0, i).Format(time.DateOnly)
+	}
+	for hour, count := range hours {
+		i := int(hour.Sub(first) / (24 * time.Hour))
+		if i < 0 || i >= days {
+			continue
+		}
+		resp.Days[i].Hours[hour.Hour()] += count
+		resp.Days[i].Total += count
+		resp.HourTotal[hour.Hour()] += count
+		resp.Total += count
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"api/internal/shared"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Where a logged verification outcome was served from
+const (
+	SourceBackend   = "backend"
+	SourceDedup     = "dedup"
+	SourceCache     = "cache"
+	SourceCoalesced = "coalesced"
+	SourceOverride  = "override"
+)
+
+// logVerification records a verification outcome and its tags in
+// verification_logs, whatever it was served from, and mirrors it to the
+// analytics endpoint when one is configured
+func logVerification(cc *shared.Context, req *shared.VerificationRequest, body []byte, source string, startTime time.Time) {
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return
+	}
+	cc.Outcome.Verified = &response.Verified
+	cc.Outcome.Source = source
+
+	var tags sql.NullString
+	if len(req.Tags) > 0 {
+		encoded, _ := json.Marshal(req.Tags)
+		tags = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	_, err := cc.Cfg.SqlClient.Exec(
+		"INSERT INTO verification_logs (request_id, hotkey, model, verified, cause, cause_code, error, input_tokens, response_tokens, gpus, latency_ms, source, tags) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		req.RequestID, cc.Hotkey, req.Model, response.Verified, response.Cause, response.CauseCode, response.Error,
+		response.InputTokens, response.ResponseTokens, response.GPUs, time.Since(startTime).Milliseconds(), source, tags,
+	)
+	if err != nil {
+		cc.Log.Warnw("Failed to record verification log", "error", err.Error(), "request_id", req.RequestID)
+	}
+	recordTags(cc, req, response.Verified)
+
+	var usageInput, usageResponse int64
+	if response.InputTokens != nil {
+		usageInput = *response.InputTokens
+	}
+	if response.ResponseTokens != nil {
+		usageResponse = *response.ResponseTokens
+	}
+	cc.Cfg.Usage.Record(cc.Hotkey, req.Model, response.Verified, usageInput, usageResponse)
+
+	mirrorToAnalytics(cc, req, body, source, startTime)
+}
+
+// parseTimeRange reads the since and until query parameters as SQL conditions on created_at
+func parseTimeRange(c echo.Context) ([]string, []any, error) {
+	var conditions []string
+	var args []any
+
+	for _, param := range []string{"since", "until"} {
+		v := c.QueryParam(param)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s must be an RFC3339 timestamp", param)
+		}
+		if param == "since" {
+			conditions = append(conditions, "created_at >= ?")
+		} else {
+			conditions = append(conditions, "created_at < ?")
+		}
+		args = append(args, t)
+	}
+
+	return conditions, args, nil
+}
+
+// ListVerifications handler for querying the verification audit trail
+func ListVerifications(c echo.Context) error {
+	cc := c.(*shared.Context)
+	defer cc.Log.Sync()
+
+	// Check admin authorization
+	if isAdmin, code, errMsg := checkAdminAuth(c); !isAdmin {
+		return c.JSON(code, errorResponse(cc, statusErrorCode(code), errMsg))
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	conditions, args, err := parseTimeRange(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	if hotkey := c.QueryParam("hotkey"); hotkey != "" {
+		conditions = append(conditions, "hotkey = ?")
+		args = append(args, hotkey)
+	}
+	if model := c.QueryParam("model"); model != "" {
+		conditions = append(conditions, "model = ?")
+		args = append(args, model)
+	}
+	if v := c.QueryParam("verified"); v != "" {
+		verified, err := strconv.ParseBool(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, errorResponse(cc, shared.CodeInvalidRequest, "verified must be true or false"))
+		}
+		conditions = append(conditions, "verified = ?")
+		args = append(args, verified)
+	}
+	if tag := c.QueryParam("tag"); tag != "" {
+		conditions = append(conditions, "request_id IN (SELECT request_id FROM verification_tags WHERE tag = ?)")
+		args = append(args, tag)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := cc.Cfg.SqlClient.QueryRow("SELECT COUNT(*) FROM verification_logs"+where, args...).Scan(&total); err != nil {
+		cc.Log.Errorw("Failed to count verification logs", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list verifications"))
+	}
+
+	rows, err := cc.Cfg.SqlClient.Query(
+		"SELECT id, request_id, hotkey, model, verified, cause, COALESCE(cause_code, ''), error, input_tokens, response_tokens, gpus, latency_ms, source, tags, created_at FROM verification_logs"+where+
+			" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		cc.Log.Errorw("Failed to list verification logs", "error", err.Error())
+		return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list verifications"))
+	}
+	defer rows.Close()
+
+	logs := []shared.VerificationLog{}
+	for rows.Next() {
+		var l shared.VerificationLog
+		var tags sql.NullString
+		if err := rows.Scan(&l.Id, &l.RequestID, &l.Hotkey, &l.Model, &l.Verified, &l.Cause, &l.CauseCode, &l.Error,
+			&l.InputTokens, &l.ResponseTokens, &l.GPUs, &l.LatencyMs, &l.Source, &tags, &l.CreatedAt); err != nil {
+			cc.Log.Errorw("Failed to scan verification log", "error", err.Error())
+			return c.JSON(http.StatusInternalServerError, errorResponse(cc, shared.CodeInternal, "Failed to list verifications"))
+		}
+		if tags.Valid {
+			_ = json.Unmarshal([]byte(tags.String), &l.Tags)
+		}
+		logs = append(logs, l)
+	}
+
+	return c.JSON(http.StatusOK, shared.VerificationLogResponse{
+		Verifications: logs,
+		Total:         total,
+		Limit:         limit,
+		Offset:        offset,
+	})
+}
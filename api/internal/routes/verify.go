@@ -2,16 +2,31 @@ package routes
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"api/internal/apikey"
+	"api/internal/config"
+	"api/internal/hotkey"
+	"api/internal/metrics"
 	"api/internal/shared"
+	"api/internal/tracing"
+	"api/internal/validation"
 
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func Verify(c echo.Context) error {
@@ -21,42 +36,211 @@ func Verify(c echo.Context) error {
 	var request shared.VerificationRequest
 	if err := c.Bind(&request); err != nil {
 		cc.Log.Errorw("Failed to parse request", "error", err.Error())
-		return c.JSON(http.StatusBadRequest, map[string]any{
-			"verified": false,
-			"error":    "Invalid request format",
-		})
+		metrics.VerifyErrors.WithLabelValues("", "invalid_request").Inc()
+		return c.JSON(http.StatusBadRequest, verifyError(cc, shared.CodeInvalidRequest, "Invalid request format"))
 	}
 
+	detail, err := parseDetail(c.QueryParam("detail"))
+	if err != nil {
+		metrics.VerifyErrors.WithLabelValues(metricModel(cc, request.Model), "invalid_request").Inc()
+		return c.JSON(http.StatusBadRequest, verifyError(cc, shared.CodeInvalidRequest, err.Error()))
+	}
+
+	cc.Outcome.Model = request.Model
+
+	if errResp, code := admitVerification(cc, &request, false); errResp != nil {
+		return c.JSON(code, errResp)
+	}
+	defer func() {
+		metrics.VerifyDuration.WithLabelValues(request.Model).Observe(time.Since(startTime).Seconds())
+	}()
+
+	response, err := runVerification(cc, &request, requestOptions(cc, &request))
+	if errors.Is(err, context.Canceled) && cc.Ctx().Err() != nil {
+		cc.Log.Infow("Client disconnected, backend call cancelled", "request_id", request.RequestID)
+		metrics.VerifyErrors.WithLabelValues(request.Model, "client_closed").Inc()
+		// Nobody reads the answer; 499 marks it in the access log the way nginx does
+		return c.NoContent(499)
+	}
+	if err != nil {
+		cc.Log.Errorw("Verification failed", "error", err.Error(), "request_id", request.RequestID)
+		status, code := verificationErrorStatus(err)
+		metrics.VerifyErrors.WithLabelValues(request.Model, strings.ToLower(string(code))).Inc()
+		errResp := verifyError(cc, code, "Verification service error: "+err.Error())
+		if code == shared.CodeQueueFull {
+			retryAfter(cc, &errResp.ErrorResponse, cc.Cfg.Workers.Backlog())
+		}
+		return c.JSON(status, errResp)
+	}
+	cc.Cfg.Backoff.Reset(cc.Hotkey)
+
+	return c.JSONBlob(http.StatusOK, trimResponse(response, detail))
+}
+
+// unknownModel labels metrics of unauthenticated requests for models outside
+// the route table, so callers cannot mint series with made-up model names
+const unknownModel = "unknown"
+
+// metricModel returns the model label for a request that is not yet
+// authenticated: the model when it is routed, unknownModel otherwise
+func metricModel(cc *shared.Context, model string) string {
+	if _, ok := cc.Cfg.Routes.Lookup(model); ok {
+		return model
+	}
+	return unknownModel
+}
+
+// admitVerification validates, authenticates and throttles a verification request,
+// returning an error body and status code when the request must be rejected
+func admitVerification(cc *shared.Context, request *shared.VerificationRequest, async bool) (*shared.VerifyErrorResponse, int) {
 	// Validate required fields
-	if missingField, isMissing := validateRequiredFields(cc, &request); isMissing {
-		return c.JSON(http.StatusBadRequest, map[string]any{
-			"verified": false,
-			"error":    "Missing required field: " + missingField,
-		})
+	if missingField, isMissing := validateRequiredFields(cc, request); isMissing {
+		metrics.VerifyErrors.WithLabelValues(metricModel(cc, request.Model), "invalid_request").Inc()
+		return verifyError(cc, shared.CodeMissingField, "Missing required field: "+missingField), http.StatusBadRequest
+	}
+
+	if _, ok := cc.Cfg.Routes.Lookup(request.Model); !ok && !cc.Cfg.Routes.Empty() {
+		cc.Log.Warnw("Unsupported model", "model", request.Model)
+		metrics.VerifyErrors.WithLabelValues(unknownModel, "unsupported_model").Inc()
+		errResp := verifyError(cc, shared.CodeUnsupportedModel, "Unsupported model: "+request.Model)
+		errResp.SupportedModels = cc.Cfg.Routes.Models()
+		return errResp, http.StatusBadRequest
 	}
 
 	valid, err := validateAPIKey(cc)
 	if !valid {
-		return c.JSON(http.StatusUnauthorized, map[string]any{
-			"verified": false,
-			"error":    err.Error(),
-		})
+		metrics.VerifyErrors.WithLabelValues(metricModel(cc, request.Model), "unauthorized").Inc()
+		return verifyError(cc, shared.CodeUnauthorized, err.Error()), http.StatusUnauthorized
+	}
+	metrics.VerifyRequests.WithLabelValues(request.Model).Inc()
+
+	if errResp, code := checkMaintenance(cc, request.Model, async); errResp != nil {
+		return errResp, code
 	}
 
-	cc.Log.Infow("Verification request received",
-		"model", request.Model,
-		"request_type", request.RequestType,
-		"request_id", request.RequestID,
-	)
+	// Only authenticated callers may move their requests up the backend queue
+	deadline, err := parseDeadline(cc, cc.Request().Header.Get("X-Deadline"))
+	if err != nil {
+		metrics.VerifyErrors.WithLabelValues(request.Model, "invalid_request").Inc()
+		return verifyError(cc, shared.CodeInvalidRequest, err.Error()), http.StatusBadRequest
+	}
+	if !deadline.IsZero() && !deadline.After(time.Now()) {
+		metrics.VerifyErrors.WithLabelValues(request.Model, "deadline_exceeded").Inc()
+		return verifyError(cc, shared.CodeDeadlineExceeded, "X-Deadline has already passed"), http.StatusGatewayTimeout
+	}
+	cc.Deadline = deadline
+	claimExpectation(cc, request)
+
+	if cc.Cfg.Database.Degraded() {
+		cc.Response().Header().Set("X-Degraded-Mode", "database")
+		metrics.DegradedRequests.WithLabelValues(request.Model).Inc()
+	}
+
+	if reason := checkAccess(cc, request); reason != "" {
+		metrics.VerifyErrors.WithLabelValues(request.Model, "access_denied").Inc()
+		return verifyError(cc, shared.CodeForbidden, "Request denied by access policy: "+reason), http.StatusForbidden
+	}
+
+	if allowed, wait := cc.Cfg.Abuse.Throttle(cc.Hotkey); !allowed {
+		cc.Log.Warnw("Throttled flagged hotkey", "hotkey", cc.Hotkey)
+		metrics.VerifyErrors.WithLabelValues(request.Model, "throttled").Inc()
+		errResp := verifyError(cc, shared.CodeKeyThrottled, "API key is flagged for review and throttled")
+		retryAfter(cc, &errResp.ErrorResponse, wait)
+		return errResp, http.StatusTooManyRequests
+	}
+
+	if rps, burst := keyRateLimit(cc); rps > 0 {
+		if allowed, wait := cc.Cfg.Limiter.Allow(cc.Hotkey, rps, burst); !allowed {
+			cc.Log.Warnw("Rate limit exceeded", "hotkey", cc.Hotkey, "rps", rps)
+			metrics.VerifyErrors.WithLabelValues(request.Model, "rate_limited").Inc()
+			errResp := verifyError(cc, shared.CodeRateLimited, "Rate limit exceeded")
+			retryAfter(cc, &errResp.ErrorResponse, wait)
+			return errResp, http.StatusTooManyRequests
+		}
+	}
+
+	if errResp, code := checkEpochQuota(cc, request.Model); errResp != nil {
+		return errResp, code
+	}
+
+	if errResp, code := checkDailyQuota(cc, request.Model); errResp != nil {
+		return errResp, code
+	}
+
+	if err := validateTags(request.Tags); err != nil {
+		metrics.VerifyErrors.WithLabelValues(request.Model, "invalid_request").Inc()
+		return verifyError(cc, shared.CodeInvalidRequest, err.Error()), http.StatusBadRequest
+	}
+
+	rt := cc.Cfg.Runtime()
+	if reason := inspectPayload(rt.Quarantine, request); reason != "" {
+		metrics.VerifyErrors.WithLabelValues(request.Model, "quarantined").Inc()
+		id, err := quarantinePayload(cc, request, reason)
+		if err != nil {
+			cc.Log.Errorw("Failed to quarantine payload", "error", err.Error(), "reason", reason)
+		}
+		errResp := verifyError(cc, shared.CodePayloadQuarantined, "Payload rejected and held for review: "+reason)
+		errResp.QuarantineID = id
+		return errResp, http.StatusUnprocessableEntity
+	}
 
-	if request.RequestID != "" {
-		if cachedResponse, found := cc.Cfg.Cache.Get(request.RequestID); found {
+	if cc.Cfg.Env.ValidatePayloads {
+		if err := validation.Request(request, cc.Cfg.Env.ValidateMaxChunks); err != nil {
+			cc.Log.Warnw("Malformed payload", "model", request.Model, "error", err.Error())
+			metrics.VerifyErrors.WithLabelValues(request.Model, "invalid_payload").Inc()
+			return verifyError(cc, shared.CodeInvalidRequest, "Invalid payload: "+err.Error()), http.StatusBadRequest
+		}
+	}
+
+	recordSanitization(cc, request, sanitizeChunks(rt.Sanitize, request))
+
+	// Serving the cached verdict of another payload would hide a client reusing IDs
+	if requestIDConflict(cc, request) {
+		cc.Log.Warnw("Request ID reused with a different payload", "request_id", request.RequestID)
+		metrics.VerifyErrors.WithLabelValues(request.Model, "request_id_conflict").Inc()
+		return verifyError(cc, shared.CodeRequestIDConflict, "request_id was already used with a different payload"), http.StatusConflict
+	}
+
+	detectResubmission(cc, request)
+
+	return nil, 0
+}
+
+// verifyOptions holds per-request switches read from headers before verification runs
+type verifyOptions struct {
+	Consensus bool
+	SkipDedup bool
+}
+
+// requestOptions reads the verification switches from the incoming request
+func requestOptions(cc *shared.Context, req *shared.VerificationRequest) verifyOptions {
+	return verifyOptions{
+		Consensus: useConsensus(cc, req),
+		SkipDedup: strings.ToLower(cc.Request().Header.Get("X-Skip-Dedup")) == "true",
+	}
+}
+
+// runVerification resolves a verification from cache or the backend. It does not touch
+// the echo request, so it can also run outside of a handler.
+func runVerification(cc *shared.Context, request *shared.VerificationRequest, opts verifyOptions) ([]byte, error) {
+	startTime := time.Now()
+
+	keys := cacheKeys(cc, request)
+	if len(keys) > 0 {
+		if cachedResponse, key, found := lookupCache(cc, keys); found {
 			var response shared.VerificationResponse
 			if err := json.Unmarshal(cachedResponse, &response); err != nil {
 				cc.Log.Warnw("Failed to unmarshal cached response", "error", err.Error(), "request_id", request.RequestID)
 			} else {
+				// A content match may come from a different request_id
+				if key != resultCacheKey(request.RequestID) && request.RequestID != "" {
+					response.RequestID = request.RequestID
+				}
+				metrics.CacheLookups.WithLabelValues(request.Model, "hit").Inc()
+				metrics.Verdicts.WithLabelValues(request.Model, metrics.Verdict(response.Verified)).Inc()
 				cc.Log.Infow("Cache hit",
 					"request_id", request.RequestID,
+					"cache_key", key,
 					"duration_ms", time.Since(startTime).Milliseconds(),
 				)
 
@@ -68,36 +252,95 @@ func Verify(c echo.Context) error {
 					"error", response.Error,
 					"cause", response.Cause,
 				)
+				logVerification(cc, request, cachedResponse, SourceCache, startTime)
 
-				return c.JSON(http.StatusOK, response)
+				return json.Marshal(response)
 			}
 		}
+		metrics.CacheLookups.WithLabelValues(request.Model, "miss").Inc()
 	}
 
-	response, err := forwardToValis(cc, &request)
+	if len(keys) == 0 {
+		return resolveVerification(cc, request, opts, startTime)
+	}
+
+	// Concurrent requests with the same cache key share a single backend call
+	leader := false
+	result, err, _ := cc.Cfg.Inflight.Do(keys[0], func() (any, error) {
+		leader = true
+		return resolveVerification(cc, request, opts, startTime)
+	})
+	if !leader && errors.Is(err, context.Canceled) && cc.Ctx().Err() == nil {
+		// The leader's client went away; this one is still waiting
+		cc.Log.Infow("Coalesced verification was cancelled, verifying again", "request_id", request.RequestID)
+		return resolveVerification(cc, request, opts, startTime)
+	}
 	if err != nil {
-		cc.Log.Errorw("Verification failed", "error", err.Error(), "request_id", request.RequestID)
-		return c.JSON(http.StatusInternalServerError, map[string]any{
-			"verified": false,
-			"error":    "Verification service error: " + err.Error(),
-		})
+		return nil, err
 	}
 
-	if request.RequestID != "" && response != nil {
-		cc.Log.Infow("About to cache response",
-			"request_id", request.RequestID,
-			"response", string(response),
-		)
-		cc.Cfg.Cache.Set(request.RequestID, response, 72*time.Minute)
-		cc.Log.Infow("Cached response", "request_id", request.RequestID)
+	response := result.([]byte)
+	if !leader {
+		cc.Log.Infow("Coalesced with in-flight verification", "request_id", request.RequestID)
+		recordVerdict(request.Model, response)
+		logVerification(cc, request, response, SourceCoalesced, startTime)
 	}
 
-	cc.Log.Infow("Verification completed",
-		"request_id", request.RequestID,
-		"duration_ms", time.Since(startTime).Milliseconds(),
-	)
+	return response, nil
+}
+
+// resolveVerification produces a verdict for a request that missed the cache,
+// from a deduplicated result or the backend
+func resolveVerification(cc *shared.Context, request *shared.VerificationRequest, opts verifyOptions, startTime time.Time) ([]byte, error) {
+	if response, found := lookupDedup(cc, request, opts); found {
+		recordVerdict(request.Model, response)
+		logVerification(cc, request, response, SourceDedup, startTime)
+		storeCache(cc, request, response)
+		return response, nil
+	}
+
+	var response []byte
+	var err error
+	backendStart := time.Now()
+	if opts.Consensus {
+		response, err = forwardConsensus(cc, request)
+	} else {
+		response, err = forwardToValis(cc, request)
+	}
+	cc.Outcome.BackendLatency = time.Since(backendStart)
+	if err != nil {
+		return nil, err
+	}
+	if !cc.Canary {
+		mirrorToShadow(cc, request, response)
+	}
+
+	response = applyPolicy(cc, request, response)
+	response = classifyCause(cc, request, response)
+
+	// Canary probes are kept out of abuse detection, dedup, stats and audit logs
+	if !cc.Canary {
+		detectAnomalies(cc, request, response)
+		storeDedup(cc, request, response)
+		recordVerdict(request.Model, response)
+		logVerification(cc, request, response, SourceBackend, startTime)
+	}
+
+	if response != nil {
+		storeCache(cc, request, response)
+	}
+
+	return response, nil
+}
 
-	return c.JSONBlob(http.StatusOK, response)
+// recordVerdict counts the verdict contained in a verification response body
+func recordVerdict(model string, body []byte) {
+	var response shared.VerificationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		metrics.VerifyErrors.WithLabelValues(model, "invalid_backend_response").Inc()
+		return
+	}
+	metrics.Verdicts.WithLabelValues(model, metrics.Verdict(response.Verified)).Inc()
 }
 
 // validateRequiredFields checks if all required fields are present in the request
@@ -128,52 +371,238 @@ func validateRequiredFields(cc *shared.Context, request *shared.VerificationRequ
 // validateAPIKey checks if the request has a valid API key
 func validateAPIKey(cc *shared.Context) (bool, error) {
 	authHeader := cc.Request().Header.Get("Authorization")
-	if authHeader == "" {
-		cc.Log.Warn("Missing Authorization header")
-		return false, fmt.Errorf("authorization required")
+	var key config.KeyInfo
+
+	if authHeader == "" && cc.Request().Header.Get(hotkey.HeaderEpistulaSignedBy) != "" {
+		// Hotkey-signed request in place of a static key
+		signed, err := epistulaKey(cc)
+		if err != nil {
+			cc.Log.Warnw("Rejected signed request", "signed_by", cc.Request().Header.Get(hotkey.HeaderEpistulaSignedBy), "error", err.Error())
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), authBadSignature).Inc()
+			return false, err
+		}
+		key = signed
+	} else {
+		if authHeader == "" {
+			cc.Log.Warn("Missing Authorization header")
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), authMissingHeader).Inc()
+			return false, fmt.Errorf("authorization required")
+		}
+
+		// Parse Bearer token
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			cc.Log.Warnw("Invalid Authorization format", "header", authHeader)
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), authBadFormat).Inc()
+			return false, fmt.Errorf("invalid authorization format")
+		}
+
+		apiKey := parts[1]
+
+		found, err := cc.Cfg.Keys.Lookup(apiKey)
+		if err != nil {
+			cc.Log.Warnw("Invalid API key", "key", apiKey, "error", err.Error())
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), lookupFailure(err)).Inc()
+			return false, fmt.Errorf("invalid API key")
+		}
+		if err := checkKeyLength(cc, found, apiKey); err != nil {
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), authShortKey).Inc()
+			return false, err
+		}
+		key = found
 	}
 
-	// Parse Bearer token
-	parts := strings.Split(authHeader, " ")
-	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-		cc.Log.Warnw("Invalid Authorization format", "header", authHeader)
-		return false, fmt.Errorf("invalid authorization format")
+	if err := checkKeyState(key); err != nil {
+		cc.Log.Warnw("Unusable API key used", "hotkey", key.Hotkey, "error", err.Error())
+		metrics.AuthAttempts.WithLabelValues(cc.Path(), keyStateReason(key)).Inc()
+		return false, err
 	}
 
-	apiKey := parts[1]
+	if err := checkKeyAddress(cc, key); err != nil {
+		metrics.AuthAttempts.WithLabelValues(cc.Path(), authIPNotAllowed).Inc()
+		return false, err
+	}
 
-	var hotkey string
-	err := cc.Cfg.SqlClient.QueryRow(
-		"SELECT hotkey FROM api_keys WHERE key_value = ?",
-		apiKey,
-	).Scan(&hotkey)
-	if err != nil {
-		cc.Log.Warnw("Invalid API key", "key", apiKey, "error", err.Error())
-		return false, fmt.Errorf("invalid API key")
+	if !key.HasScope(config.ScopeVerify) {
+		cc.Log.Warnw("API key without the verify scope used", "hotkey", key.Hotkey)
+		metrics.AuthAttempts.WithLabelValues(cc.Path(), authMissingScope).Inc()
+		return false, fmt.Errorf("API key lacks the %s scope", config.ScopeVerify)
 	}
 
-	_, err = cc.Cfg.SqlClient.Exec(
-		"UPDATE api_keys SET last_used_at = ? WHERE hotkey = ?",
-		time.Now(), hotkey,
-	)
-	if err != nil {
-		cc.Log.Warnw("Failed to update last_used_at", "error", err.Error(), "hotkey", hotkey)
+	if target := cc.Request().Header.Get("X-On-Behalf-Of"); target != "" {
+		ok, err := impersonate(cc, key, target)
+		if ok {
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), authImpersonated).Inc()
+		} else {
+			metrics.AuthAttempts.WithLabelValues(cc.Path(), authImpersonationDenied).Inc()
+		}
+		return ok, err
 	}
 
+	metrics.AuthAttempts.WithLabelValues(cc.Path(), authSuccess).Inc()
+
+	cc.Cfg.Keys.Touch(key.Hotkey)
+
+	cc.Hotkey = key.Hotkey
+	cc.Tier = key.Tier
+	cc.Key = key
+
 	return true, nil
 }
 
-// forwardToValis sends the verification request to the Valis service
+// checkKeyLength refuses keys shorter than the configured minimum and flags
+// keys shorter than newly issued ones so holders know to rotate
+func checkKeyLength(cc *shared.Context, key config.KeyInfo, keyValue string) error {
+	if len(keyValue) < cc.Cfg.Env.IDs.MinKeyLength {
+		cc.Log.Warnw("API key below minimum length", "hotkey", key.Hotkey, "length", len(keyValue))
+		return fmt.Errorf("API key is too short, rotate it to continue")
+	}
+	if len(keyValue) < apikey.Length() {
+		cc.Response().Header().Set("X-Key-Rotation-Recommended", "true")
+	}
+	return nil
+}
+
+// checkKeyState rejects keys that are inactive, disabled or expired
+func checkKeyState(key config.KeyInfo) error {
+	switch keyStateReason(key) {
+	case authInactive:
+		return fmt.Errorf("API key not activated, sign the activation challenge first")
+	case authDisabled:
+		return fmt.Errorf("API key disabled")
+	case authExpired:
+		return fmt.Errorf("API key expired")
+	}
+	return nil
+}
+
+// checkKeyAddress rejects keys used from outside their allowed CIDRs
+func checkKeyAddress(cc *shared.Context, key config.KeyInfo) error {
+	if key.AllowsIP(net.ParseIP(cc.RealIP())) {
+		return nil
+	}
+	cc.Log.Warnw("API key used from a disallowed address", "hotkey", key.Hotkey, "ip", cc.RealIP())
+	return fmt.Errorf("API key is not allowed from this address")
+}
+
+// Authentication results, as reported in the auth attempt metrics
+const (
+	authSuccess             = "success"
+	authMissingHeader       = "missing_header"
+	authBadFormat           = "bad_format"
+	authUnknownKey          = "unknown_key"
+	authLookupError         = "lookup_error"
+	authInactive            = "inactive"
+	authDisabled            = "disabled"
+	authExpired             = "expired"
+	authBadSignature        = "bad_signature"
+	authNotAdmin            = "not_admin"
+	authImpersonated        = "impersonated"
+	authImpersonationDenied = "impersonation_denied"
+	authSession             = "session"
+	authShortKey            = "short_key"
+	authBadSession          = "bad_session"
+	authMissingScope        = "missing_scope"
+	authIPNotAllowed        = "ip_not_allowed"
+)
+
+// keyStateReason returns why a key cannot be used, or "" when it can
+func keyStateReason(key config.KeyInfo) string {
+	switch {
+	case !key.Active:
+		return authInactive
+	case key.Disabled:
+		return authDisabled
+	case key.Expired(time.Now()):
+		return authExpired
+	default:
+		return ""
+	}
+}
+
+// lookupFailure classifies a failed key lookup for the auth metrics
+func lookupFailure(err error) string {
+	if errors.Is(err, sql.ErrNoRows) {
+		return authUnknownKey
+	}
+	return authLookupError
+}
+
+// forwardToValis sends the verification request to the Valis service registered
+// for the model, falling back to haproxy when no routes are registered
 func forwardToValis(cc *shared.Context, req *shared.VerificationRequest) ([]byte, error) {
-	client := &http.Client{
-		Timeout: 120 * time.Second,
+	if route, ok := cc.Cfg.Routes.Lookup(req.Model); ok && len(route.Backends()) > 0 {
+		backend := cc.Cfg.Balancer.Pick(req.Model, route.Backends())
+		release := cc.Cfg.Balancer.Start(backend)
+		response, err := forwardToBackend(cc, req, backend+route.Path, route.BackendServer)
+		release()
+		// Shed or cancelled calls say nothing about the backend's health
+		if err == nil || errors.As(err, &backendFailure{}) {
+			cc.Cfg.Balancer.Report(backend, err)
+		}
+		reportBackendError(cc, req, backend, err)
+		return response, err
+	}
+	response, err := forwardToBackend(cc, req, cc.Cfg.Env.HaproxyURL+"/verify", req.Model)
+	reportBackendError(cc, req, cc.Cfg.Env.HaproxyURL, err)
+	return response, err
+}
+
+// forwardToBackend runs the call to the verifier at backendURL on a worker of
+// the model's backend pool
+func forwardToBackend(cc *shared.Context, req *shared.VerificationRequest, backendURL, backendServer string) ([]byte, error) {
+	call := config.BackendCall{Model: req.Model, Deadline: cc.Deadline, Reserved: cc.Expected, Priority: callPriority(cc)}
+	response, err := cc.Cfg.Workers.Do(cc.Ctx(), call, func() ([]byte, error) {
+		return callBackend(cc, req, backendURL, backendServer)
+	})
+	if errors.Is(err, config.ErrBackendSaturated) || errors.Is(err, config.ErrDeadlinePassed) {
+		cc.Log.Warnw("Shedding backend call", "model", req.Model, "error", err.Error())
+	}
+	return response, err
+}
+
+// callPriority returns the scheduling priority of the requesting key, by its
+// tier or its hotkey's stake
+func callPriority(cc *shared.Context) int {
+	settings := cc.Cfg.Env.Priority
+	if settings.Source != config.PriorityStake {
+		return settings.Level(cc.Tier, 0)
+	}
+	stake, _ := cc.Cfg.Metagraph.Stake(cc.Hotkey)
+	return settings.Level(cc.Tier, stake)
+}
+
+// callBackend sends the verification request to the verifier at backendURL,
+// retrying network errors and 5xx responses with jittered exponential backoff
+func callBackend(cc *shared.Context, req *shared.VerificationRequest, backendURL, backendServer string) ([]byte, error) {
+	if cc.Cfg.Env.Mock.Enabled {
+		return mockVerify(cc, req)
+	}
+
+	// There is no point waiting on the backend past the client's deadline
+	timeout := cc.Cfg.Timeouts.Timeout(req.Model, cc.Cfg.Env.BackendTimeout(req.Model))
+	if !cc.Deadline.IsZero() {
+		remaining := time.Until(cc.Deadline)
+		if remaining <= 0 {
+			return nil, config.ErrDeadlinePassed
+		}
+		timeout = min(timeout, remaining)
 	}
 
-	requestBody, err := json.Marshal(req)
+	// Tags are proxy-side metadata and are not sent to the verifier
+	backendReq := *req
+	backendReq.Tags = nil
+	requestBody, err := json.Marshal(backendReq)
 	if err != nil {
 		cc.Log.Errorw("Failed to marshal request", "error", err.Error())
 		return nil, fmt.Errorf("failed to prepare request: %w", err)
 	}
+	if cc.Cfg.Env.BackendGzip {
+		if requestBody, err = gzipBody(requestBody); err != nil {
+			cc.Log.Errorw("Failed to compress request", "error", err.Error())
+			return nil, fmt.Errorf("failed to prepare request: %w", err)
+		}
+	}
 
 	if cc.Cfg.Env.Debug {
 		cc.Log.Debugw("Forwarding verification request",
@@ -184,28 +613,156 @@ func forwardToValis(cc *shared.Context, req *shared.VerificationRequest) ([]byte
 		)
 	}
 
-	backendURL := fmt.Sprintf("%s/verify", cc.Cfg.Env.HaproxyURL)
-	httpReq, err := http.NewRequest(http.MethodPost, backendURL, bytes.NewReader(requestBody))
+	ctx, span := tracing.Tracer.Start(cc.Ctx(), "backend.verify", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("backend.url", backendURL),
+		attribute.String("backend.server", backendServer),
+		attribute.String("verify.model", req.Model),
+		attribute.String("verify.request_id", req.RequestID),
+	))
+	defer span.End()
+
+	breakerKey := backendURL + "|" + req.Model
+
+	// A client that goes away cancels the call, unless abandoned calls are
+	// completed so their verdict is cached for the client's retry
+	parent := ctx
+	if cc.Cfg.Env.CompleteAbandoned {
+		parent = context.WithoutCancel(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !cc.Cfg.Breaker.Allow(breakerKey) {
+			cc.Log.Warnw("Circuit open for backend", "url", backendURL, "model", req.Model)
+			span.SetStatus(codes.Error, "circuit open")
+			return nil, backendFailure{fmt.Errorf("backend circuit open for model %s", req.Model)}
+		}
+
+		span.SetAttributes(attribute.Int("backend.attempts", attempt+1))
+		attemptCtx, cancel := context.WithTimeout(parent, timeout)
+		body, retryable, err := sendToBackend(attemptCtx, cc, req, backendURL, backendServer, requestBody)
+		cancel()
+		if err == nil {
+			cc.Cfg.Breaker.Success(breakerKey)
+			return body, nil
+		}
+		span.RecordError(err)
+
+		if !retryable {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		cc.Cfg.Breaker.Failure(breakerKey)
+		if attempt >= cc.Cfg.Env.BackendRetries {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+
+		wait := backoff(attempt, cc.Cfg.Env.BackendRetryBase, cc.Cfg.Env.BackendRetryMax)
+		cc.Log.Warnw("Retrying backend request",
+			"error", err.Error(),
+			"attempt", attempt+1,
+			"wait_ms", wait.Milliseconds(),
+			"request_id", req.RequestID,
+		)
+		select {
+		case <-parent.Done():
+			span.SetStatus(codes.Error, parent.Err().Error())
+			return nil, parent.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// sendToBackend makes a single backend request, reporting whether a failure is worth retrying
+func sendToBackend(ctx context.Context, cc *shared.Context, req *shared.VerificationRequest, backendURL, backendServer string, requestBody []byte) ([]byte, bool, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, backendURL, bytes.NewReader(requestBody))
 	if err != nil {
 		cc.Log.Errorw("Failed to create request", "error", err.Error())
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("x-backend-server", req.Model)
+	httpReq.Header.Set("x-backend-server", backendServer)
 	httpReq.Header.Set("Content-Type", "application/json")
-
-	httpResp, err := client.Do(httpReq)
+	httpReq.Header.Set(headerSchema, strconv.Itoa(schemaV2))
+	if cc.Cfg.Env.BackendGzip {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	SetRequestIDHeaders(cc, httpReq.Header)
+	tracing.Inject(ctx, httpReq.Header)
+
+	backendStart := time.Now()
+	httpResp, err := cc.Cfg.Client.Do(httpReq)
+	if errors.Is(err, context.Canceled) {
+		// The client went away; this says nothing about the backend
+		metrics.BackendLatency.WithLabelValues(req.Model, "cancelled").Observe(time.Since(backendStart).Seconds())
+		return nil, false, err
+	}
 	if err != nil {
+		metrics.BackendLatency.WithLabelValues(req.Model, "error").Observe(time.Since(backendStart).Seconds())
 		cc.Log.Errorw("Failed to send request to backend", "error", err.Error(), "url", backendURL)
-		return nil, fmt.Errorf("failed to send request to backend: %w", err)
+		return nil, true, backendFailure{fmt.Errorf("failed to send request to backend: %w", err)}
 	}
 	defer httpResp.Body.Close()
 
 	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
 		cc.Log.Errorw("Failed to read response body", "error", err.Error())
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, true, backendFailure{fmt.Errorf("failed to read response body: %w", err)}
+	}
+	metrics.BackendLatency.WithLabelValues(req.Model, strconv.Itoa(httpResp.StatusCode)).Observe(time.Since(backendStart).Seconds())
+	if httpResp.StatusCode < http.StatusInternalServerError {
+		cc.Cfg.Timeouts.Observe(req.Model, time.Since(backendStart))
+	}
+
+	if cc.Capture && cc.Cfg.Env.Debug {
+		cc.Log.Infow("Captured backend exchange",
+			"url", backendURL,
+			"status", httpResp.StatusCode,
+			"request_body", redactBody(cc.Cfg.Env, requestBody),
+			"response_body", redactBody(cc.Cfg.Env, body),
+		)
+	}
+
+	version := cc.Cfg.Versions.Observe(backendURL, httpResp.Header.Get("X-Verifier-Version"))
+	if version.Status == config.VersionIncompatible {
+		cc.Log.Errorw("Backend version below minimum supported version",
+			"url", backendURL,
+			"version", version.Version,
+			"min_version", cc.Cfg.Versions.MinVersion,
+		)
+		if cc.Cfg.Versions.Enforce {
+			return nil, false, backendFailure{fmt.Errorf("backend version %s is below minimum %s", version.Version, cc.Cfg.Versions.MinVersion)}
+		}
+	}
+
+	if httpResp.StatusCode >= http.StatusInternalServerError {
+		cc.Log.Errorw("Backend returned server error", "status", httpResp.StatusCode, "url", backendURL)
+		return nil, true, backendFailure{fmt.Errorf("backend returned status %d", httpResp.StatusCode)}
+	}
+
+	// Everything past this point reads the normalized verdict, never the raw body
+	response, err := parseBackendResponse(body, httpResp.Header.Get(headerSchema))
+	if err != nil {
+		cc.Log.Errorw("Backend returned invalid response", "error", err.Error(), "url", backendURL)
+		return nil, false, err
+	}
+	normalized, err := json.Marshal(response)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode backend response: %w", err)
 	}
 
-	return body, nil
+	return normalized, false, nil
+}
+
+// backoff returns the full-jitter delay before the given retry attempt
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	ceiling := base << attempt
+	if ceiling <= 0 || ceiling > max {
+		ceiling = max
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
 }
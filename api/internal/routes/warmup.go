@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"encoding/json"
+	"time"
+
+	"api/internal/config"
+	"api/internal/shared"
+
+	"go.uber.org/zap"
+)
+
+// Warmup primes caches and backend connections before the instance reports
+// ready, so the first requests after a deploy do not pay for a cold start
+func Warmup(cfg *config.Config, log *zap.SugaredLogger) {
+	startTime := time.Now()
+	log = log.With("phase", "warmup")
+
+	if primed, err := cfg.Keys.Prime(cfg.Env.WarmupKeys); err != nil {
+		log.Warnw("Failed to prime key cache", "error", err.Error())
+	} else {
+		log.Infow("Primed key cache", "keys", primed)
+	}
+
+	if err := cfg.Routes.Reload(cfg.SqlClient); err != nil {
+		log.Warnw("Failed to load model routes", "error", err.Error())
+	}
+
+	if !cfg.Env.Mock.Enabled {
+		for _, backend := range backendURLs(cfg) {
+			if err := prewarmBackend(cfg, backend); err != nil {
+				log.Warnw("Backend not reachable during warm-up", "url", backend, "error", err.Error())
+			}
+		}
+	}
+
+	if cfg.Env.WarmupRequest != "" {
+		warmupVerification(cfg, log)
+	}
+
+	cfg.Warm.Store(true)
+	log.Infow("Warm-up complete", "duration_ms", time.Since(startTime).Milliseconds())
+}
+
+// warmupVerification sends the configured synthetic request straight to the
+// backend, bypassing the cache, audit tables and metrics
+func warmupVerification(cfg *config.Config, log *zap.SugaredLogger) {
+	var request shared.VerificationRequest
+	if err := json.Unmarshal([]byte(cfg.Env.WarmupRequest), &request); err != nil {
+		log.Warnw("Invalid warm-up request", "error", err.Error())
+		return
+	}
+
+	cc := &shared.Context{Log: log, Reqid: "warmup", Cfg: cfg}
+	startTime := time.Now()
+	if _, err := forwardToValis(cc, &request); err != nil {
+		log.Warnw("Warm-up verification failed", "error", err.Error(), "model", request.Model)
+		return
+	}
+	log.Infow("Warm-up verification succeeded", "model", request.Model, "duration_ms", time.Since(startTime).Milliseconds())
+}
@@ -0,0 +1,71 @@
+package shared
+
+import "time"
+
+// ErrorCode is a machine-readable error code, so clients can decide whether to
+// retry without matching on the human-readable message
+type ErrorCode string
+
+const (
+	CodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	CodeMissingField       ErrorCode = "MISSING_FIELD"
+	CodeUnsupportedModel   ErrorCode = "UNSUPPORTED_MODEL"
+	CodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	CodeForbidden          ErrorCode = "FORBIDDEN"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodeRequestIDConflict  ErrorCode = "REQUEST_ID_CONFLICT"
+	CodePayloadTooLarge    ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeQuotaExceeded      ErrorCode = "QUOTA_EXCEEDED"
+	CodeKeyThrottled       ErrorCode = "KEY_THROTTLED"
+	CodePayloadQuarantined ErrorCode = "PAYLOAD_QUARANTINED"
+	CodeQueueFull          ErrorCode = "QUEUE_FULL"
+	CodeModelMaintenance   ErrorCode = "MODEL_MAINTENANCE"
+	CodeBackendTimeout     ErrorCode = "BACKEND_TIMEOUT"
+	CodeDeadlineExceeded   ErrorCode = "DEADLINE_EXCEEDED"
+	CodeBackendUnavailable ErrorCode = "BACKEND_UNAVAILABLE"
+	CodeInvalidBackendResp ErrorCode = "INVALID_BACKEND_RESPONSE"
+	CodeInternal           ErrorCode = "INTERNAL_ERROR"
+)
+
+// ErrorResponse is the body of every failed request. The message keeps the
+// "error" key earlier clients read.
+type ErrorResponse struct {
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"error"`
+	HelpURL   string    `json:"help_url,omitempty"`
+	RequestID string    `json:"proxy_request_id,omitempty"`
+	RetryInMs int64     `json:"retry_in_ms,omitempty"`
+}
+
+// VerifyErrorResponse is the error body of the verification endpoints, which
+// always carry a verdict
+type VerifyErrorResponse struct {
+	Verified bool `json:"verified"`
+	ErrorResponse
+	SupportedModels []string `json:"supported_models,omitempty"`
+	QuarantineID    int64    `json:"quarantine_id,omitempty"`
+	*EpochQuotaError
+	*DailyQuotaError
+	*MaintenanceError
+}
+
+// EpochQuotaError describes the epoch quota a request ran into
+type EpochQuotaError struct {
+	Epoch          int64 `json:"epoch"`
+	Quota          int   `json:"quota"`
+	ResetsInBlocks int64 `json:"resets_in_blocks"`
+}
+
+// MaintenanceError describes the scheduled maintenance a model is in
+type MaintenanceError struct {
+	MaintenanceUntil time.Time `json:"maintenance_until"`
+}
+
+// DailyQuotaError describes the daily quota a request ran into
+type DailyQuotaError struct {
+	DailyQuota int       `json:"daily_quota"`
+	Used       int64     `json:"used"`
+	ResetsAt   time.Time `json:"resets_at"`
+}
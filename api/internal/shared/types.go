@@ -2,6 +2,9 @@ package shared
 
 import (
 	"api/internal/config"
+	"api/internal/metagraph"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -13,9 +16,48 @@ import (
 // Context extends echo.Context with application-specific fields
 type Context struct {
 	echo.Context
-	Log   *zap.SugaredLogger
-	Reqid string
-	Cfg   *config.Config
+	Log    *zap.SugaredLogger
+	Reqid  string
+	Cfg    *config.Config
+	Hotkey string
+	Tier   string
+	Key    config.KeyInfo
+	// ClientReqid is the caller's X-Request-ID, echoed back and forwarded
+	ClientReqid string
+	// Actor is the admin hotkey when a request is made on behalf of Hotkey
+	Actor string
+	// RawBody is the request body as received, kept for signed requests
+	RawBody []byte
+	// Canary marks synthetic probe verifications
+	Canary bool
+	// Capture marks requests whose bodies are logged for debugging
+	Capture bool
+	// Scope is the scope RequireScope authorized the request with
+	Scope string
+	// Deadline is when the client's result stops being useful, from X-Deadline
+	Deadline time.Time
+	// Expected marks requests announced through /verify/expect, which may use
+	// the wait queue places held for them
+	Expected bool
+	// Outcome is what a verification request resolved to, for the access log
+	Outcome Outcome
+}
+
+// Outcome summarises how a verification request was answered
+type Outcome struct {
+	Model          string
+	Verified       *bool
+	Source         string
+	BackendLatency time.Duration
+}
+
+// Ctx returns the request's context, or a background context for work that
+// runs outside of a handler
+func (c *Context) Ctx() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Request().Context()
 }
 
 // RequestError represents a standard API error response
@@ -59,21 +101,152 @@ type Request struct {
 
 // ApiKey represents an API key in the system
 type ApiKey struct {
-	Hotkey    string    `json:"hotkey"`
-	KeyValue  string    `json:"key_value"`
-	CreatedAt time.Time `json:"created_at"`
-	LastUsed  time.Time `json:"last_used,omitempty"`
-	IsAdmin   bool      `json:"is_admin"`
+	Hotkey    string     `json:"hotkey"`
+	KeyValue  string     `json:"key_value"`
+	CreatedAt time.Time  `json:"created_at"`
+	LastUsed  time.Time  `json:"last_used,omitempty"`
+	IsAdmin   bool       `json:"is_admin"`
+	Scopes    []string   `json:"scopes"`
+	Tier      string     `json:"tier"`
+	Active    bool       `json:"active"`
+	Challenge string     `json:"challenge,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// KeySummary describes an API key without exposing its value
+type KeySummary struct {
+	Hotkey          string     `json:"hotkey"`
+	KeyMasked       string     `json:"key_masked"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsed        *time.Time `json:"last_used,omitempty"`
+	IsAdmin         bool       `json:"is_admin"`
+	Scopes          []string   `json:"scopes"`
+	Tier            string     `json:"tier"`
+	Active          bool       `json:"active"`
+	Disabled        bool       `json:"disabled"`
+	AutoProvisioned bool       `json:"auto_provisioned"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	PruneFlaggedAt  *time.Time `json:"prune_flagged_at,omitempty"`
+	PruneExempt     bool       `json:"prune_exempt"`
+	AllowedCIDRs    []string   `json:"allowed_cidrs,omitempty"`
+}
+
+// KeyListResponse is a page of API keys
+type KeyListResponse struct {
+	Keys   []KeySummary `json:"keys"`
+	Total  int          `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
 }
 
 // AddKeyRequest is used to request a new API key
 type AddKeyRequest struct {
-	Hotkey string `json:"hotkey" validate:"required"`
+	Hotkey    string     `json:"hotkey" param:"hotkey" validate:"required"`
+	Tier      string     `json:"tier,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       string     `json:"ttl,omitempty"`
+}
+
+// SetPruneExemptRequest exempts a key from the unused key policy, or returns it to it
+type SetPruneExemptRequest struct {
+	Hotkey string `json:"hotkey" param:"hotkey" validate:"required"`
+	Exempt bool   `json:"exempt"`
+}
+
+// SetAllowedCIDRsRequest replaces the client networks a key may be used from;
+// an empty list allows any address
+type SetAllowedCIDRsRequest struct {
+	Hotkey       string   `json:"hotkey" param:"hotkey" validate:"required"`
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+}
+
+// MetagraphStatusResponse reports the metagraph sync settings and the outcome
+// of the latest sync
+type MetagraphStatusResponse struct {
+	metagraph.Status
+	Netuid        int     `json:"netuid"`
+	MinStake      float64 `json:"min_stake"`
+	RequirePermit bool    `json:"require_permit"`
+	Interval      string  `json:"interval"`
+}
+
+// SetScopesRequest grants or revokes scopes of an API key
+type SetScopesRequest struct {
+	Hotkey string   `json:"hotkey" param:"hotkey" validate:"required"`
+	Scopes []string `json:"scopes" validate:"required"`
+}
+
+// KeyScopesResponse lists the scopes an API key holds
+type KeyScopesResponse struct {
+	Hotkey string   `json:"hotkey"`
+	Scopes []string `json:"scopes"`
+}
+
+// SetKeyStateRequest disables or re-enables an API key
+type SetKeyStateRequest struct {
+	Hotkey    string     `json:"hotkey" param:"hotkey" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	TTL       string     `json:"ttl,omitempty"`
+}
+
+// BulkRequest holds the dry-run and confirmation fields common to bulk admin
+// operations. A bulk operation only runs when given the confirmation token
+// returned by a dry run over the same selection.
+type BulkRequest struct {
+	DryRun            bool   `json:"dry_run"`
+	ConfirmationToken string `json:"confirmation_token,omitempty"`
+}
+
+// BulkDisableKeysRequest selects API keys to disable. At least one selector is
+// required; admin keys are never selected.
+type BulkDisableKeysRequest struct {
+	BulkRequest
+	Hotkeys         []string `json:"hotkeys,omitempty"`
+	Tier            string   `json:"tier,omitempty"`
+	AutoProvisioned *bool    `json:"auto_provisioned,omitempty"`
+}
+
+// BulkInvalidateCacheRequest selects cached verification results to drop
+type BulkInvalidateCacheRequest struct {
+	BulkRequest
+	RequestIDs []string `json:"request_ids"`
+}
+
+// BulkPurgeRequest selects stored rows to delete
+type BulkPurgeRequest struct {
+	BulkRequest
+	Table  string    `json:"table"`
+	Before time.Time `json:"before"`
+	Model  string    `json:"model,omitempty"`
+}
+
+// RetentionCleanupRequest runs the verification log retention now, optionally
+// with a shorter or longer age than configured
+type RetentionCleanupRequest struct {
+	OlderThan string `json:"older_than,omitempty"`
+}
+
+// RetentionCleanupResponse reports the rows a retention cleanup deleted
+type RetentionCleanupResponse struct {
+	Cutoff  time.Time        `json:"cutoff"`
+	Deleted map[string]int64 `json:"deleted"`
+}
+
+// BulkResult describes what a bulk operation affects or affected
+type BulkResult struct {
+	Operation         string     `json:"operation"`
+	DryRun            bool       `json:"dry_run"`
+	Count             int64      `json:"count"`
+	Affected          []string   `json:"affected,omitempty"`
+	ConfirmationToken string     `json:"confirmation_token,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	Message           string     `json:"message"`
 }
 
 // RemoveKeyRequest is used to request removal of an API key
 type RemoveKeyRequest struct {
-	Hotkey string `json:"hotkey" validate:"required"`
+	Hotkey string `json:"hotkey" param:"hotkey" validate:"required"`
 }
 
 // VerificationRequest is used for verification requests
@@ -83,20 +256,387 @@ type VerificationRequest struct {
 	RequestParams map[string]interface{}   `json:"request_params"`
 	RawChunks     []map[string]interface{} `json:"raw_chunks"`
 	RequestID     string                   `json:"request_id,omitempty"`
+	Tags          []string                 `json:"tags,omitempty"`
 }
 
 // VerificationResponse represents a response from the verification service
 type VerificationResponse struct {
-	RequestID      string      `json:"request_id,omitempty"`
-	Verified       bool        `json:"verified"`
-	Error          string      `json:"error,omitempty"`
-	Cause          string      `json:"cause,omitempty"`
-	InputTokens    interface{} `json:"input_tokens,omitempty"`
-	ResponseTokens interface{} `json:"response_tokens,omitempty"`
-	GPUs           int         `json:"gpus,omitempty"`
+	SchemaVersion  int        `json:"schema_version,omitempty"`
+	RequestID      string     `json:"request_id,omitempty"`
+	Verified       bool       `json:"verified"`
+	Error          string     `json:"error,omitempty"`
+	Cause          string     `json:"cause,omitempty"`
+	CauseCode      string     `json:"cause_code,omitempty"`
+	InputTokens    *int64     `json:"input_tokens,omitempty"`
+	ResponseTokens *int64     `json:"response_tokens,omitempty"`
+	GPUs           int        `json:"gpus,omitempty"`
+	Score          *float64   `json:"score,omitempty"`
+	PolicyApplied  bool       `json:"policy_applied,omitempty"`
+	Consensus      *Consensus `json:"consensus,omitempty"`
+	Deduplicated   bool       `json:"deduplicated,omitempty"`
+	DedupOf        string     `json:"dedup_of,omitempty"`
+	Override       *Override  `json:"override,omitempty"`
+
+	// Returned by v2 backends only
+	ChunkScores      []float64 `json:"chunk_scores,omitempty"`
+	LogprobDistances []float64 `json:"logprob_distances,omitempty"`
+	VerificationMs   *int64    `json:"verification_ms,omitempty"`
+}
+
+// Override records that an administrator replaced the verdict of a verification
+type Override struct {
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// OverrideVerdictRequest replaces the verdict of a past verification
+type OverrideVerdictRequest struct {
+	RequestID string `json:"request_id" validate:"required"`
+	Hotkey    string `json:"hotkey" validate:"required"`
+	Verified  *bool  `json:"verified" validate:"required"`
+	Reason    string `json:"reason" validate:"required"`
+}
+
+// Consensus describes how a verdict was reached across multiple verifier backends
+type Consensus struct {
+	Mode     string           `json:"mode"`
+	Verdicts []BackendVerdict `json:"verdicts"`
+}
+
+// BackendVerdict is a single verifier backend's verdict in consensus mode
+type BackendVerdict struct {
+	Backend  string `json:"backend"`
+	Verified bool   `json:"verified"`
+	Cause    string `json:"cause,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ActivateKeyRequest proves control of a hotkey by signing its activation challenge
+type ActivateKeyRequest struct {
+	Hotkey    string `json:"hotkey" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// SetRouteRequest is used to register or update a model route
+type SetRouteRequest struct {
+	Model         string             `json:"model" validate:"required"`
+	BackendURL    string             `json:"backend_url" validate:"required"`
+	Path          string             `json:"path,omitempty"`
+	BackendServer string             `json:"backend_server,omitempty"`
+	CauseRules    []config.CauseRule `json:"cause_rules,omitempty"`
+}
+
+// AddMaintenanceRequest schedules a maintenance window for a model. Start
+// defaults to now and mode to reject.
+type AddMaintenanceRequest struct {
+	Model   string     `json:"model" param:"model" validate:"required"`
+	Start   *time.Time `json:"start,omitempty"`
+	End     time.Time  `json:"end" validate:"required"`
+	Mode    string     `json:"mode,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+// AddPayloadRequest adds a reference case to the synthetic payload library,
+// either from an explicit request or from a retained async job's payload
+type AddPayloadRequest struct {
+	Name           string          `json:"name" validate:"required"`
+	Request        json.RawMessage `json:"request,omitempty"`
+	JobID          string          `json:"job_id,omitempty"`
+	ExpectVerified *bool           `json:"expect_verified,omitempty"`
+}
+
+// CaptureRequest registers or removes a debug body capture for a route or request ID
+type CaptureRequest struct {
+	Route     string `json:"route,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	TTL       string `json:"ttl,omitempty"`
+}
+
+// SetDailyQuotaRequest sets or clears a key's daily quota override
+type SetDailyQuotaRequest struct {
+	Hotkey string `json:"hotkey" param:"hotkey" validate:"required"`
+	Quota  *int   `json:"quota"`
+}
+
+// UsageRecord is a hotkey's usage of one model on one UTC day
+type UsageRecord struct {
+	Hotkey         string `json:"hotkey"`
+	Day            string `json:"day"`
+	Model          string `json:"model"`
+	Verifications  int64  `json:"verifications"`
+	Verified       int64  `json:"verified"`
+	InputTokens    int64  `json:"input_tokens"`
+	ResponseTokens int64  `json:"response_tokens"`
+}
+
+// UsageResponse is a page of usage records
+type UsageResponse struct {
+	Usage  []UsageRecord `json:"usage"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// SelfUsageResponse is a key holder's own usage and quota
+type SelfUsageResponse struct {
+	Hotkey     string        `json:"hotkey"`
+	DailyQuota int           `json:"daily_quota,omitempty"`
+	UsedToday  int64         `json:"used_today"`
+	Usage      []UsageRecord `json:"usage"`
+}
+
+// HeatmapDay is one UTC day of a key's usage heatmap, with a count per hour
+type HeatmapDay struct {
+	Day   string    `json:"day"`
+	Hours [24]int64 `json:"hours"`
+	Total int64     `json:"total"`
+}
+
+// HeatmapResponse is a key's verification counts per UTC hour over recent days
+type HeatmapResponse struct {
+	Hotkey    string       `json:"hotkey"`
+	Days      []HeatmapDay `json:"days"`
+	HourTotal [24]int64    `json:"hour_of_day_totals"`
+	Total     int64        `json:"total"`
+	LastUsed  *time.Time   `json:"last_used,omitempty"`
+}
+
+// SetRateLimitRequest sets or clears a key's rate limit override
+type SetRateLimitRequest struct {
+	Hotkey string   `json:"hotkey" param:"hotkey" validate:"required"`
+	RPS    *float64 `json:"rps"`
+	Burst  *int     `json:"burst"`
+}
+
+// RotateKeyRequest is used to replace a hotkey's API key value
+type RotateKeyRequest struct {
+	Hotkey      string `json:"hotkey" param:"hotkey" validate:"required"`
+	GracePeriod string `json:"grace_period,omitempty"`
+}
+
+// RotateKeyResponse returns the new key and when the previous one stops working
+type RotateKeyResponse struct {
+	Hotkey               string     `json:"hotkey"`
+	KeyValue             string     `json:"key_value"`
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
+}
+
+// VerificationLog is a persisted verification outcome
+type VerificationLog struct {
+	Id             int64     `json:"id"`
+	RequestID      string    `json:"request_id"`
+	Hotkey         string    `json:"hotkey"`
+	Model          string    `json:"model"`
+	Verified       bool      `json:"verified"`
+	Cause          string    `json:"cause,omitempty"`
+	CauseCode      string    `json:"cause_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	InputTokens    *int64    `json:"input_tokens,omitempty"`
+	ResponseTokens *int64    `json:"response_tokens,omitempty"`
+	GPUs           int       `json:"gpus"`
+	LatencyMs      int64     `json:"latency_ms"`
+	Source         string    `json:"source"`
+	Tags           []string  `json:"tags,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// QuarantinedPayload is a verification payload held back for review
+type QuarantinedPayload struct {
+	Id         int64           `json:"id"`
+	Hotkey     string          `json:"hotkey"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Model      string          `json:"model"`
+	Reason     string          `json:"reason"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	SourceIP   string          `json:"source_ip,omitempty"`
+	ReviewedAt *time.Time      `json:"reviewed_at,omitempty"`
+	ReviewedBy string          `json:"reviewed_by,omitempty"`
+	ReviewNote string          `json:"review_note,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// QuarantineListResponse is a page of quarantined payloads
+type QuarantineListResponse struct {
+	Payloads []QuarantinedPayload `json:"payloads"`
+	Total    int                  `json:"total"`
+	Limit    int                  `json:"limit"`
+	Offset   int                  `json:"offset"`
+}
+
+// ReviewQuarantineRequest closes the review of a quarantined payload
+type ReviewQuarantineRequest struct {
+	Note string `json:"note"`
+}
+
+// VerificationLogResponse is a page of verification outcomes
+type VerificationLogResponse struct {
+	Verifications []VerificationLog `json:"verifications"`
+	Total         int               `json:"total"`
+	Limit         int               `json:"limit"`
+	Offset        int               `json:"offset"`
+}
+
+// AdminAuditEntry records one admin operation
+type AdminAuditEntry struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor_hotkey"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target,omitempty"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+	RequestID string          `json:"proxy_request_id,omitempty"`
+	SourceIP  string          `json:"source_ip,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AdminAuditResponse is a page of admin audit entries
+type AdminAuditResponse struct {
+	Entries []AdminAuditEntry `json:"entries"`
+	Total   int               `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+}
+
+// TagStats summarises verification outcomes for a single tag
+type TagStats struct {
+	Tag        string `json:"tag"`
+	Total      int    `json:"total"`
+	Verified   int    `json:"verified"`
+	Unverified int    `json:"unverified"`
 }
 
 // GetKeyRequest is used to request an API key by hotkey
 type GetKeyRequest struct {
-	Hotkey string `json:"hotkey" validate:"required"`
+	Hotkey string `json:"hotkey" param:"hotkey" validate:"required"`
+}
+
+// KeyFlag is a suspicious-pattern flag raised against a hotkey
+type KeyFlag struct {
+	Id         int64      `json:"id"`
+	Hotkey     string     `json:"hotkey"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ResolveFlagRequest is used to clear a flag after review
+type ResolveFlagRequest struct {
+	Id int64 `json:"id" validate:"required"`
+}
+
+// Job statuses for asynchronous verifications
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// ExpectRequest announces verifications a validator is about to send
+type ExpectRequest struct {
+	RequestIDs []string `json:"request_ids"`
+	Model      string   `json:"model,omitempty"`
+	// Deadline takes the same forms as the X-Deadline header
+	Deadline string `json:"deadline,omitempty"`
+}
+
+// ExpectResponse confirms announced verifications
+type ExpectResponse struct {
+	Expected  int       `json:"expected"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AsyncJobResponse is returned when an asynchronous verification is accepted
+type AsyncJobResponse struct {
+	JobID     string `json:"job_id"`
+	Status    string `json:"status"`
+	StatusURL string `json:"status_url"`
+}
+
+// JobStatusResponse reports the state of an asynchronous verification
+type JobStatusResponse struct {
+	JobID       string          `json:"job_id"`
+	Status      string          `json:"status"`
+	RequestID   string          `json:"request_id,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Retention   string          `json:"retention"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// DebugStatsResponse is a snapshot of the proxy's runtime state
+type DebugStatsResponse struct {
+	Goroutines int           `json:"goroutines"`
+	Heap       HeapStats     `json:"heap"`
+	Cache      CacheStats    `json:"cache"`
+	Database   DatabaseStats `json:"database"`
+	Backend    BackendStats  `json:"backend"`
+	Jobs       JobQueueStats `json:"jobs"`
+	Uptime     string        `json:"uptime"`
+}
+
+// HeapStats summarizes the Go heap
+type HeapStats struct {
+	AllocBytes   uint64     `json:"alloc_bytes"`
+	InUseBytes   uint64     `json:"in_use_bytes"`
+	SysBytes     uint64     `json:"sys_bytes"`
+	Objects      uint64     `json:"objects"`
+	NumGC        uint32     `json:"num_gc"`
+	LastGC       *time.Time `json:"last_gc,omitempty"`
+	PauseTotalMs float64    `json:"pause_total_ms"`
+	NextGCBytes  uint64     `json:"next_gc_bytes"`
+}
+
+// CacheStats describes the verification cache. Entries is only reported for
+// the in-memory cache.
+type CacheStats struct {
+	Backend string `json:"backend"`
+	Entries *int   `json:"entries,omitempty"`
+}
+
+// DatabaseStats reports the database connection pool
+type DatabaseStats struct {
+	OpenConnections int    `json:"open_connections"`
+	InUse           int    `json:"in_use"`
+	Idle            int    `json:"idle"`
+	MaxOpen         int    `json:"max_open"`
+	WaitCount       int64  `json:"wait_count"`
+	WaitDuration    string `json:"wait_duration"`
+}
+
+// BackendStats reports backend calls in flight and waiting for a slot
+type BackendStats struct {
+	Inflight int64 `json:"inflight"`
+	Queued   int64 `json:"queued"`
+}
+
+// JobQueueStats reports the asynchronous verification queue
+type JobQueueStats struct {
+	Queued   int `json:"queued"`
+	Capacity int `json:"capacity"`
+}
+
+// Model statuses reported by GET /models
+const (
+	ModelHealthy     = "healthy"
+	ModelDegraded    = "degraded"
+	ModelUnavailable = "unavailable"
+	ModelMaintenance = "maintenance"
+)
+
+// ModelInfo describes a model the proxy can verify
+type ModelInfo struct {
+	Model            string     `json:"model"`
+	Status           string     `json:"status"`
+	Backends         int        `json:"backends"`
+	HealthyBackends  int        `json:"healthy_backends"`
+	MaintenanceUntil *time.Time `json:"maintenance_until,omitempty"`
+}
+
+// ModelsResponse lists the verifiable models and where the list came from:
+// the routing table, or the backends' own capability lists
+type ModelsResponse struct {
+	Source string      `json:"source"`
+	Models []ModelInfo `json:"models"`
 }
@@ -0,0 +1,146 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+
+	"api/internal/shared"
+)
+
+// Request types the verifier backend understands
+const (
+	RequestTypeChat       = "CHAT"
+	RequestTypeCompletion = "COMPLETION"
+)
+
+// RequestTypes lists the accepted values of request_type
+var RequestTypes = []string{RequestTypeChat, RequestTypeCompletion}
+
+// Error describes why a verification payload is malformed
+type Error struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func invalid(field, format string, args ...any) *Error {
+	return &Error{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Request checks that a verification request has the shape the backend
+// expects, so malformed payloads are refused before a backend call is spent
+// on them. A maxChunks of zero disables the chunk count limit.
+func Request(req *shared.VerificationRequest, maxChunks int) error {
+	if !slices.Contains(RequestTypes, req.RequestType) {
+		return invalid("request_type", "must be one of %v, got %q", RequestTypes, req.RequestType)
+	}
+
+	if len(req.RawChunks) == 0 {
+		return invalid("raw_chunks", "must not be empty")
+	}
+	if maxChunks > 0 && len(req.RawChunks) > maxChunks {
+		return invalid("raw_chunks", "has %d chunks, limit is %d", len(req.RawChunks), maxChunks)
+	}
+
+	for i, chunk := range req.RawChunks {
+		if err := Chunk(req.RequestType, chunk); err != nil {
+			err.Field = joinField(fmt.Sprintf("raw_chunks[%d]", i), err.Field)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Chunk checks a single streamed chunk. Every chunk carries a choices array,
+// except the trailing usage chunk, which may carry usage alone.
+func Chunk(requestType string, chunk map[string]any) *Error {
+	if chunk == nil {
+		return invalid("", "must be an object")
+	}
+
+	usage, hasUsage := chunk["usage"]
+	if hasUsage && usage != nil {
+		if _, ok := usage.(map[string]any); !ok {
+			return invalid("usage", "must be an object")
+		}
+	}
+
+	rawChoices, hasChoices := chunk["choices"]
+	if !hasChoices || rawChoices == nil {
+		if hasUsage && usage != nil {
+			return nil
+		}
+		return invalid("choices", "is required")
+	}
+
+	choices, ok := rawChoices.([]any)
+	if !ok {
+		return invalid("choices", "must be an array")
+	}
+	if len(choices) == 0 && (!hasUsage || usage == nil) {
+		return invalid("choices", "must not be empty")
+	}
+
+	for i, rawChoice := range choices {
+		choice, ok := rawChoice.(map[string]any)
+		if !ok {
+			return invalid(fmt.Sprintf("choices[%d]", i), "must be an object")
+		}
+		if err := checkChoice(requestType, choice); err != nil {
+			err.Field = joinField(fmt.Sprintf("choices[%d]", i), err.Field)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// joinField prefixes a nested field path with its parent
+func joinField(parent, field string) string {
+	if field == "" {
+		return parent
+	}
+	return parent + "." + field
+}
+
+// checkChoice checks that a choice carries the content field of its request type
+func checkChoice(requestType string, choice map[string]any) *Error {
+	if index, ok := choice["index"]; ok {
+		if _, isNumber := index.(float64); !isNumber {
+			return invalid("index", "must be a number")
+		}
+	}
+
+	switch requestType {
+	case RequestTypeChat:
+		delta, hasDelta := choice["delta"]
+		message, hasMessage := choice["message"]
+		if !hasDelta && !hasMessage {
+			return invalid("delta", "is required for %s requests", requestType)
+		}
+		if hasDelta {
+			if _, ok := delta.(map[string]any); !ok {
+				return invalid("delta", "must be an object")
+			}
+		}
+		if hasMessage {
+			if _, ok := message.(map[string]any); !ok {
+				return invalid("message", "must be an object")
+			}
+		}
+	case RequestTypeCompletion:
+		text, ok := choice["text"]
+		if !ok {
+			return invalid("text", "is required for %s requests", requestType)
+		}
+		if _, isString := text.(string); !isString {
+			return invalid("text", "must be a string")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"api/internal/config"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// startServer serves e on the listen address, terminating TLS when it is
+// configured. It blocks until the server stops.
+func startServer(e *echo.Echo, settings config.ServerSettings) error {
+	tls := settings.TLS
+	for _, server := range []*http.Server{e.Server, e.TLSServer} {
+		server.ReadTimeout = settings.ReadTimeout
+		server.WriteTimeout = settings.WriteTimeout
+		server.IdleTimeout = settings.IdleTimeout
+	}
+
+	switch {
+	case tls.Autocert():
+		e.AutoTLSManager.Prompt = autocert.AcceptTOS
+		e.AutoTLSManager.HostPolicy = autocert.HostWhitelist(tls.AutocertHosts...)
+		e.AutoTLSManager.Cache = autocert.DirCache(tls.AutocertCacheDir)
+		e.AutoTLSManager.Email = tls.AutocertEmail
+		return e.StartAutoTLS(settings.ListenAddr)
+	case tls.Enabled():
+		return e.StartTLS(settings.ListenAddr, tls.CertFile, tls.KeyFile)
+	default:
+		return e.Start(settings.ListenAddr)
+	}
+}
+
+// redirectServer builds the plain HTTP server that sends clients to HTTPS, and
+// answers ACME HTTP challenges when certificates come from autocert
+func redirectServer(e *echo.Echo, settings config.ServerSettings) *http.Server {
+	_, httpsPort, _ := net.SplitHostPort(settings.ListenAddr)
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+	if settings.TLS.Autocert() {
+		handler = e.AutoTLSManager.HTTPHandler(handler)
+	}
+
+	return &http.Server{
+		Addr:        settings.TLS.RedirectAddr,
+		Handler:     handler,
+		ReadTimeout: settings.ReadTimeout,
+		IdleTimeout: settings.IdleTimeout,
+	}
+}
+
+// ipExtractor resolves the client address from X-Forwarded-For, skipping
+// entries added by trusted proxies
+func ipExtractor(settings config.ServerSettings) echo.IPExtractor {
+	if len(settings.TrustedProxies) == 0 {
+		return echo.ExtractIPFromXFFHeader()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, network := range settings.TrustedProxies {
+		options = append(options, echo.TrustIPRange(network))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+
+	"api/internal/config"
+)
+
+//go:embed schema.sql
+var schemaSQL string
+
+// checkSchema reports drift between the live database and schema.sql
+func checkSchema(cfg *config.Config) []error {
+	if cfg.Env.SchemaCheck == "off" {
+		return nil
+	}
+
+	drift, err := config.CheckSchema(cfg.SqlClient, schemaSQL)
+	if err != nil {
+		return []error{err}
+	}
+	for i, err := range drift {
+		drift[i] = fmt.Errorf("schema drift: %w", err)
+	}
+	return drift
+}
@@ -1,10 +1,239 @@
 -- Initial schema for targon-verifier-proxy
+--
+-- This is the full current schema in MySQL syntax, used by the startup drift
+-- check. The database is created and evolved by the migrations embedded from
+-- internal/config/migrations/<driver>; schema changes need a new migration
+-- there for every driver as well.
 
 -- API keys table
 CREATE TABLE IF NOT EXISTS api_keys (
     hotkey VARCHAR(255) PRIMARY KEY,
-    key_value VARCHAR(255) NOT NULL UNIQUE,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    key_hint VARCHAR(8) NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     last_used_at TIMESTAMP NULL,
-    is_admin BOOLEAN DEFAULT FALSE
-);
\ No newline at end of file
+    is_admin BOOLEAN DEFAULT FALSE,
+    tier VARCHAR(32) NOT NULL DEFAULT 'standard',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    challenge VARCHAR(255) NULL,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    auto_provisioned BOOLEAN NOT NULL DEFAULT FALSE,
+    rate_limit_rps DOUBLE NULL,
+    rate_limit_burst INT NULL,
+    previous_key_hash CHAR(64) NULL UNIQUE,
+    previous_key_expires_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL,
+    daily_quota INT NULL,
+    scopes VARCHAR(512) NOT NULL DEFAULT 'verify',
+    prune_flagged_at TIMESTAMP NULL,
+    prune_exempt BOOLEAN NOT NULL DEFAULT FALSE,
+    allowed_cidrs TEXT NULL
+);
+
+-- Audit of verdicts adjusted by per-model verification policies
+CREATE TABLE IF NOT EXISTS policy_decisions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    score DOUBLE NOT NULL,
+    threshold DOUBLE NOT NULL,
+    in_grace BOOLEAN NOT NULL,
+    backend_verified BOOLEAN NOT NULL,
+    verified BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_policy_decisions_model (model)
+);
+
+-- Per-backend verdicts recorded in multi-verifier consensus mode
+CREATE TABLE IF NOT EXISTS consensus_verdicts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    backend VARCHAR(512) NOT NULL,
+    verified BOOLEAN NOT NULL,
+    cause TEXT,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_consensus_verdicts_request (request_id)
+);
+
+-- Suspicious-pattern flags raised against hotkeys for review
+CREATE TABLE IF NOT EXISTS key_flags (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    hotkey VARCHAR(255) NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    details TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL,
+    INDEX idx_key_flags_hotkey (hotkey)
+);
+
+-- Asynchronous verification jobs, persisted before they are acknowledged
+CREATE TABLE IF NOT EXISTS verification_jobs (
+    id VARCHAR(64) PRIMARY KEY,
+    hotkey VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    status VARCHAR(16) NOT NULL,
+    request LONGBLOB NOT NULL,
+    consensus BOOLEAN DEFAULT FALSE,
+    skip_dedup BOOLEAN DEFAULT FALSE,
+    retention VARCHAR(16) NOT NULL DEFAULT 'full',
+    result LONGBLOB NULL,
+    error TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP NULL,
+    owner VARCHAR(255) NULL,
+    lease_until TIMESTAMP NULL,
+    INDEX idx_verification_jobs_status (status)
+);
+
+-- Model to verifier backend routing, managed through /admin/routes
+CREATE TABLE IF NOT EXISTS model_routes (
+    model VARCHAR(255) PRIMARY KEY,
+    backend_url VARCHAR(512) NOT NULL,
+    path VARCHAR(255) NOT NULL DEFAULT '/verify',
+    backend_server VARCHAR(255) NOT NULL,
+    cause_rules JSON,
+    maintenance JSON,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- Verification counts and token totals per hotkey, model and UTC day
+CREATE TABLE IF NOT EXISTS key_usage (
+    hotkey VARCHAR(255) NOT NULL,
+    day DATE NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    verifications BIGINT NOT NULL DEFAULT 0,
+    verified BIGINT NOT NULL DEFAULT 0,
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    response_tokens BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (hotkey, day, model),
+    INDEX idx_key_usage_day (day)
+);
+
+-- Verification counts per hotkey and UTC hour, for usage heatmaps
+CREATE TABLE IF NOT EXISTS key_usage_hourly (
+    hotkey VARCHAR(255) NOT NULL,
+    hour CHAR(13) NOT NULL,
+    verifications BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (hotkey, hour),
+    INDEX idx_key_usage_hourly_hour (hour)
+);
+
+-- Calls to deprecated endpoint aliases per hotkey, shared across replicas
+CREATE TABLE IF NOT EXISTS legacy_usage (
+    route VARCHAR(255) NOT NULL,
+    hotkey VARCHAR(255) NOT NULL,
+    successor VARCHAR(255) NOT NULL,
+    calls BIGINT NOT NULL DEFAULT 0,
+    last_seen TIMESTAMP NOT NULL,
+    PRIMARY KEY (route, hotkey)
+);
+
+-- Payloads rejected as hostile, kept for abuse forensics instead of forwarded
+CREATE TABLE IF NOT EXISTS payload_quarantine (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    hotkey VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    payload LONGBLOB NOT NULL,
+    source_ip VARCHAR(64),
+    reviewed_at TIMESTAMP NULL,
+    reviewed_by VARCHAR(255),
+    review_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_payload_quarantine_hotkey (hotkey, created_at),
+    INDEX idx_payload_quarantine_reviewed (reviewed_at)
+);
+
+-- Audit of verdicts replaced by an administrator
+CREATE TABLE IF NOT EXISTS verdict_overrides (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    request_id VARCHAR(255) NOT NULL,
+    hotkey VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    previous_verified BOOLEAN NOT NULL,
+    verified BOOLEAN NOT NULL,
+    reason TEXT NOT NULL,
+    admin_hotkey VARCHAR(255) NOT NULL,
+    proxy_request_id VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_verdict_overrides_request (request_id)
+);
+
+-- Reference requests with a known verdict, replayed by the canary prober
+CREATE TABLE IF NOT EXISTS synthetic_payloads (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    model VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    request JSON NOT NULL,
+    expect_verified BOOLEAN NOT NULL DEFAULT TRUE,
+    source VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uniq_synthetic_payloads_model_name (model, name)
+);
+
+-- Audit of admin requests made on behalf of another hotkey
+CREATE TABLE IF NOT EXISTS impersonation_audit (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    admin_hotkey VARCHAR(255) NOT NULL,
+    target_hotkey VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    path VARCHAR(512) NOT NULL,
+    proxy_request_id VARCHAR(64),
+    source_ip VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_impersonation_audit_target (target_hotkey)
+);
+
+-- Durable trail of key management operations
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_hotkey VARCHAR(255) NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    detail TEXT,
+    proxy_request_id VARCHAR(64),
+    source_ip VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_admin_audit_created (created_at),
+    INDEX idx_admin_audit_target (target)
+);
+
+-- Caller-supplied tags on verification requests, for per-tag outcome stats
+CREATE TABLE IF NOT EXISTS verification_tags (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    hotkey VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    model VARCHAR(255) NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    verified BOOLEAN NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_verification_tags_hotkey_tag (hotkey, tag)
+);
+
+-- Audit trail of every verification outcome served
+CREATE TABLE IF NOT EXISTS verification_logs (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    request_id VARCHAR(255),
+    hotkey VARCHAR(255) NOT NULL,
+    model VARCHAR(255) NOT NULL,
+    verified BOOLEAN NOT NULL,
+    cause TEXT,
+    cause_code VARCHAR(32),
+    error TEXT,
+    input_tokens BIGINT,
+    response_tokens BIGINT,
+    gpus INT NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL,
+    source VARCHAR(16) NOT NULL,
+    tags TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_verification_logs_created (created_at),
+    INDEX idx_verification_logs_hotkey (hotkey, created_at),
+    INDEX idx_verification_logs_model (model, created_at),
+    INDEX idx_verification_logs_request (hotkey, request_id)
+);
@@ -1,17 +1,35 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"api/internal/config"
+	"api/internal/errorsink"
 	"api/internal/routes"
 	"api/internal/shared"
+	"api/internal/tracing"
 
-	"github.com/aidarkhanov/nanoid"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
 func main() {
+	validateOnly := flag.Bool("validate-only", false, "load and check the configuration, then exit")
+	flag.Parse()
+
+	if *validateOnly {
+		os.Exit(validateConfig())
+	}
+
 	logger, err := zap.NewProduction()
 	if err != nil {
 		panic("Failed to get logger")
@@ -27,19 +45,66 @@ func main() {
 	}
 	defer cfg.Shutdown()
 
+	if drift := checkSchema(cfg); len(drift) > 0 {
+		for _, err := range drift {
+			sugar.Errorln(err)
+		}
+		if cfg.Env.SchemaCheck == "strict" {
+			panic("Database schema does not match schema.sql")
+		}
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Env.TracingEndpoint, cfg.Env.TracingSample)
+	if err != nil {
+		sugar.Errorw("Failed to initialize tracing", "error", err.Error())
+		panic("Failed to init tracing")
+	}
+
+	flushErrors, err := errorsink.Init(cfg.Env.ErrorSinkDSN, cfg.Env.ErrorSinkURL, cfg.Env.ErrorSinkEnv)
+	if err != nil {
+		sugar.Errorw("Failed to initialize error reporting", "error", err.Error())
+		panic("Failed to init error reporting")
+	}
+
 	e := echo.New()
+	e.HTTPErrorHandler = routes.HTTPErrorHandler(cfg)
+	e.IPExtractor = ipExtractor(cfg.Env.Server)
+	e.Use(routes.DecompressBody())
+	e.Use(middleware.BodyLimit(cfg.Env.Server.MaxBodySize))
+	if cfg.Env.Server.GzipMinLength >= 0 {
+		e.Use(routes.CompressResponses(cfg.Env.Server.GzipMinLength))
+	}
 	e.Use(middleware.CORS())
+	e.Use(tracing.Middleware)
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			reqId, _ := nanoid.Generate("0123456789abcdefghijklmnopqrstuvwxyz", 28)
+			reqId := cfg.NewID()
 			logger := sugar.With(
 				"request_id", "req_"+reqId,
 			)
+			if traceID := tracing.Annotate(c.Request().Context(), "req_"+reqId); traceID != "" {
+				logger = logger.With("trace_id", traceID)
+			}
 
-			cc := &shared.Context{Context: c, Log: logger, Reqid: reqId, Cfg: cfg}
+			clientReqId := routes.ClientRequestID(c.Request().Header.Get(routes.HeaderRequestID))
+			if clientReqId != "" {
+				logger = logger.With("client_request_id", clientReqId)
+			}
+
+			cc := &shared.Context{Context: c, Log: logger, Reqid: reqId, Cfg: cfg, ClientReqid: clientReqId}
+			// Set up front so every response carries them, errors included
+			routes.SetRequestIDHeaders(cc, c.Response().Header())
 			return next(cc)
 		}
 	})
+	accessLog, err := routes.AccessLog(cfg.Env.AccessLog)
+	if err != nil {
+		sugar.Errorw("Failed to initialize access log", "error", err.Error())
+		panic("Failed to init access log")
+	}
+	e.Use(routes.CountTraffic(cfg.Traffic))
+	e.Use(accessLog)
+	e.Use(routes.CaptureBodies(cfg))
 	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		StackSize: 1 << 10, // 1 KB
 		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
@@ -47,6 +112,7 @@ func main() {
 				_ = sugar.Sync()
 			}()
 			sugar.Errorw("Api Panic", "error", err.Error())
+			errorsink.Capture(routes.PanicEvent(c, err, stack))
 			return c.String(500, "Internal Server Error")
 		},
 	}))
@@ -55,15 +121,143 @@ func main() {
 	adminGroup := e.Group("/admin")
 
 	// Create a group for verification endpoints
-	verifyGroup := e.Group("")
+	verifyGroup := e.Group("", routes.CaptureSignedBody)
 
 	// Apply admin routes
-	adminGroup.POST("/add-key", routes.AddKey)
-	adminGroup.POST("/remove-key", routes.RemoveKey)
-	adminGroup.POST("/get-key", routes.GetKey)
+	adminGroup.POST("/session", routes.CreateSession)
+	adminGroup.DELETE("/session", routes.EndSession)
+	adminGroup.GET("/keys", routes.ListKeys, routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.POST("/keys", routes.AddKey, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.GET("/keys/:hotkey", routes.GetKey, routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.GET("/keys/:hotkey/heatmap", routes.KeyHeatmap, routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.DELETE("/keys/:hotkey", routes.RemoveKey, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/keys/:hotkey/disable", routes.DisableKey, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/keys/:hotkey/enable", routes.EnableKey, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/keys/:hotkey/rotate", routes.RotateKey, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.PUT("/keys/:hotkey/rate-limit", routes.SetRateLimit, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.PUT("/keys/:hotkey/daily-quota", routes.SetDailyQuota, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.PUT("/keys/:hotkey/prune-exempt", routes.SetPruneExempt, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.PUT("/keys/:hotkey/allowed-cidrs", routes.SetAllowedCIDRs, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.GET("/legacy-usage", routes.ListLegacyUsage, routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.GET("/verifications", routes.ListVerifications, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/verifications/override", routes.OverrideVerdict, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/quarantine", routes.ListQuarantine, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/quarantine/:id", routes.GetQuarantined, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/quarantine/:id/review", routes.ReviewQuarantined, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/flags", routes.ListFlags, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/flags/resolve", routes.ResolveFlag, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/routes", routes.ListRoutes, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/routes", routes.SetRoute, routes.RequireScope(config.ScopeAll))
+	adminGroup.DELETE("/routes/:model", routes.DeleteRoute, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/routes/:model/maintenance", routes.AddMaintenance, routes.RequireScope(config.ScopeAll))
+	adminGroup.DELETE("/routes/:model/maintenance", routes.ClearMaintenance, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/usage", routes.ListUsage, routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.GET("/captures", routes.ListCaptures, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/captures", routes.AddCapture, routes.RequireScope(config.ScopeAll))
+	adminGroup.DELETE("/captures", routes.RemoveCapture, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/payloads", routes.ListPayloads, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/payloads", routes.AddPayload, routes.RequireScope(config.ScopeAll))
+	adminGroup.DELETE("/payloads/:id", routes.DeletePayload, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/backends", routes.ListBackends, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/audit", routes.ListAdminAudit, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/debug/stats", routes.DebugStats, routes.RequireScope(config.ScopeAll))
+	adminGroup.GET("/metagraph/status", routes.MetagraphStatus, routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.POST("/metagraph/sync", routes.SyncMetagraph, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/retention/cleanup", routes.RetentionCleanup, routes.RequireScope(config.ScopeAll))
+	routes.MountPprof(adminGroup.Group("/debug/pprof", routes.RequireScope(config.ScopeAll)))
+	adminGroup.POST("/bulk/keys/disable", routes.BulkDisableKeys, routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/bulk/cache/invalidate", routes.BulkInvalidateCache, routes.RequireScope(config.ScopeCacheAdmin))
+	adminGroup.POST("/bulk/purge", routes.BulkPurge, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/keys/:hotkey/scopes/grant", routes.GrantScopes, routes.RequireScope(config.ScopeAll))
+	adminGroup.POST("/keys/:hotkey/scopes/revoke", routes.RevokeScopes, routes.RequireScope(config.ScopeAll))
+
+	// Legacy admin endpoints, kept as aliases until callers have migrated
+	adminGroup.POST("/add-key", routes.AddKey, routes.Legacy("/admin/keys"), routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/remove-key", routes.RemoveKey, routes.Legacy("/admin/keys/:hotkey"), routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/disable-key", routes.DisableKey, routes.Legacy("/admin/keys/:hotkey/disable"), routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/enable-key", routes.EnableKey, routes.Legacy("/admin/keys/:hotkey/enable"), routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/get-key", routes.GetKey, routes.Legacy("/admin/keys/:hotkey"), routes.RequireScope(config.ScopeKeysRead))
+	adminGroup.POST("/rotate-key", routes.RotateKey, routes.Legacy("/admin/keys/:hotkey/rotate"), routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/set-rate-limit", routes.SetRateLimit, routes.Legacy("/admin/keys/:hotkey/rate-limit"), routes.RequireScope(config.ScopeKeysWrite))
+	adminGroup.POST("/set-daily-quota", routes.SetDailyQuota, routes.Legacy("/admin/keys/:hotkey/daily-quota"), routes.RequireScope(config.ScopeKeysWrite))
 
-	// Apply verify route
+	// Apply verify routes
 	verifyGroup.POST("/verify", routes.Verify)
+	verifyGroup.POST("/verify/async", routes.VerifyAsync)
+	verifyGroup.POST("/verify/expect", routes.VerifyExpect)
+	verifyGroup.GET("/verify/status/:job_id", routes.VerifyStatus)
+	verifyGroup.GET("/verify/result/:request_id", routes.VerifyResult)
+	verifyGroup.GET("/stats/tags", routes.TagStats)
+	verifyGroup.GET("/models", routes.ListModels)
+	verifyGroup.GET("/usage", routes.SelfUsage)
+
+	// Apply key self-service routes
+	e.GET("/keys/challenge/:hotkey", routes.GetChallenge)
+	e.POST("/keys/activate", routes.ActivateKey)
+
+	go routes.Warmup(cfg, sugar)
+	routes.StartBackendRefresh(cfg, sugar)
+	routes.StartBackendHealthChecks(cfg, sugar)
+	routes.StartAsyncWorkers(cfg, sugar)
+	routes.StartCanaryRoutine(cfg, sugar)
+	routes.StartSoakTest(cfg, sugar)
+	routes.StartAnalyticsMirror(cfg, sugar)
+	routes.StartKeyPruning(cfg, sugar)
+	routes.StartLogRetention(cfg, sugar)
+	cfg.Metagraph.StartSyncRoutine(cfg.SqlClient, cfg.Env.MetagraphInterval, sugar)
+
+	// Liveness and readiness probes
+	e.GET("/healthz", routes.Healthz)
+	e.GET("/readyz", routes.Readyz)
+
+	// Expose Prometheus metrics
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := startServer(e, cfg.Env.Server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			sugar.Errorw("Server stopped", "error", err.Error())
+			stop()
+		}
+	}()
 
-	e.Logger.Fatal(e.Start(":80"))
+	var redirect *http.Server
+	if cfg.Env.Server.TLS.RedirectAddr != "" {
+		redirect = redirectServer(e, cfg.Env.Server)
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				sugar.Errorw("HTTPS redirect server stopped", "error", err.Error())
+				stop()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	drainStart := time.Now()
+	inflight := cfg.Traffic.Inflight()
+	sugar.Infow("Shutting down, draining in-flight requests", "timeout", cfg.Env.ShutdownTimeout.String(), "inflight", inflight)
+
+	// Stop accepting connections and wait for in-flight handlers; the shutdown
+	// report then flushes key usage and deferred config shutdown closes the DB
+	// and cache
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Env.ShutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		sugar.Errorw("Failed to drain connections", "error", err.Error())
+	}
+	if redirect != nil {
+		if err := redirect.Shutdown(shutdownCtx); err != nil {
+			sugar.Errorw("Failed to stop HTTPS redirect server", "error", err.Error())
+		}
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		sugar.Errorw("Failed to flush traces", "error", err.Error())
+	}
+	if err := flushErrors(shutdownCtx); err != nil {
+		sugar.Errorw("Failed to flush error reports", "error", err.Error())
+	}
+	sendShutdownReport(cfg, buildShutdownReport(cfg, inflight, drainStart), sugar)
+	_ = sugar.Sync()
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"api/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// buildShutdownReport flushes buffered writes and summarises the shutdown that
+// began at drainStart with inflight requests being handled
+func buildShutdownReport(cfg *config.Config, inflight int64, drainStart time.Time) config.ShutdownReport {
+	now := time.Now()
+	total, failed := cfg.Traffic.Total()
+	dropped := cfg.Traffic.Inflight()
+
+	report := config.ShutdownReport{
+		StartedAt:          cfg.Traffic.StartedAt,
+		StoppedAt:          now,
+		Uptime:             now.Sub(cfg.Traffic.StartedAt).Round(time.Second).String(),
+		RequestsTotal:      total,
+		RequestsFailed:     failed,
+		InflightAtShutdown: inflight,
+		Drained:            max(inflight-dropped, 0),
+		Dropped:            dropped,
+		DrainDuration:      now.Sub(drainStart).Round(time.Millisecond).String(),
+		JobsPending:        len(cfg.JobQueue),
+		MirrorDropped:      len(cfg.Mirror),
+	}
+	report.Instance, _ = os.Hostname()
+	cfg.FlushWrites(&report)
+	return report
+}
+
+// sendShutdownReport logs the report and posts it to SHUTDOWN_REPORT_URL when set
+func sendShutdownReport(cfg *config.Config, report config.ShutdownReport, sugar *zap.SugaredLogger) {
+	sugar.Infow("Shutdown report", "report", report)
+
+	if cfg.Env.ShutdownReportURL == "" {
+		return
+	}
+	if err := postShutdownReport(cfg, report); err != nil {
+		sugar.Errorw("Failed to post shutdown report", "error", err.Error())
+	}
+}
+
+func postShutdownReport(cfg *config.Config, report config.ShutdownReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second, Transport: cfg.Env.Outbound.Transport()}
+	resp, err := client.Post(cfg.Env.ShutdownReportURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("shutdown report endpoint returned %s", resp.Status)
+	}
+	return nil
+}
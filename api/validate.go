@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"api/internal/config"
+	"api/internal/routes"
+)
+
+// validateConfig loads the full configuration, checks the database and backend,
+// prints the effective settings and returns the process exit code
+func validateConfig() int {
+	cfg, errs := config.InitConfig()
+	if errs != nil {
+		fmt.Println("Configuration is invalid:")
+		for _, err := range errs {
+			fmt.Printf("  - %v\n", err)
+		}
+		return 1
+	}
+	defer cfg.Shutdown()
+
+	env := cfg.Env
+	if env.AdminKeyValue != "" {
+		env.AdminKeyValue = "<redacted>"
+	}
+	env.Outbound.ProxyURL = env.Outbound.RedactedProxy()
+	if env.BackendAuth.Token != "" {
+		env.BackendAuth.Token = "<redacted>"
+	}
+	settings, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		fmt.Printf("Failed to render settings: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Effective settings:\n%s\n", settings)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	checks, ready := routes.CheckDependencies(ctx, cfg)
+	dependencies := make([]string, 0, len(checks))
+	for dependency := range checks {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Strings(dependencies)
+
+	fmt.Println("Dependency checks:")
+	for _, dependency := range dependencies {
+		fmt.Printf("  %s: %s\n", dependency, checks[dependency])
+	}
+
+	drift := checkSchema(cfg)
+	if len(drift) > 0 {
+		fmt.Println("Schema drift:")
+		for _, err := range drift {
+			fmt.Printf("  - %v\n", err)
+		}
+		if cfg.Env.SchemaCheck == "strict" {
+			ready = false
+		}
+	}
+
+	if !ready {
+		fmt.Println("Configuration check failed")
+		return 1
+	}
+
+	fmt.Println("Configuration OK")
+	return 0
+}